@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/v9"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/config"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/internal/storage/cache"
+	"github.com/thanhnguyen/product-api/internal/storage/elasticsearch"
+	"github.com/thanhnguyen/product-api/internal/storage/postgres"
+	transportHttp "github.com/thanhnguyen/product-api/internal/transport/http"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	"github.com/thanhnguyen/product-api/pkg/notifier"
+)
+
+// application is the wired API server along with the dependencies main
+// manages over its lifetime
+type application struct {
+	server            *transportHttp.Server
+	authUseCase       usecase.AuthUseCase
+	notificationQueue *notifier.Queue
+	// redisClient is nil unless the statistics are stored in Redis
+	redisClient *redis.Client
+}
+
+// newApplication creates the repositories, use cases and HTTP server of the
+// API on db. Background tasks run until background is cancelled.
+func newApplication(background context.Context, cfg *config.Config, db *postgres.Database, log *logger.Logger) (*application, error) {
+	// Create repositories. Every repository that writes products shares the
+	// product count cache so it can invalidate it.
+	productCounts := postgres.NewCountCache(cfg.ActiveCache().ProductCountTTL)
+	userRepo := postgres.NewUserRepository(db, log)
+	productRepo := postgres.NewProductRepository(db, log, cfg.Products.SKUPrefix, productCounts)
+	categoryRepo := postgres.NewCategoryRepository(db, log, productCounts)
+	priceScheduleRepo := postgres.NewPriceScheduleRepository(db, log, productCounts)
+	priceAlertRepo := postgres.NewPriceAlertRepository(db, log)
+	reviewRepo := postgres.NewReviewRepository(db, log)
+	wishlistRepo := postgres.NewWishlistRepository(db, log)
+	reservationRepo := postgres.NewStockReservationRepository(db, log, productCounts)
+
+	// An empty Elasticsearch URL or the search feature flag disables the search backend
+	var (
+		productSearch  *elasticsearch.ProductSearch
+		productIndexer usecase.ProductIndexer
+	)
+	if !cfg.Features.Search {
+		log.Warn("Search feature disabled, products are searched in the database")
+	} else if cfg.Elasticsearch.URL != "" {
+		var err error
+		productSearch, err = elasticsearch.NewProductSearch(elasticsearch.Config{
+			URL:      cfg.Elasticsearch.URL,
+			Username: cfg.Elasticsearch.Username,
+			Password: cfg.Elasticsearch.Password,
+			APIKey:   cfg.Elasticsearch.APIKey,
+			Analyzer: cfg.Search.Analyzer,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create product search: %w", err)
+		}
+		productIndexer = productSearch
+	} else {
+		log.Warn("Elasticsearch URL not configured, product search is disabled")
+	}
+
+	// Create caches and the WebSocket hub before the use cases that feed them
+	var (
+		statsStore  storage.StatsStore
+		redisClient *redis.Client
+	)
+	if strings.EqualFold(cfg.Stats.Store, "redis") {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		statsStore = cache.NewRedisStatsStore(redisClient, cfg.Redis.KeyPrefix, cfg.Stats.CacheTTL)
+		log.Info("Storing statistics in Redis")
+	} else {
+		statsCache := cache.NewStatsCache(log, cfg.Stats.CacheTTL)
+		statsCache.CleanupTask(background, time.Minute)
+		statsStore = statsCache
+	}
+	wsHub := transportHttp.NewWebSocketHub(cfg.WebSocket.PingInterval, cfg.CORS.AllowOrigins)
+
+	// Create notification queue, flushed on shutdown
+	notificationQueue := notifier.NewQueue(background, notifier.NewLogNotifier(log), log, 100)
+
+	// Create use cases
+	authUseCase := usecase.NewAuthUseCase(userRepo, log, cfg.Auth.DefaultRole)
+	priceAlertUseCase := usecase.NewPriceAlertUseCase(priceAlertRepo, productRepo, notificationQueue, log)
+	stockMonitor := usecase.NewStockMonitor(notificationQueue, log, cfg.Inventory.LowStockThreshold, cfg.Inventory.AlertRecipient)
+	productUseCase := usecase.NewProductUseCase(productRepo, categoryRepo, priceAlertUseCase, stockMonitor, log, 5*time.Minute, productSearch, productIndexer, cfg.Products.ImmutableFields)
+	priceScheduleUseCase := usecase.NewPriceScheduleUseCase(background, priceScheduleRepo, productRepo, categoryRepo, log, time.Minute)
+	reviewUseCase := usecase.NewReviewUseCase(reviewRepo, productRepo, log, entity.ReviewPreview{
+		Sort:      cfg.Reviews.PreviewSort,
+		MinRating: cfg.Reviews.PreviewMinRating,
+		Limit:     cfg.Reviews.PreviewLimit,
+	})
+	wishlistUseCase := usecase.NewWishlistUseCase(wishlistRepo, productRepo, log)
+	categoryUseCase := usecase.NewCategoryUseCase(categoryRepo, log, cfg.Categories.DeleteCascade)
+	reservationSweeper := usecase.NewReservationSweeper(background, reservationRepo, log, cfg.Inventory.ReservationSweepInterval)
+
+	// The stats use case refreshes and broadcasts right away, so it is created
+	// once every repository and the hub it depends on exist. Its refresh loop
+	// runs until shutdown.
+	statsUseCase := usecase.NewStatsUseCase(background, productRepo, categoryRepo, userRepo, wishlistRepo, reviewRepo, statsStore, log, 15*time.Minute, wsHub, cfg.Stats.Concurrency, cfg.Stats.BestSellerMetric)
+
+	// Create HTTP server
+	server := transportHttp.NewServer(cfg, log, authUseCase, productUseCase, statsUseCase, priceScheduleUseCase, priceAlertUseCase, reviewUseCase, wishlistUseCase, categoryUseCase, wsHub)
+	server.AddHealthCheck("database", db.Ping)
+	if productSearch != nil {
+		server.AddHealthCheck("elasticsearch", productSearch.Ping)
+	}
+	if redisClient != nil {
+		server.AddHealthCheck("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
+
+	// Export the in-use and idle connections of the database pool
+	pool, err := db.Pool()
+	if err == nil {
+		err = server.AddMetricsCollector(collectors.NewDBStatsCollector(pool, cfg.Database.Name))
+	}
+	if err != nil {
+		log.WithError(err).Warn("Failed to export database pool metrics")
+	}
+	if err := server.AddMetricsCollector(reservationSweeper); err != nil {
+		log.WithError(err).Warn("Failed to export stock reservation metrics")
+	}
+
+	return &application{
+		server:            server,
+		authUseCase:       authUseCase,
+		notificationQueue: notificationQueue,
+		redisClient:       redisClient,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/thanhnguyen/product-api/internal/config"
+	"github.com/thanhnguyen/product-api/internal/storage/postgres"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// modulePath prefixes the packages whose dependencies the wiring test checks
+const modulePath = "github.com/thanhnguyen/product-api/"
+
+// newMockDatabase returns a database backed by sqlmock. It expects nothing,
+// so the queries of the background tasks fail without reaching a server.
+func newMockDatabase(t *testing.T) *postgres.Database {
+	t.Helper()
+
+	sqlDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(gormpostgres.New(gormpostgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger:               gormlogger.Default.LogMode(gormlogger.Silent),
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+	return &postgres.Database{DB: db}
+}
+
+// nilDependencies lists the nil pointer, interface, map, func and channel
+// fields reachable from v through the structs of this module. The database
+// is the caller's and isn't walked.
+func nilDependencies(v reflect.Value, path string, seen map[uintptr]bool) []string {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] || v.Type() == reflect.TypeOf(&postgres.Database{}) {
+			return nil
+		}
+		seen[v.Pointer()] = true
+		return nilDependencies(v.Elem(), path, seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return nilDependencies(v.Elem(), path, seen)
+	case reflect.Struct:
+		if !strings.HasPrefix(v.Type().PkgPath(), modulePath) {
+			return nil
+		}
+		var missing []string
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			name := path + "." + v.Type().Field(i).Name
+			switch field.Kind() {
+			case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Func, reflect.Chan:
+				if field.IsNil() {
+					missing = append(missing, name)
+					continue
+				}
+			}
+			missing = append(missing, nilDependencies(field, name, seen)...)
+		}
+		return missing
+	}
+	return nil
+}
+
+func TestNewApplication_WiresEveryDependency(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	cfg, err := config.LoadConfigFile("")
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	// Enable every optional backend so none of them is left out on purpose
+	search := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer search.Close()
+	cfg.Features.Search = true
+	cfg.Elasticsearch.URL = search.URL
+	cfg.Stats.Store = "redis"
+	cfg.Redis.Addr = miniredis.RunT(t).Addr()
+
+	log := logger.NewLogger("error", "text", "stdout")
+	log.SetOutput(io.Discard)
+	background, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	app, err := newApplication(background, cfg, newMockDatabase(t), log)
+	if err != nil {
+		t.Fatalf("newApplication() error = %v", err)
+	}
+	defer app.redisClient.Close()
+
+	if missing := nilDependencies(reflect.ValueOf(app), "app", map[uintptr]bool{}); len(missing) > 0 {
+		t.Errorf("nil dependencies:\n%s", strings.Join(missing, "\n"))
+	}
+}
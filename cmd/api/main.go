@@ -2,32 +2,40 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/thanhnguyen/product-api/internal/business/entity"
 	"github.com/thanhnguyen/product-api/internal/business/usecase"
 	"github.com/thanhnguyen/product-api/internal/config"
-	"github.com/thanhnguyen/product-api/internal/storage/cache"
-	"github.com/thanhnguyen/product-api/internal/storage/elasticsearch"
 	"github.com/thanhnguyen/product-api/internal/storage/postgres"
-	transportHttp "github.com/thanhnguyen/product-api/internal/transport/http"
 	"github.com/thanhnguyen/product-api/pkg/logger"
 )
 
 func main() {
+	configFile := flag.String("config", "", "YAML or JSON configuration file (default $CONFIG_FILE)")
+	flag.Parse()
+
 	// Load configuration
-	cfg, err := config.LoadConfig()
+	cfg, err := config.LoadConfigFile(*configFile)
 	if err != nil {
 		fmt.Printf("Failed to load configuration: %v\n", err)
 		os.Exit(1)
 	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
 
 	// Initialize logger
 	log := logger.NewLogger(cfg.Logger.Level, cfg.Logger.Format, cfg.Logger.OutputPath)
 	log.Info("Starting application")
+	log.WithFields(logger.Fields(cfg.Redacted())).Info("Effective configuration")
 
 	// Connect to database
 	db, err := postgres.NewPostgresDB(cfg.GetDatabaseURL(),
@@ -40,23 +48,32 @@ func main() {
 	defer db.Close()
 	log.Info("Connected to database")
 
-	// Create repositories
-	productRepo := postgres.NewProductRepository(db, log)
-	categoryRepo := postgres.NewCategoryRepository(db, log)
+	// Background tasks run until shutdown
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
 
-	// Create caches
-	statsCache := cache.NewStatsCache(log)
-	wsHub := transportHttp.NewWebSocketHub()
-	// Create use cases
-	productSearch, err := elasticsearch.NewProductSearch(cfg.Elasticsearch.URL)
+	// Wire the repositories, use cases and server
+	app, err := newApplication(background, cfg, db, log)
 	if err != nil {
-		log.WithError(err).Fatal("Failed to create product search")
+		log.WithError(err).Fatal("Failed to create the application")
 	}
-	productUseCase := usecase.NewProductUseCase(productRepo, categoryRepo, log, 5*time.Minute, productSearch)
-	statsUseCase := usecase.NewStatsUseCase(productRepo, categoryRepo, nil, nil, statsCache, log, 15*time.Minute, wsHub)
+	if app.redisClient != nil {
+		defer app.redisClient.Close()
+	}
+	server := app.server
 
-	// Create HTTP server
-	server := transportHttp.NewServer(cfg, log, productUseCase, statsUseCase, wsHub)
+	// Create the first admin
+	err = app.authUseCase.BootstrapAdmin(context.Background(), &entity.User{
+		Username: cfg.Auth.BootstrapAdminUsername,
+		Email:    cfg.Auth.BootstrapAdminEmail,
+		FullName: "Admin User",
+	}, cfg.Auth.BootstrapAdminPassword)
+	if errors.Is(err, usecase.ErrBootstrapPassword) {
+		log.Fatal("No admin user exists, set BOOTSTRAP_ADMIN_PASSWORD to create one")
+	}
+	if err != nil {
+		log.WithError(err).Fatal("Failed to bootstrap the admin user")
+	}
 
 	// Start server in a goroutine
 	go func() {
@@ -79,6 +96,14 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.WithError(err).Fatal("Server forced to shutdown")
 	}
+	stopBackground()
+
+	// Deliver the notifications still queued
+	select {
+	case <-app.notificationQueue.Done():
+	case <-ctx.Done():
+		log.Warn("Timed out delivering queued notifications")
+	}
 
 	log.Info("Server exiting")
 }
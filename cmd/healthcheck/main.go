@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/config"
+	"github.com/thanhnguyen/product-api/internal/storage/elasticsearch"
+	"github.com/thanhnguyen/product-api/internal/storage/postgres"
+)
+
+// seedMigration inserts sample data and is never applied in production
+const seedMigration = "002_seed_data"
+
+// check is a single self-test step
+type check struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// healthcheck connects to the database and Elasticsearch and verifies that
+// every migration has been applied. It prints the result of each check and
+// exits with status 1 if any of them fails, so it can be used as a Docker
+// HEALTHCHECK or a pre-deploy gate.
+func main() {
+	var (
+		timeout       time.Duration
+		migrationsDir string
+		configFile    string
+	)
+	flag.DurationVar(&timeout, "timeout", 5*time.Second, "Time allowed for all checks")
+	flag.StringVar(&migrationsDir, "migrations", "migrations/sql", "Directory of the migration files")
+	flag.StringVar(&configFile, "config", "", "YAML or JSON configuration file (default $CONFIG_FILE)")
+	flag.Parse()
+
+	cfg, err := config.LoadConfigFile(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(run(cfg, migrationsDir, timeout, os.Stdout, os.Stderr))
+}
+
+// run connects to the dependencies of cfg and checks them, returning the
+// exit status: 0 if every check passed and 1 otherwise
+func run(cfg *config.Config, migrationsDir string, timeout time.Duration, stdout, stderr io.Writer) int {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	db, err := postgres.NewPostgresDB(cfg.GetDatabaseURL(), 1, 1, timeout)
+	if err != nil {
+		fmt.Fprintf(stderr, "database: FAIL (%v)\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	// An empty Elasticsearch URL disables the search backend
+	var search *elasticsearch.ProductSearch
+	if cfg.Elasticsearch.URL != "" {
+		search, err = elasticsearch.NewProductSearch(elasticsearch.Config{
+			URL:      cfg.Elasticsearch.URL,
+			Username: cfg.Elasticsearch.Username,
+			Password: cfg.Elasticsearch.Password,
+			APIKey:   cfg.Elasticsearch.APIKey,
+		})
+		if err != nil {
+			fmt.Fprintf(stderr, "elasticsearch: FAIL (%v)\n", err)
+			return 1
+		}
+	}
+
+	checks := dependencyChecks(db, search, migrationsDir, cfg.Environment == "production")
+	return runChecks(ctx, checks, stdout)
+}
+
+// dependencyChecks returns the checks of the database, its migrations and,
+// unless search is nil, Elasticsearch
+func dependencyChecks(db *postgres.Database, search *elasticsearch.ProductSearch, migrationsDir string, production bool) []check {
+	checks := []check{
+		{name: "database", run: db.Ping},
+		{name: "migrations", run: func(ctx context.Context) error {
+			return checkMigrations(ctx, db, migrationsDir, production)
+		}},
+	}
+	if search != nil {
+		checks = append(checks, check{name: "elasticsearch", run: search.Ping})
+	}
+	return checks
+}
+
+// runChecks runs every check, printing its result, and returns 1 if any of
+// them failed and 0 otherwise
+func runChecks(ctx context.Context, checks []check, out io.Writer) int {
+	status := 0
+	for _, c := range checks {
+		start := time.Now()
+		if err := c.run(ctx); err != nil {
+			fmt.Fprintf(out, "%s: FAIL (%v)\n", c.name, err)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(out, "%s: OK (%s)\n", c.name, time.Since(start).Round(time.Millisecond))
+	}
+	return status
+}
+
+// checkMigrations returns an error listing the migrations in dir that haven't
+// been applied. The seed migration isn't required in production.
+func checkMigrations(ctx context.Context, db *postgres.Database, dir string, production bool) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no migration files found in %s", dir)
+	}
+
+	var applied []string
+	if err := db.WithContext(ctx).Table("migrations").Pluck("name", &applied).Error; err != nil {
+		return err
+	}
+	appliedSet := make(map[string]bool, len(applied))
+	for _, name := range applied {
+		appliedSet[name] = true
+	}
+
+	var pending []string
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".sql")
+		if strings.HasSuffix(name, "_down") || appliedSet[name] {
+			continue
+		}
+		if production && name == seedMigration {
+			continue
+		}
+		pending = append(pending, name)
+	}
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		return errors.New("pending migrations: " + strings.Join(pending, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/config"
+	"github.com/thanhnguyen/product-api/internal/storage/elasticsearch"
+	"github.com/thanhnguyen/product-api/internal/storage/postgres"
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newMockDatabase returns a database backed by sqlmock that answers pings
+// and lists the applied migrations
+func newMockDatabase(t *testing.T, pingErr error, applied ...string) *postgres.Database {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(gormpostgres.New(gormpostgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger:               gormlogger.Default.LogMode(gormlogger.Silent),
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+
+	mock.ExpectPing().WillReturnError(pingErr)
+	rows := sqlmock.NewRows([]string{"name"})
+	for _, name := range applied {
+		rows.AddRow(name)
+	}
+	mock.ExpectQuery(`SELECT "name" FROM "migrations"`).WillReturnRows(rows)
+
+	return &postgres.Database{DB: db}
+}
+
+// newMigrationsDir returns a directory holding the given migrations
+func newMigrationsDir(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name+".sql"), []byte("SELECT 1;"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// newElasticsearch returns a search client of a cluster answering pings with status
+func newElasticsearch(t *testing.T, status int) *elasticsearch.ProductSearch {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	search, err := elasticsearch.NewProductSearch(elasticsearch.Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("failed to create the search client: %v", err)
+	}
+	return search
+}
+
+// closedAddress returns the address of a port nothing listens on
+func closedAddress(t *testing.T) *net.TCPAddr {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+	return addr
+}
+
+func TestRunChecks_ExitCodes(t *testing.T) {
+	migrations := []string{"001_init", "001_init_down", "002_seed_data"}
+	unreachable := closedAddress(t)
+
+	tests := []struct {
+		name       string
+		db         func(t *testing.T) *postgres.Database
+		search     func(t *testing.T) *elasticsearch.ProductSearch
+		production bool
+		want       int
+		wantFailed string
+	}{
+		{
+			name:   "healthy",
+			db:     func(t *testing.T) *postgres.Database { return newMockDatabase(t, nil, "001_init", "002_seed_data") },
+			search: func(t *testing.T) *elasticsearch.ProductSearch { return newElasticsearch(t, http.StatusOK) },
+			want:   0,
+		},
+		{
+			name:       "healthy production without the seed data",
+			db:         func(t *testing.T) *postgres.Database { return newMockDatabase(t, nil, "001_init") },
+			search:     func(t *testing.T) *elasticsearch.ProductSearch { return nil },
+			production: true,
+			want:       0,
+		},
+		{
+			name: "database down",
+			db: func(t *testing.T) *postgres.Database {
+				return newMockDatabase(t, net.ErrClosed, "001_init", "002_seed_data")
+			},
+			search:     func(t *testing.T) *elasticsearch.ProductSearch { return newElasticsearch(t, http.StatusOK) },
+			want:       1,
+			wantFailed: "database",
+		},
+		{
+			name:       "pending migration",
+			db:         func(t *testing.T) *postgres.Database { return newMockDatabase(t, nil, "001_init") },
+			search:     func(t *testing.T) *elasticsearch.ProductSearch { return newElasticsearch(t, http.StatusOK) },
+			want:       1,
+			wantFailed: "migrations",
+		},
+		{
+			name: "elasticsearch unhealthy",
+			db:   func(t *testing.T) *postgres.Database { return newMockDatabase(t, nil, "001_init", "002_seed_data") },
+			search: func(t *testing.T) *elasticsearch.ProductSearch {
+				return newElasticsearch(t, http.StatusServiceUnavailable)
+			},
+			want:       1,
+			wantFailed: "elasticsearch",
+		},
+		{
+			name: "elasticsearch unreachable",
+			db:   func(t *testing.T) *postgres.Database { return newMockDatabase(t, nil, "001_init", "002_seed_data") },
+			search: func(t *testing.T) *elasticsearch.ProductSearch {
+				search, err := elasticsearch.NewProductSearch(elasticsearch.Config{URL: "http://" + unreachable.String()})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return search
+			},
+			want:       1,
+			wantFailed: "elasticsearch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := dependencyChecks(tt.db(t), tt.search(t), newMigrationsDir(t, migrations...), tt.production)
+
+			var out bytes.Buffer
+			if got := runChecks(context.Background(), checks, &out); got != tt.want {
+				t.Fatalf("exit status = %d, want %d\n%s", got, tt.want, out.String())
+			}
+			if tt.wantFailed != "" && !strings.Contains(out.String(), tt.wantFailed+": FAIL") {
+				t.Errorf("output doesn't report the failed %s check:\n%s", tt.wantFailed, out.String())
+			}
+		})
+	}
+}
+
+func TestRun_UnreachableDatabase(t *testing.T) {
+	addr := closedAddress(t)
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		Username: "postgres",
+		Name:     "products",
+		SSLMode:  "disable",
+	}}
+
+	var stdout, stderr bytes.Buffer
+	if got := run(cfg, newMigrationsDir(t, "001_init"), 2*time.Second, &stdout, &stderr); got != 1 {
+		t.Fatalf("exit status = %d, want 1", got)
+	}
+	if !strings.Contains(stderr.String(), "database: FAIL") {
+		t.Errorf("stderr = %q, want the database failure", stderr.String())
+	}
+}
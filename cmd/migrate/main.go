@@ -1,6 +1,8 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -9,12 +11,17 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// seedMigration inserts sample data, including users with well-known passwords
+const seedMigration = "002_seed_data"
+
 // Migration represents a single migration
 type Migration struct {
 	Name string
@@ -25,10 +32,14 @@ type Migration struct {
 func main() {
 	// Parse command line arguments
 	var down bool
+	var status bool
+	var force bool
 	var migrationID string
 	var envFile string
 
 	flag.BoolVar(&down, "down", false, "Roll back migrations instead of applying them")
+	flag.BoolVar(&force, "force", false, "Run even if applied migration files have been modified")
+	flag.BoolVar(&status, "status", false, "Show applied and pending migrations without changing the database")
 	flag.StringVar(&migrationID, "migration", "", "Specify a specific migration to run (optional)")
 	flag.StringVar(&envFile, "env", ".env", "Path to the .env file")
 	flag.Parse()
@@ -90,11 +101,20 @@ func main() {
 	}
 	defer sqlDB.Close()
 
+	// Print migration status and exit without modifying the database
+	if status {
+		if err := printStatus(db, "migrations/sql"); err != nil {
+			log.Fatalf("Failed to get migration status: %v", err)
+		}
+		return
+	}
+
 	// Create migrations table if it doesn't exist
 	err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS migrations (
 			id SERIAL PRIMARY KEY,
 			name VARCHAR(255) NOT NULL UNIQUE,
+			checksum VARCHAR(64),
 			applied_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 		)
 	`).Error
@@ -102,12 +122,40 @@ func main() {
 		log.Fatalf("Failed to create migrations table: %v", err)
 	}
 
+	// Add the checksum column to migrations tables created before it existed
+	err = db.Exec("ALTER TABLE migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64)").Error
+	if err != nil {
+		log.Fatalf("Failed to add checksum column to migrations table: %v", err)
+	}
+
 	// Get applied migrations
-	var appliedMigrations []string
-	err = db.Table("migrations").Pluck("name", &appliedMigrations).Error
+	var applied []AppliedMigration
+	err = db.Table("migrations").Select("name", "applied_at", "COALESCE(checksum, '') AS checksum").Scan(&applied).Error
 	if err != nil {
 		log.Fatalf("Failed to get applied migrations: %v", err)
 	}
+	appliedMigrations := make([]string, 0, len(applied))
+	for _, a := range applied {
+		appliedMigrations = append(appliedMigrations, a.Name)
+	}
+
+	// Detect applied migrations whose files were edited afterwards
+	upMigrations, err := loadMigrations("migrations/sql", false)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	modified, err := verifyChecksums(db, applied, upMigrations)
+	if err != nil {
+		log.Fatalf("Failed to verify migration checksums: %v", err)
+	}
+	if len(modified) > 0 {
+		if !force {
+			log.Fatalf("Applied migrations have been modified since they were applied: %s (use -force to override)",
+				strings.Join(modified, ", "))
+		}
+		log.Printf("WARNING: applied migrations have been modified since they were applied: %s\n",
+			strings.Join(modified, ", "))
+	}
 
 	// Get available migrations
 	migrations, err := loadMigrations("migrations/sql", down)
@@ -137,9 +185,14 @@ func main() {
 			return migrations[i].Name < migrations[j].Name
 		})
 
-		// Only apply migrations that haven't been applied yet
+		// Only apply migrations that haven't been applied yet. The seed data
+		// has users with well-known passwords, so it never runs in production.
 		for _, migration := range migrations {
 			if !contains(appliedMigrations, migration.Name) {
+				if migration.Name == seedMigration && os.Getenv("ENVIRONMENT") == "production" {
+					log.Printf("Skipping %s in production: it creates users with well-known passwords\n", migration.Name)
+					continue
+				}
 				if migrationID == "" || migration.Name == migrationID {
 					migrationsToApply = append(migrationsToApply, migration)
 				}
@@ -179,7 +232,7 @@ func main() {
 		if down {
 			err = tx.Exec("DELETE FROM migrations WHERE name = $1", migration.Name).Error
 		} else {
-			err = tx.Exec("INSERT INTO migrations (name) VALUES ($1)", migration.Name).Error
+			err = tx.Exec("INSERT INTO migrations (name, checksum) VALUES ($1, $2)", migration.Name, checksum(content)).Error
 		}
 
 		if err != nil {
@@ -248,6 +301,108 @@ func loadMigrations(dir string, down bool) ([]Migration, error) {
 	return migrations, err
 }
 
+// AppliedMigration represents a row in the migrations table
+type AppliedMigration struct {
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// checksum returns the hex-encoded SHA-256 of a migration file's contents
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyChecksums compares the stored checksum of every applied migration
+// against its current file and returns the names of modified migrations.
+// Migrations applied before checksums were recorded are backfilled.
+func verifyChecksums(db *gorm.DB, applied []AppliedMigration, migrations []Migration) ([]string, error) {
+	paths := make(map[string]string, len(migrations))
+	for _, migration := range migrations {
+		paths[migration.Name] = migration.Path
+	}
+
+	var modified []string
+	for _, a := range applied {
+		path, ok := paths[a.Name]
+		if !ok {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sum := checksum(content)
+
+		if a.Checksum == "" {
+			if err := db.Exec("UPDATE migrations SET checksum = $1 WHERE name = $2", sum, a.Name).Error; err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if a.Checksum != sum {
+			modified = append(modified, a.Name)
+		}
+	}
+
+	sort.Strings(modified)
+	return modified, nil
+}
+
+// printStatus prints every migration found in dir with its applied/pending state
+func printStatus(db *gorm.DB, dir string) error {
+	migrations, err := loadMigrations(dir, false)
+	if err != nil {
+		return err
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Name < migrations[j].Name
+	})
+
+	// The migrations table doesn't exist until the first run, so everything is pending
+	applied := make(map[string]AppliedMigration)
+	if db.Migrator().HasTable("migrations") {
+		columns := []string{"name", "applied_at"}
+		if db.Migrator().HasColumn("migrations", "checksum") {
+			columns = append(columns, "COALESCE(checksum, '') AS checksum")
+		}
+
+		var rows []AppliedMigration
+		if err := db.Table("migrations").Select(columns).Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			applied[row.Name] = row
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MIGRATION\tSTATUS\tAPPLIED AT")
+	for _, migration := range migrations {
+		row, ok := applied[migration.Name]
+		if !ok {
+			fmt.Fprintf(w, "%s\tpending\t-\n", migration.Name)
+			continue
+		}
+
+		state := "applied"
+		if row.Checksum != "" {
+			content, err := ioutil.ReadFile(migration.Path)
+			if err != nil {
+				return err
+			}
+			if checksum(content) != row.Checksum {
+				state = "modified"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", migration.Name, state, row.AppliedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
 // contains checks if a string slice contains a value
 func contains(slice []string, value string) bool {
 	for _, item := range slice {
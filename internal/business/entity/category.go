@@ -5,4 +5,26 @@ type Category struct {
 	ID          uint   `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Disabled    bool   `json:"disabled"`
+	// ParentID is the category this one is nested under, nil for top-level ones
+	ParentID *uint `json:"parent_id"`
+}
+
+// CategoryFilter represents the filters of a category list
+type CategoryFilter struct {
+	// Name matches categories whose name contains it, ignoring case
+	Name            string
+	IncludeDisabled bool
+}
+
+// CategorySummary is a category with the number of products in it
+type CategorySummary struct {
+	Category
+	ProductCount int `json:"product_count"`
+}
+
+// CategoryNode is a category with its sub-categories
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
 }
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a cursor that can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ProductCursor marks the last product of a page in keyset pagination. The
+// next page starts right after the product with this sort value and ID.
+type ProductCursor struct {
+	ID        uint   `json:"id"`
+	SortBy    string `json:"sort_by,omitempty"`
+	SortOrder string `json:"sort_order,omitempty"`
+	Value     string `json:"value,omitempty"`
+}
+
+// NewProductCursor creates the cursor of a product in a list sorted by sortBy and sortOrder
+func NewProductCursor(p Product, sortBy, sortOrder string) ProductCursor {
+	cursor := ProductCursor{ID: p.ID, SortBy: sortBy, SortOrder: sortOrder}
+	switch sortBy {
+	case "name":
+		cursor.Value = p.Name
+	case "price":
+		cursor.Value = strconv.FormatFloat(p.Price, 'f', -1, 64)
+	case "stock_quantity":
+		cursor.Value = strconv.Itoa(p.StockQuantity)
+	case "created_at":
+		cursor.Value = p.CreatedAt.Format(time.RFC3339Nano)
+	}
+	return cursor
+}
+
+// Encode encodes the cursor as an opaque URL-safe string
+func (c ProductCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// SortKey returns the cursor's sort value typed like its sort column. It is nil
+// when products are sorted by ID only.
+func (c ProductCursor) SortKey() (interface{}, error) {
+	switch c.SortBy {
+	case "name":
+		return c.Value, nil
+	case "price":
+		return strconv.ParseFloat(c.Value, 64)
+	case "stock_quantity":
+		return strconv.Atoi(c.Value)
+	case "created_at":
+		return time.Parse(time.RFC3339Nano, c.Value)
+	}
+	return nil, nil
+}
+
+// DecodeProductCursor decodes a cursor created by ProductCursor.Encode
+func DecodeProductCursor(s string) (*ProductCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor ProductCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := cursor.SortKey(); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestProductCursor_RoundTrip(t *testing.T) {
+	product := Product{
+		ID:            42,
+		Name:          "Widget, large",
+		Price:         19.99,
+		StockQuantity: 7,
+		CreatedAt:     time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC),
+	}
+
+	tests := []struct {
+		sortBy string
+		want   interface{}
+	}{
+		{sortBy: "", want: nil},
+		{sortBy: "name", want: "Widget, large"},
+		{sortBy: "price", want: 19.99},
+		{sortBy: "stock_quantity", want: 7},
+		{sortBy: "created_at", want: product.CreatedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			cursor, err := DecodeProductCursor(NewProductCursor(product, tt.sortBy, "desc").Encode())
+			if err != nil {
+				t.Fatalf("DecodeProductCursor() error = %v", err)
+			}
+			if cursor.ID != 42 || cursor.SortBy != tt.sortBy || cursor.SortOrder != "desc" {
+				t.Errorf("cursor = %+v, want product 42 sorted by %q desc", cursor, tt.sortBy)
+			}
+
+			key, err := cursor.SortKey()
+			if err != nil {
+				t.Fatalf("SortKey() error = %v", err)
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				if got, _ := key.(time.Time); !got.Equal(want) {
+					t.Errorf("SortKey() = %v, want %v", key, want)
+				}
+			} else if key != tt.want {
+				t.Errorf("SortKey() = %v, want %v", key, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeProductCursor_Invalid(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "%%%"},
+		{name: "not JSON", cursor: encode("products")},
+		{name: "no ID", cursor: encode(`{"sort_by":"price","value":"10"}`)},
+		{name: "value of the wrong type", cursor: encode(`{"id":3,"sort_by":"price","value":"cheap"}`)},
+		{name: "bad time", cursor: encode(`{"id":3,"sort_by":"created_at","value":"yesterday"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeProductCursor(tt.cursor); err != ErrInvalidCursor {
+				t.Errorf("DecodeProductCursor() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
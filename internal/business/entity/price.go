@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// Price adjustment types
+const (
+	PriceAdjustmentPercentage = "percentage"
+	PriceAdjustmentAbsolute   = "absolute"
+)
+
+// Scheduled price change statuses
+const (
+	ScheduleStatusPending  = "pending"
+	ScheduleStatusApplied  = "applied"
+	ScheduleStatusReverted = "reverted"
+)
+
+// DefaultMinPrice is the lowest price an adjustment may produce when no floor is given
+const DefaultMinPrice = 0.01
+
+// PriceAdjustment describes a relative change applied to product prices
+type PriceAdjustment struct {
+	Type     string  `json:"type"`
+	Value    float64 `json:"value"`
+	MinPrice float64 `json:"min_price"`
+}
+
+// BulkPriceUpdate selects the products a price adjustment applies to.
+// Explicit ProductIDs take precedence over Filter. A DryRun computes the
+// changes without saving them.
+type BulkPriceUpdate struct {
+	ProductIDs []uint          `json:"product_ids,omitempty"`
+	Filter     ProductFilter   `json:"filter"`
+	Adjustment PriceAdjustment `json:"adjustment"`
+	DryRun     bool            `json:"dry_run,omitempty"`
+	// MaxProducts caps the number of products the update may match; zero means no cap
+	MaxProducts int `json:"max_products,omitempty"`
+	// UserID is the user applying the update, recorded in the audit log
+	UserID uint `json:"user_id,omitempty"`
+}
+
+// HasTarget reports whether the update selects products explicitly, by ID or
+// by a filter, rather than the whole catalog
+func (u BulkPriceUpdate) HasTarget() bool {
+	f := u.Filter
+	return len(u.ProductIDs) > 0 || f.Search != "" || f.CategoryID != 0 || f.MinPrice != nil || f.MaxPrice != nil
+}
+
+// PriceChange records the effect of an adjustment on a single product
+type PriceChange struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	OldPrice  float64 `json:"old_price"`
+	NewPrice  float64 `json:"new_price"`
+}
+
+// Apply returns the adjusted price, rounded to cents and clamped to the minimum price
+func (a PriceAdjustment) Apply(price float64) float64 {
+	var adjusted float64
+	switch a.Type {
+	case PriceAdjustmentPercentage:
+		adjusted = price * (1 + a.Value/100)
+	default:
+		adjusted = price + a.Value
+	}
+	adjusted = math.Round(adjusted*100) / 100
+
+	minPrice := a.MinPrice
+	if minPrice <= 0 {
+		minPrice = DefaultMinPrice
+	}
+	if adjusted < minPrice {
+		adjusted = minPrice
+	}
+	return adjusted
+}
+
+// ScheduledPriceChange represents a price change applied at EffectiveAt and,
+// optionally, reverted at RevertAt. It targets either a single product or
+// every product in a category.
+type ScheduledPriceChange struct {
+	ID          uint       `json:"id"`
+	ProductID   *uint      `json:"product_id,omitempty"`
+	CategoryID  *uint      `json:"category_id,omitempty"`
+	NewPrice    float64    `json:"new_price"`
+	EffectiveAt time.Time  `json:"effective_at"`
+	RevertAt    *time.Time `json:"revert_at,omitempty"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// PriceAlert is a user's subscription to be notified when a product's price
+// drops to or below Threshold
+type PriceAlert struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	UserEmail  string     `json:"-"`
+	ProductID  uint       `json:"product_id"`
+	Threshold  float64    `json:"threshold"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
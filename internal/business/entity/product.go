@@ -2,27 +2,100 @@ package entity
 
 import "time"
 
-// Product represents a product in the system
+// Product represents a product in the system. Handlers never serialize it
+// directly; responses go through the transport DTOs.
 type Product struct {
 	ID            uint       `json:"id"`
 	Name          string     `json:"name"`
+	SKU           string     `json:"sku"`
 	Description   string     `json:"description"`
 	Price         float64    `json:"price"`
 	StockQuantity int        `json:"stock_quantity"`
 	Status        string     `json:"status"`
-	Categories    []Category `json:"categories,omitempty"`
+	Archived      bool       `json:"archived"`
+	ArchivedAt    *time.Time `json:"archived_at,omitempty"`
+	Categories    []Category `json:"categories"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
+// Product statuses
+const (
+	ProductStatusActive       = "active"
+	ProductStatusInactive     = "inactive"
+	ProductStatusOutOfStock   = "out_of_stock"
+	ProductStatusDiscontinued = "discontinued"
+)
+
+// ProductSearchResult is a product found by a search along with the matched
+// text fragments
+type ProductSearchResult struct {
+	Product    Product  `json:"product"`
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+// StockChange records the effect of a stock adjustment on a single product
+type StockChange struct {
+	ProductID   uint   `json:"product_id"`
+	Name        string `json:"name"`
+	OldQuantity int    `json:"old_quantity"`
+	NewQuantity int    `json:"new_quantity"`
+}
+
+// SearchConsistencyReport records how the search index differs from the
+// database. Repaired documents are listed along with the differences found.
+type SearchConsistencyReport struct {
+	// Checked is the number of products in the database
+	Checked int `json:"checked"`
+	// Indexed is the number of documents in the search index
+	Indexed int `json:"indexed"`
+	// Missing lists the products without a document
+	Missing []uint `json:"missing"`
+	// Stale lists the products updated since they were indexed
+	Stale []uint `json:"stale"`
+	// Orphaned lists the documents of products no longer in the database
+	Orphaned []uint `json:"orphaned"`
+	Repaired bool   `json:"repaired"`
+	// Failed lists the products whose repair failed
+	Failed []uint `json:"failed"`
+}
+
+// ProductAvailability reports whether a product can currently be ordered
+type ProductAvailability struct {
+	ProductID     uint   `json:"product_id"`
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	StockQuantity int    `json:"stock_quantity"`
+	Available     bool   `json:"available"`
+}
+
+// IsAvailable reports whether the product is on sale and in stock
+func (p Product) IsAvailable() bool {
+	return p.Status == ProductStatusActive && !p.Archived && p.StockQuantity > 0
+}
+
+// Product fields that can be locked once a product is published
+const (
+	ProductFieldName          = "name"
+	ProductFieldSKU           = "sku"
+	ProductFieldDescription   = "description"
+	ProductFieldPrice         = "price"
+	ProductFieldStockQuantity = "stock_quantity"
+)
+
 // ProductFilter contains filtering criteria for products
 type ProductFilter struct {
-	Search     string   `json:"search"`
-	Page       int      `json:"page"`
-	PageSize   int      `json:"page_size"`
-	CategoryID uint     `json:"category_id,omitempty"`
-	MinPrice   *float64 `json:"min_price,omitempty"`
-	MaxPrice   *float64 `json:"max_price,omitempty"`
-	SortBy     string   `json:"sort_by,omitempty"`
-	SortOrder  string   `json:"sort_order,omitempty"`
+	Search          string   `json:"search"`
+	Page            int      `json:"page"`
+	PageSize        int      `json:"page_size"`
+	CategoryID      uint     `json:"category_id,omitempty"`
+	MinPrice        *float64 `json:"min_price,omitempty"`
+	MaxPrice        *float64 `json:"max_price,omitempty"`
+	SortBy          string   `json:"sort_by,omitempty"`
+	SortOrder       string   `json:"sort_order,omitempty"`
+	IncludeArchived bool     `json:"include_archived,omitempty"`
+	// CreatedAfter lists only the products created at or after this time
+	CreatedAfter *time.Time `json:"created_after,omitempty"`
+	// After switches to keyset pagination, listing the products after the cursor instead of a page
+	After *ProductCursor `json:"after,omitempty"`
 }
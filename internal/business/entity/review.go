@@ -2,14 +2,39 @@ package entity
 
 import "time"
 
+// Review preview sort orders
+const (
+	ReviewSortNewest = "newest"
+	ReviewSortRating = "rating"
+)
+
 // Review represents a product review
 type Review struct {
-	ID        uint      `json:"id"`
-	ProductID uint      `json:"product_id"`
-	UserID    uint      `json:"user_id"`
-	Rating    int       `json:"rating"`
-	Comment   string    `json:"comment"`
+	ID        uint   `json:"id"`
+	ProductID uint   `json:"product_id"`
+	UserID    uint   `json:"user_id"`
+	Rating    int    `json:"rating"`
+	Comment   string `json:"comment"`
+	// Approved reviews count toward the product's rating. Reviews are
+	// approved when they are created.
+	Approved  bool      `json:"approved"`
 	User      User      `json:"user,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// ReviewSummary summarizes a product's ratings
+type ReviewSummary struct {
+	ProductID uint
+	Average   float64
+	Total     int
+	// Distribution counts the reviews for each rating from 1 to 5
+	Distribution map[int]int
+}
+
+// ReviewPreview selects the reviews embedded in a product's detail
+type ReviewPreview struct {
+	Sort      string
+	MinRating int
+	Limit     int
+}
@@ -1,5 +1,17 @@
 package entity
 
+import "time"
+
+// StatsUpdateEventName identifies stats update events pushed to WebSocket clients
+const StatsUpdateEventName = "stats_update"
+
+// Metrics products are ranked by. Without an orders table, best-sellers are
+// approximated by one of them.
+const (
+	MetricReviews  = "reviews"
+	MetricWishlist = "wishlist"
+)
+
 // CategoryStat represents statistics for a category
 type CategoryStat struct {
 	CategoryID   uint   `json:"category_id"`
@@ -21,3 +33,21 @@ type TopProduct struct {
 	Count       int    `json:"count"`
 	Metric      string `json:"metric"`
 }
+
+// Stats is a snapshot of the cached statistics
+type Stats struct {
+	TotalProducts int64
+	TotalUsers    int64
+	TotalReviews  int64
+	AverageRating float64
+	LastRefreshed time.Time
+	// Misc holds the other cached statistics by key
+	Misc map[string]interface{}
+}
+
+// StatsUpdateEvent is broadcast to WebSocket clients after the stats are refreshed
+type StatsUpdateEvent struct {
+	Event     string                 `json:"event"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
@@ -6,16 +6,18 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-// User represents a user in the system
+// User represents a user in the system. MustChangePassword is set on
+// bootstrapped accounts until their owner picks a password of their own.
 type User struct {
-	ID           uint      `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	FullName     string    `json:"full_name"`
-	Role         string    `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                 uint      `json:"id"`
+	Username           string    `json:"username"`
+	Email              string    `json:"email"`
+	PasswordHash       string    `json:"-"`
+	FullName           string    `json:"full_name"`
+	Role               string    `json:"role"`
+	MustChangePassword bool      `json:"must_change_password"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 // SetPassword hashes a password and sets it to the user
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// AuthUseCase defines the user registration and login business logic
+type AuthUseCase interface {
+	Register(ctx context.Context, user *entity.User, password string) error
+	Login(ctx context.Context, identifier, password string) (*entity.User, error)
+	ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) (*entity.User, error)
+	BootstrapAdmin(ctx context.Context, admin *entity.User, password string) error
+}
+
+// authUseCase implements AuthUseCase
+type authUseCase struct {
+	userRepo    storage.UserRepository
+	logger      *logger.Logger
+	defaultRole string
+}
+
+// adminRole is the role with access to the admin endpoints
+const adminRole = "admin"
+
+// NewAuthUseCase creates a new AuthUseCase. Self-registered users get
+// defaultRole, which may never be the admin role.
+func NewAuthUseCase(userRepo storage.UserRepository, logger *logger.Logger, defaultRole string) AuthUseCase {
+	if defaultRole == "" || defaultRole == adminRole {
+		logger.Warnf("Default role %q is not allowed for self-registered users, using \"user\"", defaultRole)
+		defaultRole = "user"
+	}
+
+	return &authUseCase{
+		userRepo:    userRepo,
+		logger:      logger,
+		defaultRole: defaultRole,
+	}
+}
+
+// Register creates a new user with a bcrypt-hashed password
+func (uc *authUseCase) Register(ctx context.Context, user *entity.User, password string) error {
+	// Check if username or email is taken
+	existing, err := uc.userRepo.FindByUsername(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrUserExists
+	}
+	existing, err = uc.userRepo.FindByEmail(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrUserExists
+	}
+
+	// Self-registered users never get elevated roles
+	user.Role = uc.defaultRole
+
+	// Hash password
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+
+	// Create user; the unique indexes catch a concurrent registration
+	if err := uc.userRepo.Create(ctx, user); err != nil {
+		if errors.Is(err, storage.ErrDuplicateKey) {
+			return ErrUserExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Login verifies a user's credentials. The identifier may be a username or an email.
+func (uc *authUseCase) Login(ctx context.Context, identifier, password string) (*entity.User, error) {
+	var (
+		user *entity.User
+		err  error
+	)
+	if strings.Contains(identifier, "@") {
+		user, err = uc.userRepo.FindByEmail(ctx, identifier)
+	} else {
+		user, err = uc.userRepo.FindByUsername(ctx, identifier)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the same error for unknown users and wrong passwords
+	if user == nil || !user.CheckPassword(password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// ChangePassword replaces a user's password after verifying the current one
+// and clears any pending forced password change
+func (uc *authUseCase) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) (*entity.User, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.CheckPassword(currentPassword) {
+		return nil, ErrInvalidCredentials
+	}
+	if newPassword == currentPassword {
+		return nil, newValidationError("new password must differ from the current password")
+	}
+
+	if err := user.SetPassword(newPassword); err != nil {
+		return nil, err
+	}
+	user.MustChangePassword = false
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// BootstrapAdmin creates the first admin user with the given password unless
+// an admin already exists. The admin must change the password on first login.
+func (uc *authUseCase) BootstrapAdmin(ctx context.Context, admin *entity.User, password string) error {
+	count, err := uc.userRepo.CountByRole(ctx, adminRole)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if password == "" {
+		return ErrBootstrapPassword
+	}
+
+	admin.Role = adminRole
+	admin.MustChangePassword = true
+	if err := admin.SetPassword(password); err != nil {
+		return err
+	}
+	if err := uc.userRepo.Create(ctx, admin); err != nil {
+		return err
+	}
+
+	uc.logger.WithContext(ctx).WithField("username", admin.Username).Info("Bootstrapped admin user")
+	return nil
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+)
+
+// memoryUserRepo stores users in memory
+type memoryUserRepo struct {
+	users []*entity.User
+}
+
+func (r *memoryUserRepo) Create(ctx context.Context, user *entity.User) error {
+	user.ID = uint(len(r.users) + 1)
+	stored := *user
+	r.users = append(r.users, &stored)
+	return nil
+}
+
+func (r *memoryUserRepo) find(match func(*entity.User) bool) *entity.User {
+	for _, user := range r.users {
+		if match(user) {
+			found := *user
+			return &found
+		}
+	}
+	return nil
+}
+
+func (r *memoryUserRepo) FindByID(ctx context.Context, id uint) (*entity.User, error) {
+	return r.find(func(u *entity.User) bool { return u.ID == id }), nil
+}
+
+func (r *memoryUserRepo) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.find(func(u *entity.User) bool { return u.Username == username }), nil
+}
+
+func (r *memoryUserRepo) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.find(func(u *entity.User) bool { return u.Email == email }), nil
+}
+
+func (r *memoryUserRepo) CountByRole(ctx context.Context, role string) (int64, error) {
+	var count int64
+	for _, user := range r.users {
+		if user.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryUserRepo) Count(ctx context.Context) (int64, error) {
+	return int64(len(r.users)), nil
+}
+
+func (r *memoryUserRepo) Update(ctx context.Context, user *entity.User) error {
+	stored := *user
+	r.users[user.ID-1] = &stored
+	return nil
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+	repo := &memoryUserRepo{}
+	uc := NewAuthUseCase(repo, testLogger(), "user")
+	ctx := context.Background()
+
+	if err := uc.BootstrapAdmin(ctx, &entity.User{Username: "admin", Email: "admin@example.com"}, ""); err != ErrBootstrapPassword {
+		t.Fatalf("BootstrapAdmin() without a password error = %v, want %v", err, ErrBootstrapPassword)
+	}
+
+	if err := uc.BootstrapAdmin(ctx, &entity.User{Username: "admin", Email: "admin@example.com"}, "initial-secret"); err != nil {
+		t.Fatalf("BootstrapAdmin() error = %v", err)
+	}
+	admin, _ := repo.FindByUsername(ctx, "admin")
+	if admin == nil || admin.Role != "admin" || !admin.MustChangePassword {
+		t.Fatalf("bootstrapped admin = %+v, want an admin who must change the password", admin)
+	}
+	if !admin.CheckPassword("initial-secret") {
+		t.Error("bootstrapped admin doesn't have the given password")
+	}
+
+	// Once an admin exists, bootstrapping does nothing, even without a password
+	if err := uc.BootstrapAdmin(ctx, &entity.User{Username: "other", Email: "other@example.com"}, ""); err != nil {
+		t.Fatalf("BootstrapAdmin() with an existing admin error = %v", err)
+	}
+	if count, _ := repo.Count(ctx); count != 1 {
+		t.Errorf("%d users exist, want only the first admin", count)
+	}
+}
+
+func TestRegister_NeverGrantsAdmin(t *testing.T) {
+	tests := []struct {
+		name        string
+		defaultRole string
+		want        string
+	}{
+		{name: "configured role", defaultRole: "customer", want: "customer"},
+		{name: "empty role", defaultRole: "", want: "user"},
+		{name: "admin role", defaultRole: "admin", want: "user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewAuthUseCase(&memoryUserRepo{}, testLogger(), tt.defaultRole)
+			user := &entity.User{Username: "jane", Email: "jane@example.com", Role: "admin"}
+			if err := uc.Register(context.Background(), user, "password123"); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			if user.Role != tt.want {
+				t.Errorf("Role = %q, want %q", user.Role, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangePassword_ClearsForcedChange(t *testing.T) {
+	repo := &memoryUserRepo{}
+	uc := NewAuthUseCase(repo, testLogger(), "user")
+	ctx := context.Background()
+	if err := uc.BootstrapAdmin(ctx, &entity.User{Username: "admin", Email: "admin@example.com"}, "initial-secret"); err != nil {
+		t.Fatalf("BootstrapAdmin() error = %v", err)
+	}
+
+	if _, err := uc.ChangePassword(ctx, 1, "wrong", "new-secret"); err != ErrInvalidCredentials {
+		t.Errorf("ChangePassword() with a wrong password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := uc.ChangePassword(ctx, 1, "initial-secret", "initial-secret"); err == nil {
+		t.Error("ChangePassword() accepted the current password as the new one")
+	}
+
+	user, err := uc.ChangePassword(ctx, 1, "initial-secret", "new-secret")
+	if err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if user.MustChangePassword {
+		t.Error("the forced password change is still pending")
+	}
+	if _, err := uc.Login(ctx, "admin", "new-secret"); err != nil {
+		t.Errorf("Login() with the new password error = %v", err)
+	}
+	if _, err := uc.Login(ctx, "admin", "initial-secret"); err != ErrInvalidCredentials {
+		t.Errorf("Login() with the old password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// CategoryUseCase defines the category business logic
+type CategoryUseCase interface {
+	CreateCategory(ctx context.Context, category *entity.Category) error
+	GetCategory(ctx context.Context, id uint) (*entity.Category, error)
+	ListCategories(ctx context.Context, filter entity.CategoryFilter) ([]entity.CategorySummary, error)
+	CategoryTree(ctx context.Context) ([]entity.CategoryNode, error)
+	UpdateCategory(ctx context.Context, category *entity.Category) error
+	DeleteCategory(ctx context.Context, id uint, reassignTo *uint) error
+	DisableCategory(ctx context.Context, id uint) error
+	EnableCategory(ctx context.Context, id uint) error
+}
+
+// categoryUseCase implements CategoryUseCase
+type categoryUseCase struct {
+	categoryRepo  storage.CategoryRepository
+	logger        *logger.Logger
+	deleteCascade bool
+}
+
+// NewCategoryUseCase creates a new CategoryUseCase. Deleting a category that
+// still has products removes it from them if deleteCascade is set and fails
+// with ErrCategoryInUse otherwise.
+func NewCategoryUseCase(categoryRepo storage.CategoryRepository, logger *logger.Logger, deleteCascade bool) CategoryUseCase {
+	return &categoryUseCase{
+		categoryRepo:  categoryRepo,
+		logger:        logger,
+		deleteCascade: deleteCascade,
+	}
+}
+
+// CreateCategory creates a new category
+func (uc *categoryUseCase) CreateCategory(ctx context.Context, category *entity.Category) error {
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	if err := uc.validateParent(ctx, category); err != nil {
+		return err
+	}
+
+	return uc.categoryRepo.Create(ctx, category)
+}
+
+// GetCategory gets a category by ID
+func (uc *categoryUseCase) GetCategory(ctx context.Context, id uint) (*entity.Category, error) {
+	category, err := uc.categoryRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	return category, nil
+}
+
+// ListCategories lists the categories matching the filter with the number of
+// products directly in each
+func (uc *categoryUseCase) ListCategories(ctx context.Context, filter entity.CategoryFilter) ([]entity.CategorySummary, error) {
+	categories, err := uc.categoryRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := uc.categoryRepo.CountProductsByCategory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]entity.CategorySummary, 0, len(categories))
+	for _, category := range categories {
+		summaries = append(summaries, entity.CategorySummary{
+			Category:     category,
+			ProductCount: counts[category.ID],
+		})
+	}
+
+	return summaries, nil
+}
+
+// CategoryTree returns the category hierarchy
+func (uc *categoryUseCase) CategoryTree(ctx context.Context) ([]entity.CategoryNode, error) {
+	return uc.categoryRepo.Tree(ctx)
+}
+
+// UpdateCategory renames a category, changes its description or moves it
+// under another parent
+func (uc *categoryUseCase) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	if err := uc.validateParent(ctx, category); err != nil {
+		return err
+	}
+
+	if err := uc.categoryRepo.Update(ctx, category); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+
+	// Return the stored category, which keeps its disabled flag
+	updated, err := uc.categoryRepo.FindByID(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return ErrCategoryNotFound
+	}
+	*category = *updated
+
+	return nil
+}
+
+// validateCategory validates a category's fields
+func validateCategory(category *entity.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return newValidationError("category name is required")
+	}
+	return nil
+}
+
+// validateParent checks that a category's parent exists and that the category
+// isn't nested under itself or one of its descendants
+func (uc *categoryUseCase) validateParent(ctx context.Context, category *entity.Category) error {
+	if category.ParentID == nil {
+		return nil
+	}
+	if *category.ParentID == category.ID {
+		return newValidationError("a category can't be its own parent")
+	}
+
+	categories, err := uc.categoryRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	parents := make(map[uint]*uint, len(categories))
+	for _, c := range categories {
+		parents[c.ID] = c.ParentID
+	}
+
+	if _, ok := parents[*category.ParentID]; !ok {
+		return newValidationError("parent category not found")
+	}
+
+	// Walk up from the new parent; reaching the category means it would become
+	// its own ancestor. New categories have no descendants yet.
+	if category.ID == 0 {
+		return nil
+	}
+	for id := category.ParentID; id != nil; id = parents[*id] {
+		if *id == category.ID {
+			return newValidationError("a category can't be nested under its own sub-category")
+		}
+	}
+
+	return nil
+}
+
+// DeleteCategory deletes a category, optionally moving its products to the
+// reassignTo category
+func (uc *categoryUseCase) DeleteCategory(ctx context.Context, id uint, reassignTo *uint) error {
+	// Check if category exists
+	category, err := uc.categoryRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return ErrCategoryNotFound
+	}
+
+	// Validate the fallback category
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return newValidationError("reassign_to must differ from the deleted category")
+		}
+		target, err := uc.categoryRepo.FindByID(ctx, *reassignTo)
+		if err != nil {
+			return err
+		}
+		if target == nil || target.Disabled {
+			return newValidationError("reassign_to category not found")
+		}
+	}
+
+	if err := uc.categoryRepo.Delete(ctx, id, reassignTo, uc.deleteCascade); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrReferenced):
+			return ErrCategoryInUse
+		case errors.Is(err, storage.ErrNotFound):
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DisableCategory disables a category. Its products keep it, but no product
+// can be added to it until it is enabled again.
+func (uc *categoryUseCase) DisableCategory(ctx context.Context, id uint) error {
+	return uc.setDisabled(ctx, id, true)
+}
+
+// EnableCategory re-enables a disabled category
+func (uc *categoryUseCase) EnableCategory(ctx context.Context, id uint) error {
+	return uc.setDisabled(ctx, id, false)
+}
+
+// setDisabled disables or re-enables an existing category
+func (uc *categoryUseCase) setDisabled(ctx context.Context, id uint, disabled bool) error {
+	// Check if category exists
+	category, err := uc.categoryRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return ErrCategoryNotFound
+	}
+
+	return uc.categoryRepo.SetDisabled(ctx, id, disabled)
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the use cases that handlers map to specific HTTP statuses
+var (
+	ErrProductNotFound    = errors.New("product not found")
+	ErrUserExists         = errors.New("username or email already exists")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrBootstrapPassword  = errors.New("no admin user exists and no bootstrap admin password is set")
+	ErrSearchDisabled     = errors.New("search backend is disabled")
+	ErrScheduleNotFound   = errors.New("price schedule not found")
+	ErrScheduleNotPending = errors.New("only pending price schedules can be modified")
+	ErrReviewExists       = errors.New("user has already reviewed this product")
+	ErrCategoryNotFound   = errors.New("category not found")
+	ErrCategoryInUse      = errors.New("category still has products")
+	ErrSKUExists          = errors.New("a product with this SKU already exists")
+)
+
+// ValidationError reports input rejected by a use case's business rules
+type ValidationError struct {
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// newValidationError creates a new ValidationError
+func newValidationError(message string) error {
+	return &ValidationError{Message: message}
+}
+
+// InsufficientStockError reports a stock adjustment that would take a
+// product's stock below zero
+type InsufficientStockError struct {
+	ProductID uint
+	Available int
+	Requested int
+}
+
+// Error implements the error interface
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %d: %d available, %d requested", e.ProductID, e.Available, e.Requested)
+}
+
+// StatusTransitionError reports a product status change the transition table doesn't allow
+type StatusTransitionError struct {
+	From string
+	To   string
+}
+
+// Error implements the error interface
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("product status can't change from %s to %s", e.From, e.To)
+}
+
+// LockedFieldError reports a change to a field that is immutable once the product is published
+type LockedFieldError struct {
+	Field string
+}
+
+// Error implements the error interface
+func (e *LockedFieldError) Error() string {
+	return fmt.Sprintf("%s can't be changed once the product is published", e.Field)
+}
@@ -0,0 +1,8 @@
+package usecase
+
+import "github.com/thanhnguyen/product-api/pkg/logger"
+
+// testLogger returns a logger that only reports errors
+func testLogger() *logger.Logger {
+	return logger.NewLogger("error", "text", "stdout")
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	"github.com/thanhnguyen/product-api/pkg/notifier"
+)
+
+// PriceAlertUseCase defines the price-drop alert business logic
+type PriceAlertUseCase interface {
+	Subscribe(ctx context.Context, alert *entity.PriceAlert) error
+	Unsubscribe(ctx context.Context, userID, productID uint) error
+	ListAlerts(ctx context.Context, userID uint) ([]entity.PriceAlert, error)
+	NotifyPriceDrop(ctx context.Context, product *entity.Product, oldPrice float64)
+}
+
+// priceAlertUseCase implements PriceAlertUseCase
+type priceAlertUseCase struct {
+	alertRepo   storage.PriceAlertRepository
+	productRepo storage.ProductRepository
+	notifier    notifier.Notifier
+	logger      *logger.Logger
+}
+
+// NewPriceAlertUseCase creates a new PriceAlertUseCase
+func NewPriceAlertUseCase(
+	alertRepo storage.PriceAlertRepository,
+	productRepo storage.ProductRepository,
+	notifier notifier.Notifier,
+	logger *logger.Logger,
+) PriceAlertUseCase {
+	return &priceAlertUseCase{
+		alertRepo:   alertRepo,
+		productRepo: productRepo,
+		notifier:    notifier,
+		logger:      logger,
+	}
+}
+
+// Subscribe subscribes a user to a price-drop alert for a product
+func (uc *priceAlertUseCase) Subscribe(ctx context.Context, alert *entity.PriceAlert) error {
+	if alert.Threshold <= 0 {
+		return newValidationError("threshold must be greater than zero")
+	}
+
+	// Check if product exists
+	product, err := uc.productRepo.FindByID(ctx, alert.ProductID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return ErrProductNotFound
+	}
+
+	return uc.alertRepo.Subscribe(ctx, alert)
+}
+
+// Unsubscribe removes a user's price-drop alert for a product
+func (uc *priceAlertUseCase) Unsubscribe(ctx context.Context, userID, productID uint) error {
+	return uc.alertRepo.Unsubscribe(ctx, userID, productID)
+}
+
+// ListAlerts lists a user's price-drop alerts
+func (uc *priceAlertUseCase) ListAlerts(ctx context.Context, userID uint) ([]entity.PriceAlert, error) {
+	return uc.alertRepo.ListByUser(ctx, userID)
+}
+
+// NotifyPriceDrop enqueues notifications for every subscriber whose threshold
+// the product's new price has reached. Failures are logged rather than
+// returned so they never fail the price update itself.
+func (uc *priceAlertUseCase) NotifyPriceDrop(ctx context.Context, product *entity.Product, oldPrice float64) {
+	if product.Price >= oldPrice {
+		return
+	}
+
+	alerts, err := uc.alertRepo.FindTriggered(ctx, product.ID, product.Price)
+	if err != nil {
+		uc.logger.WithContext(ctx).WithError(err).WithField("product_id", product.ID).Error("Failed to find triggered price alerts")
+		return
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(alerts))
+	for _, alert := range alerts {
+		err := uc.notifier.Notify(ctx, notifier.Notification{
+			Recipient: alert.UserEmail,
+			Subject:   fmt.Sprintf("Price drop: %s", product.Name),
+			Body: fmt.Sprintf("%s is now %.2f (was %.2f), at or below your alert price of %.2f.",
+				product.Name, product.Price, oldPrice, alert.Threshold),
+		})
+		if err != nil {
+			uc.logger.WithContext(ctx).WithError(err).WithField("alert_id", alert.ID).Error("Failed to enqueue price alert notification")
+			continue
+		}
+		ids = append(ids, alert.ID)
+	}
+
+	if err := uc.alertRepo.MarkNotified(ctx, ids); err != nil {
+		uc.logger.WithContext(ctx).WithError(err).WithField("product_id", product.ID).Error("Failed to mark price alerts as notified")
+	}
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/notifier"
+)
+
+// fakeAlertRepo returns its alerts as triggered by any price at or below
+// their threshold and records the ones marked notified
+type fakeAlertRepo struct {
+	storage.PriceAlertRepository
+
+	alerts   []entity.PriceAlert
+	lookups  int
+	notified []uint
+}
+
+func (r *fakeAlertRepo) FindTriggered(ctx context.Context, productID uint, price float64) ([]entity.PriceAlert, error) {
+	r.lookups++
+	var triggered []entity.PriceAlert
+	for _, alert := range r.alerts {
+		if alert.ProductID == productID && alert.Threshold >= price && alert.NotifiedAt == nil {
+			triggered = append(triggered, alert)
+		}
+	}
+	return triggered, nil
+}
+
+func (r *fakeAlertRepo) MarkNotified(ctx context.Context, ids []uint) error {
+	r.notified = append(r.notified, ids...)
+	return nil
+}
+
+// recordingNotifier records the notifications it is asked to send
+type recordingNotifier struct {
+	sent []notifier.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification notifier.Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func TestNotifyPriceDrop(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldPrice   float64
+		newPrice   float64
+		wantNotify []string
+	}{
+		{"price drop below the threshold", 120, 95, []string{"a@example.com"}},
+		{"price drop to the threshold", 120, 100, []string{"a@example.com"}},
+		{"price drop above the threshold", 120, 110, nil},
+		{"price rise", 95, 120, nil},
+		{"unchanged price", 95, 95, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAlertRepo{alerts: []entity.PriceAlert{
+				{ID: 1, ProductID: 7, Threshold: 100, UserEmail: "a@example.com"},
+				{ID: 2, ProductID: 8, Threshold: 500, UserEmail: "b@example.com"},
+			}}
+			sender := &recordingNotifier{}
+			uc := NewPriceAlertUseCase(repo, nil, sender, testLogger())
+
+			product := &entity.Product{ID: 7, Name: "Lamp", Price: tt.newPrice}
+			uc.NotifyPriceDrop(context.Background(), product, tt.oldPrice)
+
+			if len(sender.sent) != len(tt.wantNotify) {
+				t.Fatalf("sent %d notifications, want %d: %+v", len(sender.sent), len(tt.wantNotify), sender.sent)
+			}
+			for i, recipient := range tt.wantNotify {
+				if sender.sent[i].Recipient != recipient {
+					t.Errorf("notification %d went to %s, want %s", i, sender.sent[i].Recipient, recipient)
+				}
+			}
+			if len(tt.wantNotify) > 0 && !equalIDs(repo.notified, []uint{1}) {
+				t.Errorf("marked notified = %v, want [1]", repo.notified)
+			}
+			// A price that didn't drop never looks up alerts
+			if tt.newPrice >= tt.oldPrice && repo.lookups != 0 {
+				t.Errorf("looked up alerts %d times for a price that didn't drop", repo.lookups)
+			}
+		})
+	}
+}
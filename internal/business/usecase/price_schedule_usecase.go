@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// PriceScheduleUseCase defines the scheduled price change business logic
+type PriceScheduleUseCase interface {
+	CreateSchedule(ctx context.Context, schedule *entity.ScheduledPriceChange) error
+	ListSchedules(ctx context.Context) ([]entity.ScheduledPriceChange, error)
+	GetSchedule(ctx context.Context, id uint) (*entity.ScheduledPriceChange, error)
+	UpdateSchedule(ctx context.Context, schedule *entity.ScheduledPriceChange) error
+	DeleteSchedule(ctx context.Context, id uint) error
+	ProcessDue(ctx context.Context) error
+}
+
+// priceScheduleUseCase implements PriceScheduleUseCase
+type priceScheduleUseCase struct {
+	scheduleRepo storage.PriceScheduleRepository
+	productRepo  storage.ProductRepository
+	categoryRepo storage.CategoryRepository
+	logger       *logger.Logger
+	interval     time.Duration
+	now          func() time.Time
+}
+
+// NewPriceScheduleUseCase creates a new PriceScheduleUseCase and starts the
+// background scheduler that applies and reverts due schedules every interval.
+// The scheduler stops when ctx is cancelled.
+func NewPriceScheduleUseCase(
+	ctx context.Context,
+	scheduleRepo storage.PriceScheduleRepository,
+	productRepo storage.ProductRepository,
+	categoryRepo storage.CategoryRepository,
+	logger *logger.Logger,
+	interval time.Duration,
+) PriceScheduleUseCase {
+	uc := &priceScheduleUseCase{
+		scheduleRepo: scheduleRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		logger:       logger,
+		interval:     interval,
+		now:          time.Now,
+	}
+
+	// Start the background scheduler goroutine
+	go uc.startSchedulerLoop(ctx)
+
+	return uc
+}
+
+// startSchedulerLoop periodically processes due schedules
+func (uc *priceScheduleUseCase) startSchedulerLoop(ctx context.Context) {
+	ticker := time.NewTicker(uc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := uc.ProcessDue(ctx); err != nil && ctx.Err() == nil {
+				uc.logger.WithError(err).Error("Failed to process scheduled price changes")
+			}
+		}
+	}
+}
+
+// CreateSchedule creates a new scheduled price change
+func (uc *priceScheduleUseCase) CreateSchedule(ctx context.Context, schedule *entity.ScheduledPriceChange) error {
+	if err := uc.validateSchedule(ctx, schedule); err != nil {
+		return err
+	}
+
+	schedule.Status = entity.ScheduleStatusPending
+	return uc.scheduleRepo.Create(ctx, schedule)
+}
+
+// ListSchedules lists all scheduled price changes
+func (uc *priceScheduleUseCase) ListSchedules(ctx context.Context) ([]entity.ScheduledPriceChange, error) {
+	return uc.scheduleRepo.List(ctx)
+}
+
+// GetSchedule gets a scheduled price change by ID
+func (uc *priceScheduleUseCase) GetSchedule(ctx context.Context, id uint) (*entity.ScheduledPriceChange, error) {
+	schedule, err := uc.scheduleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+// UpdateSchedule updates a scheduled price change that hasn't been applied yet
+func (uc *priceScheduleUseCase) UpdateSchedule(ctx context.Context, schedule *entity.ScheduledPriceChange) error {
+	existing, err := uc.GetSchedule(ctx, schedule.ID)
+	if err != nil {
+		return err
+	}
+	if existing.Status != entity.ScheduleStatusPending {
+		return ErrScheduleNotPending
+	}
+
+	if err := uc.validateSchedule(ctx, schedule); err != nil {
+		return err
+	}
+
+	schedule.Status = existing.Status
+	schedule.CreatedAt = existing.CreatedAt
+	return uc.scheduleRepo.Update(ctx, schedule)
+}
+
+// DeleteSchedule deletes a scheduled price change, reverting it first if it is currently applied
+func (uc *priceScheduleUseCase) DeleteSchedule(ctx context.Context, id uint) error {
+	schedule, err := uc.GetSchedule(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if schedule.Status == entity.ScheduleStatusApplied {
+		if err := uc.scheduleRepo.Revert(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return uc.scheduleRepo.Delete(ctx, id)
+}
+
+// ProcessDue applies pending schedules whose effective time has passed and
+// reverts applied schedules whose revert time has passed
+func (uc *priceScheduleUseCase) ProcessDue(ctx context.Context) error {
+	now := uc.now()
+
+	schedules, err := uc.scheduleRepo.FindDue(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		if schedule.Status == entity.ScheduleStatusPending {
+			if err := uc.scheduleRepo.Apply(ctx, schedule.ID); err != nil {
+				uc.logger.WithContext(ctx).WithError(err).WithField("schedule_id", schedule.ID).Error("Failed to apply scheduled price change")
+				continue
+			}
+			uc.logger.WithContext(ctx).WithField("schedule_id", schedule.ID).Info("Scheduled price change applied")
+			schedule.Status = entity.ScheduleStatusApplied
+		}
+
+		// A schedule may be both applied and reverted in the same run if the scheduler was down
+		if schedule.Status == entity.ScheduleStatusApplied && schedule.RevertAt != nil && !schedule.RevertAt.After(now) {
+			if err := uc.scheduleRepo.Revert(ctx, schedule.ID); err != nil {
+				uc.logger.WithContext(ctx).WithError(err).WithField("schedule_id", schedule.ID).Error("Failed to revert scheduled price change")
+				continue
+			}
+			uc.logger.WithContext(ctx).WithField("schedule_id", schedule.ID).Info("Scheduled price change reverted")
+		}
+	}
+
+	return nil
+}
+
+// validateSchedule validates a scheduled price change and its target
+func (uc *priceScheduleUseCase) validateSchedule(ctx context.Context, schedule *entity.ScheduledPriceChange) error {
+	if (schedule.ProductID == nil) == (schedule.CategoryID == nil) {
+		return newValidationError("exactly one of product_id or category_id is required")
+	}
+	if schedule.NewPrice <= 0 {
+		return newValidationError("new price must be greater than zero")
+	}
+	if schedule.EffectiveAt.IsZero() {
+		return newValidationError("effective_at is required")
+	}
+	if schedule.RevertAt != nil && !schedule.RevertAt.After(schedule.EffectiveAt) {
+		return newValidationError("revert_at must be after effective_at")
+	}
+
+	// Check the target exists
+	if schedule.ProductID != nil {
+		product, err := uc.productRepo.FindByID(ctx, *schedule.ProductID)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return newValidationError("product not found")
+		}
+	} else {
+		category, err := uc.categoryRepo.FindByID(ctx, *schedule.CategoryID)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return newValidationError("category not found")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+)
+
+// fakeScheduleRepo keeps schedules in memory, finding the due ones like the
+// Postgres repository does, and records the ones it applies and reverts
+type fakeScheduleRepo struct {
+	storage.PriceScheduleRepository
+
+	mu        sync.Mutex
+	schedules []entity.ScheduledPriceChange
+	polls     int
+	applied   []uint
+	reverted  []uint
+}
+
+func (r *fakeScheduleRepo) FindDue(ctx context.Context, now time.Time) ([]entity.ScheduledPriceChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.polls++
+	var due []entity.ScheduledPriceChange
+	for _, schedule := range r.schedules {
+		pending := schedule.Status == entity.ScheduleStatusPending && !schedule.EffectiveAt.After(now)
+		reverting := schedule.Status == entity.ScheduleStatusApplied && schedule.RevertAt != nil && !schedule.RevertAt.After(now)
+		if pending || reverting {
+			due = append(due, schedule)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeScheduleRepo) Apply(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.applied = append(r.applied, id)
+	r.setStatus(id, entity.ScheduleStatusApplied)
+	return nil
+}
+
+func (r *fakeScheduleRepo) Revert(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reverted = append(r.reverted, id)
+	r.setStatus(id, entity.ScheduleStatusReverted)
+	return nil
+}
+
+// setStatus changes the status of a schedule. The caller holds mu.
+func (r *fakeScheduleRepo) setStatus(id uint, status string) {
+	for i := range r.schedules {
+		if r.schedules[i].ID == id {
+			r.schedules[i].Status = status
+		}
+	}
+}
+
+func (r *fakeScheduleRepo) pollCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.polls
+}
+
+func TestProcessDue_AppliesAndReverts(t *testing.T) {
+	effectiveAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	revertAt := effectiveAt.Add(24 * time.Hour)
+	repo := &fakeScheduleRepo{schedules: []entity.ScheduledPriceChange{
+		{ID: 1, Status: entity.ScheduleStatusPending, EffectiveAt: effectiveAt, RevertAt: &revertAt},
+	}}
+	now := effectiveAt.Add(-time.Minute)
+	uc := &priceScheduleUseCase{scheduleRepo: repo, logger: testLogger(), now: func() time.Time { return now }}
+
+	// Step the clock across the effective and revert times
+	steps := []struct {
+		name         string
+		now          time.Time
+		wantApplied  []uint
+		wantReverted []uint
+	}{
+		{"before effective_at", effectiveAt.Add(-time.Minute), nil, nil},
+		{"at effective_at", effectiveAt, []uint{1}, nil},
+		{"before revert_at", revertAt.Add(-time.Minute), []uint{1}, nil},
+		{"at revert_at", revertAt, []uint{1}, []uint{1}},
+		{"after revert_at", revertAt.Add(time.Hour), []uint{1}, []uint{1}},
+	}
+	for _, step := range steps {
+		now = step.now
+		if err := uc.ProcessDue(context.Background()); err != nil {
+			t.Fatalf("%s: ProcessDue() error = %v", step.name, err)
+		}
+		if !equalIDs(repo.applied, step.wantApplied) {
+			t.Errorf("%s: applied = %v, want %v", step.name, repo.applied, step.wantApplied)
+		}
+		if !equalIDs(repo.reverted, step.wantReverted) {
+			t.Errorf("%s: reverted = %v, want %v", step.name, repo.reverted, step.wantReverted)
+		}
+	}
+}
+
+func TestProcessDue_AppliesAndRevertsInOneRunAfterDowntime(t *testing.T) {
+	effectiveAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	revertAt := effectiveAt.Add(time.Hour)
+	later := revertAt.Add(time.Hour)
+	repo := &fakeScheduleRepo{schedules: []entity.ScheduledPriceChange{
+		{ID: 1, Status: entity.ScheduleStatusPending, EffectiveAt: effectiveAt, RevertAt: &later},
+		{ID: 2, Status: entity.ScheduleStatusPending, EffectiveAt: effectiveAt, RevertAt: &revertAt},
+		{ID: 3, Status: entity.ScheduleStatusPending, EffectiveAt: later},
+	}}
+	uc := &priceScheduleUseCase{scheduleRepo: repo, logger: testLogger(), now: func() time.Time { return revertAt }}
+
+	if err := uc.ProcessDue(context.Background()); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+	if want := []uint{1, 2}; !equalIDs(repo.applied, want) {
+		t.Errorf("applied = %v, want %v", repo.applied, want)
+	}
+	if want := []uint{2}; !equalIDs(repo.reverted, want) {
+		t.Errorf("reverted = %v, want %v", repo.reverted, want)
+	}
+}
+
+func TestPriceScheduler_StopsWhenContextCancelled(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	ctx, cancel := context.WithCancel(context.Background())
+	NewPriceScheduleUseCase(ctx, repo, nil, nil, testLogger(), time.Millisecond)
+
+	waitFor(t, func() bool { return repo.pollCount() > 0 })
+	cancel()
+
+	// Let an in-flight tick finish, then make sure no more follow
+	time.Sleep(20 * time.Millisecond)
+	polls := repo.pollCount()
+	time.Sleep(20 * time.Millisecond)
+	if got := repo.pollCount(); got != polls {
+		t.Errorf("scheduler polled %d more times after cancellation", got-polls)
+	}
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func equalIDs(got, want []uint) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
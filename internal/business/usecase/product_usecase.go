@@ -3,11 +3,14 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/thanhnguyen/product-api/internal/business/entity"
 	"github.com/thanhnguyen/product-api/internal/storage"
 	"github.com/thanhnguyen/product-api/internal/storage/elasticsearch"
+	"github.com/thanhnguyen/product-api/pkg/ctxutil"
 	"github.com/thanhnguyen/product-api/pkg/logger"
 )
 
@@ -15,39 +18,82 @@ import (
 type ProductUseCase interface {
 	CreateProduct(ctx context.Context, product *entity.Product, categoryIDs []uint) error
 	ListProducts(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, int64, error)
+	ListNewArrivals(ctx context.Context, days, limit int) ([]entity.Product, error)
 	GetProduct(ctx context.Context, id uint) (*entity.Product, error)
-	UpdateProduct(ctx context.Context, product *entity.Product, categoryIDs []uint) error
+	GetProductBySKU(ctx context.Context, sku string) (*entity.Product, error)
+	UpdateProduct(ctx context.Context, product *entity.Product, categoryIDs []uint, overrideLocked bool) error
 	DeleteProduct(ctx context.Context, id uint) error
-	SearchProductsByDescription(ctx context.Context, desc string) ([]entity.Product, error)
+	SearchProductsByDescription(ctx context.Context, desc string, page, pageSize int) ([]entity.ProductSearchResult, int64, error)
+	SearchProducts(ctx context.Context, query string, page, pageSize int) ([]entity.Product, int64, error)
+	BulkUpdatePrices(ctx context.Context, update entity.BulkPriceUpdate) ([]entity.PriceChange, error)
+	AdjustStockBatch(ctx context.Context, deltas map[uint]int) ([]entity.StockChange, error)
+	ArchiveProduct(ctx context.Context, id uint) error
+	UnarchiveProduct(ctx context.Context, id uint) error
+	ChangeStatus(ctx context.Context, id uint, status string) (*entity.Product, error)
+	ReindexProduct(ctx context.Context, id uint) (*elasticsearch.Product, error)
+	CheckSearchConsistency(ctx context.Context, repair bool) (*entity.SearchConsistencyReport, error)
+}
+
+// ProductIndexer keeps the search index in sync with the products
+type ProductIndexer interface {
+	IndexProduct(ctx context.Context, p elasticsearch.Product) error
+	DeleteProduct(ctx context.Context, id uint) error
+	IndexedProducts(ctx context.Context) (map[uint]time.Time, error)
 }
 
 // productUseCase implements ProductUseCase
 type productUseCase struct {
 	productRepo   storage.ProductRepository
 	categoryRepo  storage.CategoryRepository
+	priceAlerts   PriceAlertUseCase
+	stockMonitor  StockMonitor
 	logger        *logger.Logger
 	cacheTimeout  time.Duration
 	productSearch *elasticsearch.ProductSearch
+	indexer       ProductIndexer
+	lockedFields  []string
 }
 
-// NewProductUseCase creates a new ProductUseCase
+// NewProductUseCase creates a new ProductUseCase. Without priceAlerts or
+// stockMonitor, price drops and low stock go unreported.
 func NewProductUseCase(
 	productRepo storage.ProductRepository,
 	categoryRepo storage.CategoryRepository,
+	priceAlerts PriceAlertUseCase,
+	stockMonitor StockMonitor,
 	logger *logger.Logger,
 	cacheTimeout time.Duration,
 	productSearch *elasticsearch.ProductSearch,
+	indexer ProductIndexer,
+	lockedFields []string,
 ) ProductUseCase {
+	// Unknown fields would never match, so warn about them
+	for _, field := range lockedFields {
+		if _, ok := productFieldValue(&entity.Product{}, field); !ok {
+			logger.Warnf("Unknown immutable product field %q is ignored", field)
+		}
+	}
+
 	return &productUseCase{
-		productRepo:  productRepo,
-		categoryRepo: categoryRepo,
-		logger:       logger,
-		cacheTimeout: cacheTimeout,
+		productRepo:   productRepo,
+		categoryRepo:  categoryRepo,
+		priceAlerts:   priceAlerts,
+		stockMonitor:  stockMonitor,
+		logger:        logger,
+		cacheTimeout:  cacheTimeout,
+		productSearch: productSearch,
+		indexer:       indexer,
+		lockedFields:  lockedFields,
 	}
 }
 
 // CreateProduct creates a new product
 func (uc *productUseCase) CreateProduct(ctx context.Context, product *entity.Product, categoryIDs []uint) error {
+	// Set default status if not provided
+	if product.Status == "" {
+		product.Status = entity.ProductStatusActive
+	}
+
 	// Validate product
 	if err := validateProduct(product); err != nil {
 		return err
@@ -60,18 +106,21 @@ func (uc *productUseCase) CreateProduct(ctx context.Context, product *entity.Pro
 			return err
 		}
 		if len(categories) != len(categoryIDs) {
-			return errors.New("one or more categories not found")
+			return newValidationError("one or more categories not found or disabled")
 		}
 		product.Categories = categories
 	}
 
-	// Set default status if not provided
-	if product.Status == "" {
-		product.Status = "active"
+	// Create product
+	if err := uc.productRepo.Create(ctx, product); err != nil {
+		if errors.Is(err, storage.ErrDuplicateKey) {
+			return ErrSKUExists
+		}
+		return err
 	}
 
-	// Create product
-	return uc.productRepo.Create(ctx, product)
+	uc.indexProduct(ctx, product)
+	return nil
 }
 
 // ListProducts lists products with filtering and pagination
@@ -88,6 +137,27 @@ func (uc *productUseCase) ListProducts(ctx context.Context, filter entity.Produc
 	return uc.productRepo.List(ctx, filter)
 }
 
+// ListNewArrivals lists at most limit products created in the last days
+// days, newest first
+func (uc *productUseCase) ListNewArrivals(ctx context.Context, days, limit int) ([]entity.Product, error) {
+	if days < 1 {
+		return nil, newValidationError("days must be at least 1")
+	}
+	if limit < 1 || limit > 100 {
+		return nil, newValidationError("limit must be between 1 and 100")
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	products, _, err := uc.productRepo.List(ctx, entity.ProductFilter{
+		Page:         1,
+		PageSize:     limit,
+		SortBy:       "created_at",
+		SortOrder:    "desc",
+		CreatedAfter: &since,
+	})
+	return products, err
+}
+
 // GetProduct gets a product by ID
 func (uc *productUseCase) GetProduct(ctx context.Context, id uint) (*entity.Product, error) {
 	product, err := uc.productRepo.FindByID(ctx, id)
@@ -100,8 +170,21 @@ func (uc *productUseCase) GetProduct(ctx context.Context, id uint) (*entity.Prod
 	return product, nil
 }
 
-// UpdateProduct updates a product
-func (uc *productUseCase) UpdateProduct(ctx context.Context, product *entity.Product, categoryIDs []uint) error {
+// GetProductBySKU gets a product by SKU
+func (uc *productUseCase) GetProductBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	product, err := uc.productRepo.FindBySKU(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	return product, nil
+}
+
+// UpdateProduct updates a product. Once a product is published its locked
+// fields can only change if overrideLocked is set.
+func (uc *productUseCase) UpdateProduct(ctx context.Context, product *entity.Product, categoryIDs []uint, overrideLocked bool) error {
 	// Check if product exists
 	existingProduct, err := uc.productRepo.FindByID(ctx, product.ID)
 	if err != nil {
@@ -111,6 +194,28 @@ func (uc *productUseCase) UpdateProduct(ctx context.Context, product *entity.Pro
 		return errors.New("product not found")
 	}
 
+	// Keep the current SKU and status unless new ones are given
+	if product.SKU == "" {
+		product.SKU = existingProduct.SKU
+	}
+	if product.Status == "" {
+		product.Status = existingProduct.Status
+	}
+	if err := checkStatusTransition(existingProduct.Status, product.Status); err != nil {
+		return err
+	}
+
+	// Reject changes to locked fields of a published product
+	if existingProduct.Status == entity.ProductStatusActive && !overrideLocked {
+		for _, field := range uc.lockedFields {
+			before, _ := productFieldValue(existingProduct, field)
+			after, _ := productFieldValue(product, field)
+			if before != after {
+				return &LockedFieldError{Field: field}
+			}
+		}
+	}
+
 	// Validate product
 	if err := validateProduct(product); err != nil {
 		return err
@@ -123,13 +228,31 @@ func (uc *productUseCase) UpdateProduct(ctx context.Context, product *entity.Pro
 			return err
 		}
 		if len(categories) != len(categoryIDs) {
-			return errors.New("one or more categories not found")
+			return newValidationError("one or more categories not found or disabled")
 		}
 		product.Categories = categories
 	}
 
 	// Update product
-	return uc.productRepo.Update(ctx, product)
+	if err := uc.productRepo.Update(ctx, product); err != nil {
+		if errors.Is(err, storage.ErrDuplicateKey) {
+			return ErrSKUExists
+		}
+		return err
+	}
+	uc.indexProduct(ctx, product)
+
+	// Notify subscribers of a price drop
+	if uc.priceAlerts != nil && product.Price < existingProduct.Price {
+		uc.priceAlerts.NotifyPriceDrop(ctx, product, existingProduct.Price)
+	}
+
+	// Alert operations when stock runs low
+	if uc.stockMonitor != nil {
+		uc.stockMonitor.CheckStockChange(ctx, product, existingProduct.StockQuantity)
+	}
+
+	return nil
 }
 
 // DeleteProduct deletes a product
@@ -144,7 +267,309 @@ func (uc *productUseCase) DeleteProduct(ctx context.Context, id uint) error {
 	}
 
 	// Delete product
-	return uc.productRepo.Delete(ctx, id)
+	if err := uc.productRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// The index is eventually consistent, so a failure doesn't fail the delete
+	if uc.indexer != nil {
+		if err := uc.indexer.DeleteProduct(ctx, id); err != nil {
+			uc.logger.WithContext(ctx).WithError(err).WithField("product_id", id).Warn("Failed to remove product from the search index")
+		}
+	}
+	return nil
+}
+
+// indexProduct indexes a product's searchable fields. The index is eventually
+// consistent, so a failure is logged and doesn't fail the database write.
+func (uc *productUseCase) indexProduct(ctx context.Context, product *entity.Product) {
+	if uc.indexer == nil {
+		return
+	}
+
+	if err := uc.indexer.IndexProduct(ctx, searchDocument(product)); err != nil {
+		uc.logger.WithContext(ctx).WithError(err).WithField("product_id", product.ID).Warn("Failed to index product")
+	}
+}
+
+// ReindexProduct re-indexes a single product from the database and returns
+// the indexed document. Unlike indexProduct, an indexing failure is returned
+// so a product that drifted out of the index can be repaired on demand.
+func (uc *productUseCase) ReindexProduct(ctx context.Context, id uint) (*elasticsearch.Product, error) {
+	if uc.indexer == nil {
+		return nil, ErrSearchDisabled
+	}
+
+	product, err := uc.productRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	doc := searchDocument(product)
+	if err := uc.indexer.IndexProduct(ctx, doc); err != nil {
+		return nil, err
+	}
+
+	uc.logger.WithContext(ctx).WithField("product_id", id).Info("Product re-indexed")
+	return &doc, nil
+}
+
+// searchDocument builds the search index document of a product
+func searchDocument(product *entity.Product) elasticsearch.Product {
+	return elasticsearch.Product{
+		ID:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		UpdatedAt:   product.UpdatedAt,
+	}
+}
+
+// CheckSearchConsistency compares the products in the database with the
+// documents in the search index. A product is missing without a document and
+// stale when it was updated after it was indexed; a document is orphaned when
+// its product no longer exists. With repair, missing and stale products are
+// re-indexed and orphaned documents removed.
+func (uc *productUseCase) CheckSearchConsistency(ctx context.Context, repair bool) (*entity.SearchConsistencyReport, error) {
+	if uc.indexer == nil {
+		return nil, ErrSearchDisabled
+	}
+
+	products, err := uc.productRepo.ListUpdateTimes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indexed, err := uc.indexer.IndexedProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.SearchConsistencyReport{
+		Checked:  len(products),
+		Indexed:  len(indexed),
+		Missing:  []uint{},
+		Stale:    []uint{},
+		Orphaned: []uint{},
+		Repaired: repair,
+		Failed:   []uint{},
+	}
+	for id, updatedAt := range products {
+		indexedAt, ok := indexed[id]
+		switch {
+		case !ok:
+			report.Missing = append(report.Missing, id)
+		case updatedAt.After(indexedAt):
+			report.Stale = append(report.Stale, id)
+		}
+	}
+	for id := range indexed {
+		if _, ok := products[id]; !ok {
+			report.Orphaned = append(report.Orphaned, id)
+		}
+	}
+	sortIDs(report.Missing)
+	sortIDs(report.Stale)
+	sortIDs(report.Orphaned)
+
+	if repair {
+		if err := uc.repairSearchIndex(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+
+	uc.logger.WithFields(logger.Fields{
+		"missing":  len(report.Missing),
+		"stale":    len(report.Stale),
+		"orphaned": len(report.Orphaned),
+		"repaired": repair,
+		"failed":   len(report.Failed),
+	}).Info("Search index consistency checked")
+
+	return report, nil
+}
+
+// repairSearchIndex re-indexes the missing and stale products of a report and
+// removes its orphaned documents, recording the products that failed
+func (uc *productUseCase) repairSearchIndex(ctx context.Context, report *entity.SearchConsistencyReport) error {
+	ids := append(append([]uint{}, report.Missing...), report.Stale...)
+	if len(ids) > 0 {
+		products, err := uc.productRepo.FindByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		// Products deleted since the check have nothing to index
+		for i := range products {
+			if err := uc.indexer.IndexProduct(ctx, searchDocument(&products[i])); err != nil {
+				uc.logger.WithContext(ctx).WithError(err).WithField("product_id", products[i].ID).Warn("Failed to re-index product")
+				report.Failed = append(report.Failed, products[i].ID)
+			}
+		}
+	}
+
+	for _, id := range report.Orphaned {
+		if err := uc.indexer.DeleteProduct(ctx, id); err != nil {
+			uc.logger.WithContext(ctx).WithError(err).WithField("product_id", id).Warn("Failed to remove orphaned document")
+			report.Failed = append(report.Failed, id)
+		}
+	}
+
+	sortIDs(report.Failed)
+	return nil
+}
+
+// sortIDs sorts IDs in ascending order
+func sortIDs(ids []uint) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
+
+// AdjustStockBatch adds a delta to the stock of each product, all or nothing,
+// e.g. to take a shipped order's items out of stock. If any product is missing
+// or would be oversold, nothing changes and the error names that product.
+func (uc *productUseCase) AdjustStockBatch(ctx context.Context, deltas map[uint]int) ([]entity.StockChange, error) {
+	if len(deltas) == 0 {
+		return nil, newValidationError("at least one stock adjustment is required")
+	}
+
+	changes, err := uc.productRepo.AdjustStockBatch(ctx, deltas)
+	if err != nil {
+		var stockErr *storage.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			return nil, &InsufficientStockError{
+				ProductID: stockErr.ProductID,
+				Available: stockErr.Available,
+				Requested: stockErr.Requested,
+			}
+		}
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %v", ErrProductNotFound, err)
+		}
+		return nil, err
+	}
+
+	// Alert operations when stock runs low
+	if uc.stockMonitor != nil {
+		for _, change := range changes {
+			product := &entity.Product{ID: change.ProductID, Name: change.Name, StockQuantity: change.NewQuantity}
+			uc.stockMonitor.CheckStockChange(ctx, product, change.OldQuantity)
+		}
+	}
+
+	return changes, nil
+}
+
+// ArchiveProduct hides a product from the catalog and search while keeping it
+// and its history
+func (uc *productUseCase) ArchiveProduct(ctx context.Context, id uint) error {
+	return uc.setArchived(ctx, id, true)
+}
+
+// UnarchiveProduct returns an archived product to the catalog
+func (uc *productUseCase) UnarchiveProduct(ctx context.Context, id uint) error {
+	return uc.setArchived(ctx, id, false)
+}
+
+// setArchived archives or unarchives an existing product
+func (uc *productUseCase) setArchived(ctx context.Context, id uint, archived bool) error {
+	// Check if product exists
+	product, err := uc.productRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return ErrProductNotFound
+	}
+
+	return uc.productRepo.SetArchived(ctx, id, archived)
+}
+
+// BulkUpdatePrices applies a price adjustment to many products at once and records it in the
+// price audit log. A dry run only returns the changes the adjustment would make.
+func (uc *productUseCase) BulkUpdatePrices(ctx context.Context, update entity.BulkPriceUpdate) ([]entity.PriceChange, error) {
+	// Validate adjustment
+	if err := validatePriceAdjustment(update.Adjustment); err != nil {
+		return nil, err
+	}
+
+	// An update without IDs or a filter would reprice the whole catalog
+	if !update.HasTarget() {
+		return nil, newValidationError("product_ids or a non-empty filter is required")
+	}
+
+	// The repository records the user in the audit log
+	update.UserID, _ = ctxutil.UserIDFromContext(ctx)
+
+	changes, err := uc.productRepo.BulkUpdatePrice(ctx, update)
+	if errors.Is(err, storage.ErrTooManyRows) {
+		return nil, newValidationError(fmt.Sprintf("the update matches more than %d products", update.MaxProducts))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if update.DryRun {
+		return changes, nil
+	}
+
+	// The audit log holds the update; log it as well
+	uc.logger.WithFields(logger.Fields{
+		"audit":           "bulk_price_update",
+		"user_id":         update.UserID,
+		"product_ids":     update.ProductIDs,
+		"adjustment_type": update.Adjustment.Type,
+		"value":           update.Adjustment.Value,
+		"min_price":       update.Adjustment.MinPrice,
+		"updated":         len(changes),
+	}).WithContext(ctx).Info("Bulk price update applied")
+
+	// Notify subscribers of price drops
+	for _, change := range changes {
+		if uc.priceAlerts != nil && change.NewPrice < change.OldPrice {
+			product := &entity.Product{ID: change.ProductID, Name: change.Name, Price: change.NewPrice}
+			uc.priceAlerts.NotifyPriceDrop(ctx, product, change.OldPrice)
+		}
+	}
+
+	return changes, nil
+}
+
+// validatePriceAdjustment validates a price adjustment
+func validatePriceAdjustment(adj entity.PriceAdjustment) error {
+	switch adj.Type {
+	case entity.PriceAdjustmentPercentage:
+		if adj.Value <= -100 {
+			return newValidationError("percentage adjustment must be greater than -100")
+		}
+	case entity.PriceAdjustmentAbsolute:
+	default:
+		return newValidationError("adjustment type must be percentage or absolute")
+	}
+	if adj.Value == 0 {
+		return newValidationError("adjustment value must not be zero")
+	}
+	if adj.MinPrice < 0 {
+		return newValidationError("minimum price cannot be negative")
+	}
+	return nil
+}
+
+// productFieldValue returns the value of a lockable product field
+func productFieldValue(product *entity.Product, field string) (interface{}, bool) {
+	switch field {
+	case entity.ProductFieldName:
+		return product.Name, true
+	case entity.ProductFieldSKU:
+		return product.SKU, true
+	case entity.ProductFieldDescription:
+		return product.Description, true
+	case entity.ProductFieldPrice:
+		return product.Price, true
+	case entity.ProductFieldStockQuantity:
+		return product.StockQuantity, true
+	default:
+		return nil, false
+	}
 }
 
 // validateProduct validates a product
@@ -158,21 +583,138 @@ func validateProduct(product *entity.Product) error {
 	if product.StockQuantity < 0 {
 		return errors.New("product stock quantity cannot be negative")
 	}
+	if _, ok := productStatusTransitions[product.Status]; !ok {
+		return newValidationError(fmt.Sprintf("unknown product status %q", product.Status))
+	}
 	return nil
 }
 
-func (uc *productUseCase) SearchProductsByDescription(ctx context.Context, desc string) ([]entity.Product, error) {
-	results, err := uc.productSearch.SearchByDescription(ctx, desc)
+// productStatusTransitions lists the statuses each product status may change
+// to. Discontinued products can't be brought back.
+var productStatusTransitions = map[string][]string{
+	entity.ProductStatusActive:       {entity.ProductStatusInactive, entity.ProductStatusOutOfStock, entity.ProductStatusDiscontinued},
+	entity.ProductStatusInactive:     {entity.ProductStatusActive, entity.ProductStatusDiscontinued},
+	entity.ProductStatusOutOfStock:   {entity.ProductStatusActive, entity.ProductStatusInactive, entity.ProductStatusDiscontinued},
+	entity.ProductStatusDiscontinued: {},
+}
+
+// checkStatusTransition checks that a product may change from one status to
+// another. Keeping the same status is always allowed.
+func checkStatusTransition(from, to string) error {
+	if _, ok := productStatusTransitions[to]; !ok {
+		return newValidationError(fmt.Sprintf("unknown product status %q", to))
+	}
+	if from == to {
+		return nil
+	}
+	for _, allowed := range productStatusTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &StatusTransitionError{From: from, To: to}
+}
+
+// ChangeStatus moves a product to a new status if the transition table allows it
+func (uc *productUseCase) ChangeStatus(ctx context.Context, id uint, status string) (*entity.Product, error) {
+	product, err := uc.productRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	if err := checkStatusTransition(product.Status, status); err != nil {
+		return nil, err
+	}
+	if product.Status == status {
+		return product, nil
+	}
+
+	if err := uc.productRepo.SetStatus(ctx, id, status); err != nil {
+		return nil, err
+	}
+	product.Status = status
+	return product, nil
+}
+
+// SearchProducts searches products by name and description, ranking name
+// matches first, and returns one page of results along with the total number
+// of matches. It falls back to the database when the search backend is disabled.
+func (uc *productUseCase) SearchProducts(ctx context.Context, query string, page, pageSize int) ([]entity.Product, int64, error) {
+	offset := (page - 1) * pageSize
+	if uc.productSearch == nil {
+		return uc.productRepo.Search(ctx, query, offset, pageSize)
+	}
+
+	results, total, err := uc.productSearch.Search(ctx, query, elasticsearch.SearchOptions{
+		From: offset,
+		Size: pageSize,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	products, err := uc.loadSearchResults(ctx, results)
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// loadSearchResults loads the full products of search hits, keeping them in
+// relevance order. The index only holds the searchable fields.
+func (uc *productUseCase) loadSearchResults(ctx context.Context, results []elasticsearch.Product) ([]entity.Product, error) {
+	ids := make([]uint, len(results))
+	for i, p := range results {
+		ids[i] = p.ID
+	}
+	found, err := uc.productRepo.FindByIDs(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
-	var products []entity.Product
-	for _, p := range results {
-		products = append(products, entity.Product{
-			ID:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-		})
+	byID := make(map[uint]entity.Product, len(found))
+	for _, p := range found {
+		byID[p.ID] = p
+	}
+
+	products := make([]entity.Product, 0, len(results))
+	for _, id := range ids {
+		// Skip products deleted or archived since they were indexed
+		if p, ok := byID[id]; ok && !p.Archived {
+			products = append(products, p)
+		}
 	}
 	return products, nil
 }
+
+// SearchProductsByDescription searches products by description using the
+// search backend and returns one page of results, with the matched description
+// fragments, along with the total number of matches
+func (uc *productUseCase) SearchProductsByDescription(ctx context.Context, desc string, page, pageSize int) ([]entity.ProductSearchResult, int64, error) {
+	// A nil search backend means search is disabled
+	if uc.productSearch == nil {
+		return nil, 0, ErrSearchDisabled
+	}
+
+	hits, total, err := uc.productSearch.SearchByDescription(ctx, desc, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	products, err := uc.loadSearchResults(ctx, hits)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Attach the highlights of each hit to its product
+	highlights := make(map[uint][]string, len(hits))
+	for _, hit := range hits {
+		highlights[hit.ID] = hit.Highlights
+	}
+	results := make([]entity.ProductSearchResult, len(products))
+	for i, p := range products {
+		results[i] = entity.ProductSearchResult{Product: p, Highlights: highlights[p.ID]}
+	}
+	return results, total, nil
+}
@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/internal/storage/elasticsearch"
+)
+
+// archivingProductRepo records the archival changes of the products it holds
+type archivingProductRepo struct {
+	fakeProductRepo
+	archived map[uint]bool
+}
+
+func (r *archivingProductRepo) SetArchived(ctx context.Context, id uint, archived bool) error {
+	r.archived[id] = archived
+	return nil
+}
+
+// updatingProductRepo stores the products it is asked to update
+type updatingProductRepo struct {
+	fakeProductRepo
+}
+
+func (r *updatingProductRepo) Update(ctx context.Context, product *entity.Product) error {
+	updated := *product
+	r.products[product.ID] = &updated
+	return nil
+}
+
+// recordingPriceAlerts records the price drops it is told about
+type recordingPriceAlerts struct {
+	PriceAlertUseCase
+	oldPrices []float64
+}
+
+func (a *recordingPriceAlerts) NotifyPriceDrop(ctx context.Context, product *entity.Product, oldPrice float64) {
+	a.oldPrices = append(a.oldPrices, oldPrice)
+}
+
+// recordingStockMonitor records the stock changes it is told about
+type recordingStockMonitor struct {
+	oldStocks []int
+}
+
+func (m *recordingStockMonitor) CheckStockChange(ctx context.Context, product *entity.Product, oldStock int) {
+	m.oldStocks = append(m.oldStocks, oldStock)
+}
+
+// newUpdatingProductRepo returns a repository holding one active product
+func newUpdatingProductRepo() *updatingProductRepo {
+	return &updatingProductRepo{fakeProductRepo{products: map[uint]*entity.Product{
+		1: {ID: 1, Name: "Lamp", SKU: "SKU-1", Price: 50, StockQuantity: 20, Status: entity.ProductStatusActive},
+	}}}
+}
+
+func TestUpdateProduct_ReportsPriceDropAndStockChange(t *testing.T) {
+	repo := newUpdatingProductRepo()
+	alerts := &recordingPriceAlerts{}
+	monitor := &recordingStockMonitor{}
+	uc := NewProductUseCase(repo, nil, alerts, monitor, testLogger(), time.Minute, nil, nil, nil)
+
+	update := &entity.Product{ID: 1, Name: "Lamp", Price: 40, StockQuantity: 5}
+	if err := uc.UpdateProduct(context.Background(), update, nil, false); err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+	if len(alerts.oldPrices) != 1 || alerts.oldPrices[0] != 50 {
+		t.Errorf("price drops = %v, want one from 50", alerts.oldPrices)
+	}
+	if len(monitor.oldStocks) != 1 || monitor.oldStocks[0] != 20 {
+		t.Errorf("stock changes = %v, want one from 20", monitor.oldStocks)
+	}
+
+	// A price rise isn't a drop
+	update = &entity.Product{ID: 1, Name: "Lamp", Price: 60, StockQuantity: 5}
+	if err := uc.UpdateProduct(context.Background(), update, nil, false); err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+	if len(alerts.oldPrices) != 1 {
+		t.Errorf("price drops = %v after a price rise, want still one", alerts.oldPrices)
+	}
+}
+
+func TestUpdateProduct_WithoutPriceAlertsOrStockMonitor(t *testing.T) {
+	repo := newUpdatingProductRepo()
+	uc := NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, nil)
+
+	update := &entity.Product{ID: 1, Name: "Lamp", Price: 40, StockQuantity: 5}
+	if err := uc.UpdateProduct(context.Background(), update, nil, false); err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+	if got := repo.products[1]; got.Price != 40 || got.StockQuantity != 5 {
+		t.Errorf("stored product = %+v, want the update", got)
+	}
+}
+
+func TestArchiveProduct(t *testing.T) {
+	repo := &archivingProductRepo{
+		fakeProductRepo: fakeProductRepo{products: map[uint]*entity.Product{1: {ID: 1}}},
+		archived:        make(map[uint]bool),
+	}
+	uc := NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, nil)
+
+	if err := uc.ArchiveProduct(context.Background(), 1); err != nil {
+		t.Fatalf("ArchiveProduct() error = %v", err)
+	}
+	if archived, ok := repo.archived[1]; !ok || !archived {
+		t.Errorf("product 1 archived = %v, want true", archived)
+	}
+
+	if err := uc.UnarchiveProduct(context.Background(), 1); err != nil {
+		t.Fatalf("UnarchiveProduct() error = %v", err)
+	}
+	if repo.archived[1] {
+		t.Error("product 1 is still archived")
+	}
+
+	if err := uc.ArchiveProduct(context.Background(), 2); err != ErrProductNotFound {
+		t.Errorf("ArchiveProduct() of a missing product error = %v, want %v", err, ErrProductNotFound)
+	}
+	if _, ok := repo.archived[2]; ok {
+		t.Error("a missing product was archived")
+	}
+}
+
+func TestUpdateProduct_LockedSKU(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         string
+		overrideLocked bool
+		wantLocked     bool
+	}{
+		{name: "published product", status: entity.ProductStatusActive, wantLocked: true},
+		{name: "admin override", status: entity.ProductStatusActive, overrideLocked: true},
+		{name: "unpublished product", status: entity.ProductStatusInactive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newUpdatingProductRepo()
+			repo.products[1].Status = tt.status
+			uc := NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, []string{entity.ProductFieldSKU})
+
+			update := &entity.Product{ID: 1, Name: "Lamp", SKU: "SKU-2", Price: 50, StockQuantity: 20}
+			err := uc.UpdateProduct(context.Background(), update, nil, tt.overrideLocked)
+
+			var lockedErr *LockedFieldError
+			if tt.wantLocked {
+				if !errors.As(err, &lockedErr) || lockedErr.Field != entity.ProductFieldSKU {
+					t.Fatalf("UpdateProduct() error = %v, want a locked sku", err)
+				}
+				if got := repo.products[1].SKU; got != "SKU-1" {
+					t.Errorf("SKU = %s, want the unchanged SKU-1", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateProduct() error = %v", err)
+			}
+			if got := repo.products[1].SKU; got != "SKU-2" {
+				t.Errorf("SKU = %s, want SKU-2", got)
+			}
+		})
+	}
+}
+
+func TestUpdateProduct_UnlockedFieldsOfPublishedProduct(t *testing.T) {
+	repo := newUpdatingProductRepo()
+	uc := NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, []string{entity.ProductFieldSKU})
+
+	// Keeping the SKU leaves the other fields editable
+	update := &entity.Product{ID: 1, Name: "Desk lamp", Price: 45, StockQuantity: 20}
+	if err := uc.UpdateProduct(context.Background(), update, nil, false); err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+	if got := repo.products[1]; got.Name != "Desk lamp" || got.SKU != "SKU-1" {
+		t.Errorf("stored product = %+v, want the new name and the kept SKU", got)
+	}
+}
+
+// creatingProductRepo stores the products it is asked to create
+type creatingProductRepo struct {
+	fakeProductRepo
+}
+
+func (r *creatingProductRepo) Create(ctx context.Context, product *entity.Product) error {
+	product.ID = uint(len(r.products) + 1)
+	created := *product
+	r.products[product.ID] = &created
+	return nil
+}
+
+func TestCreateProduct_DefaultsToActive(t *testing.T) {
+	repo := &creatingProductRepo{fakeProductRepo{products: map[uint]*entity.Product{}}}
+	uc := NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, nil)
+
+	product := &entity.Product{Name: "Lamp", SKU: "SKU-1", Price: 50, StockQuantity: 20}
+	if err := uc.CreateProduct(context.Background(), product, nil); err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	if got := repo.products[product.ID]; got == nil || got.Status != entity.ProductStatusActive {
+		t.Errorf("stored product = %+v, want an active product", got)
+	}
+}
+
+// enabledCategoryRepo finds only the enabled categories, like the database does
+type enabledCategoryRepo struct {
+	storage.CategoryRepository
+	categories []entity.Category
+}
+
+func (r *enabledCategoryRepo) FindByIDs(ctx context.Context, ids []uint) ([]entity.Category, error) {
+	var found []entity.Category
+	for _, category := range r.categories {
+		for _, id := range ids {
+			if category.ID == id && !category.Disabled {
+				found = append(found, category)
+			}
+		}
+	}
+	return found, nil
+}
+
+func TestCreateAndUpdateProduct_RejectDisabledCategory(t *testing.T) {
+	categories := &enabledCategoryRepo{categories: []entity.Category{
+		{ID: 1, Name: "Lighting"},
+		{ID: 2, Name: "Clearance", Disabled: true},
+	}}
+	repo := newUpdatingProductRepo()
+	uc := NewProductUseCase(repo, categories, nil, nil, testLogger(), time.Minute, nil, nil, nil)
+
+	var validationErr *ValidationError
+	product := &entity.Product{Name: "Desk lamp", SKU: "SKU-2", Price: 30, StockQuantity: 5, Status: entity.ProductStatusActive}
+	if err := uc.CreateProduct(context.Background(), product, []uint{1, 2}); !errors.As(err, &validationErr) {
+		t.Fatalf("CreateProduct() error = %v, want a validation error", err)
+	}
+
+	update := &entity.Product{ID: 1, Name: "Lamp", SKU: "SKU-1", Price: 50, StockQuantity: 20}
+	if err := uc.UpdateProduct(context.Background(), update, []uint{2}, false); !errors.As(err, &validationErr) {
+		t.Fatalf("UpdateProduct() error = %v, want a validation error", err)
+	}
+	if got := repo.products[1]; len(got.Categories) != 0 {
+		t.Errorf("stored categories = %+v, want none", got.Categories)
+	}
+
+	// The enabled category alone is accepted
+	if err := uc.UpdateProduct(context.Background(), update, []uint{1}, false); err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+	if got := repo.products[1]; len(got.Categories) != 1 || got.Categories[0].ID != 1 {
+		t.Errorf("stored categories = %+v, want category 1", got.Categories)
+	}
+}
+
+// indexedProductRepo lists the update times of the products it holds
+type indexedProductRepo struct {
+	fakeProductRepo
+}
+
+func (r *indexedProductRepo) ListUpdateTimes(ctx context.Context) (map[uint]time.Time, error) {
+	times := make(map[uint]time.Time, len(r.products))
+	for id, p := range r.products {
+		times[id] = p.UpdatedAt
+	}
+	return times, nil
+}
+
+func (r *indexedProductRepo) FindByIDs(ctx context.Context, ids []uint) ([]entity.Product, error) {
+	var products []entity.Product
+	for _, id := range ids {
+		if p, ok := r.products[id]; ok {
+			products = append(products, *p)
+		}
+	}
+	return products, nil
+}
+
+// mapIndexer is a search index holding the update time of each document
+type mapIndexer struct {
+	docs    map[uint]time.Time
+	indexed []uint
+	deleted []uint
+}
+
+func (m *mapIndexer) IndexProduct(ctx context.Context, p elasticsearch.Product) error {
+	m.docs[p.ID] = p.UpdatedAt
+	m.indexed = append(m.indexed, p.ID)
+	return nil
+}
+
+func (m *mapIndexer) DeleteProduct(ctx context.Context, id uint) error {
+	delete(m.docs, id)
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func (m *mapIndexer) IndexedProducts(ctx context.Context) (map[uint]time.Time, error) {
+	docs := make(map[uint]time.Time, len(m.docs))
+	for id, updatedAt := range m.docs {
+		docs[id] = updatedAt
+	}
+	return docs, nil
+}
+
+func TestCheckSearchConsistency_ReindexesMissingProduct(t *testing.T) {
+	indexedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := &indexedProductRepo{fakeProductRepo{products: map[uint]*entity.Product{
+		1: {ID: 1, Name: "Lamp", UpdatedAt: indexedAt},
+		2: {ID: 2, Name: "Desk", UpdatedAt: indexedAt},
+		3: {ID: 3, Name: "Chair", UpdatedAt: indexedAt.Add(time.Hour)},
+	}}}
+	// Product 2 never made it to the index, 3 changed since and 9 is gone
+	indexer := &mapIndexer{docs: map[uint]time.Time{1: indexedAt, 3: indexedAt, 9: indexedAt}}
+	uc := NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, indexer, nil)
+
+	report, err := uc.CheckSearchConsistency(context.Background(), false)
+	if err != nil {
+		t.Fatalf("CheckSearchConsistency() error = %v", err)
+	}
+	if !equalIDs(report.Missing, []uint{2}) || !equalIDs(report.Stale, []uint{3}) || !equalIDs(report.Orphaned, []uint{9}) {
+		t.Errorf("report = %+v, want product 2 missing, 3 stale and 9 orphaned", report)
+	}
+	if len(indexer.indexed) != 0 || len(indexer.deleted) != 0 {
+		t.Errorf("indexed %v and deleted %v without repair", indexer.indexed, indexer.deleted)
+	}
+
+	report, err = uc.CheckSearchConsistency(context.Background(), true)
+	if err != nil {
+		t.Fatalf("CheckSearchConsistency() with repair error = %v", err)
+	}
+	if !equalIDs(indexer.indexed, []uint{2, 3}) || !equalIDs(indexer.deleted, []uint{9}) || len(report.Failed) != 0 {
+		t.Errorf("indexed %v and deleted %v (failed %v), want 2 and 3 indexed and 9 deleted", indexer.indexed, indexer.deleted, report.Failed)
+	}
+
+	// The repaired index matches the database
+	report, err = uc.CheckSearchConsistency(context.Background(), false)
+	if err != nil {
+		t.Fatalf("CheckSearchConsistency() error = %v", err)
+	}
+	if len(report.Missing)+len(report.Stale)+len(report.Orphaned) != 0 {
+		t.Errorf("report after repair = %+v, want no differences", report)
+	}
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// ReservationSweeper returns the stock held by expired reservations. It
+// collects the Prometheus metrics of its sweeps.
+type ReservationSweeper interface {
+	prometheus.Collector
+	Sweep(ctx context.Context) error
+}
+
+// reservationSweeper implements ReservationSweeper
+type reservationSweeper struct {
+	reservationRepo storage.StockReservationRepository
+	logger          *logger.Logger
+	interval        time.Duration
+	now             func() time.Time
+
+	released    prometheus.Counter
+	failed      prometheus.Counter
+	outstanding prometheus.Gauge
+}
+
+// NewReservationSweeper creates a new ReservationSweeper and starts the
+// background loop that sweeps expired reservations every interval. The loop
+// stops when ctx is cancelled.
+func NewReservationSweeper(
+	ctx context.Context,
+	reservationRepo storage.StockReservationRepository,
+	logger *logger.Logger,
+	interval time.Duration,
+) ReservationSweeper {
+	s := &reservationSweeper{
+		reservationRepo: reservationRepo,
+		logger:          logger,
+		interval:        interval,
+		now:             time.Now,
+		released: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "stock_reservations_released_total",
+			Help: "Number of expired stock reservations released.",
+		}),
+		failed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "stock_reservation_sweeps_failed_total",
+			Help: "Number of stock reservation sweeps that failed.",
+		}),
+		outstanding: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "stock_reservations_outstanding",
+			Help: "Number of stock reservations still held after the last sweep.",
+		}),
+	}
+
+	// Start the background sweeper goroutine
+	go s.startSweepLoop(ctx)
+
+	return s
+}
+
+// startSweepLoop periodically sweeps expired reservations
+func (s *reservationSweeper) startSweepLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+				s.logger.WithError(err).Error("Failed to sweep expired stock reservations")
+			}
+		}
+	}
+}
+
+// Sweep releases expired reservations and reports how many were released and
+// how many are still outstanding
+func (s *reservationSweeper) Sweep(ctx context.Context) error {
+	released, err := s.reservationRepo.ReleaseExpired(ctx, s.now())
+	if err != nil {
+		s.failed.Inc()
+		return err
+	}
+	s.released.Add(float64(released))
+
+	outstanding, err := s.reservationRepo.CountOutstanding(ctx)
+	if err != nil {
+		s.failed.Inc()
+		return err
+	}
+	s.outstanding.Set(float64(outstanding))
+
+	s.logger.WithFields(logger.Fields{
+		"reservations_released":    released,
+		"reservations_outstanding": outstanding,
+	}).Info("Swept expired stock reservations")
+
+	return nil
+}
+
+// Describe implements prometheus.Collector
+func (s *reservationSweeper) Describe(ch chan<- *prometheus.Desc) {
+	s.released.Describe(ch)
+	s.failed.Describe(ch)
+	s.outstanding.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (s *reservationSweeper) Collect(ch chan<- prometheus.Metric) {
+	s.released.Collect(ch)
+	s.failed.Collect(ch)
+	s.outstanding.Collect(ch)
+}
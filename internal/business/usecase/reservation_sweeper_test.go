@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeReservationRepo counts the sweeps it serves, releasing released
+// reservations in each unless err is set
+type fakeReservationRepo struct {
+	mu          sync.Mutex
+	sweeps      int
+	released    int
+	outstanding int64
+	err         error
+}
+
+func (r *fakeReservationRepo) ReleaseExpired(ctx context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweeps++
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.released, nil
+}
+
+func (r *fakeReservationRepo) CountOutstanding(ctx context.Context) (int64, error) {
+	return r.outstanding, nil
+}
+
+func (r *fakeReservationRepo) sweepCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sweeps
+}
+
+func TestReservationSweeper_StopsWhenContextCancelled(t *testing.T) {
+	repo := &fakeReservationRepo{}
+	ctx, cancel := context.WithCancel(context.Background())
+	NewReservationSweeper(ctx, repo, testLogger(), time.Millisecond)
+
+	waitFor(t, func() bool { return repo.sweepCount() > 0 })
+	cancel()
+
+	// Let an in-flight sweep finish, then make sure no more follow
+	time.Sleep(20 * time.Millisecond)
+	sweeps := repo.sweepCount()
+	time.Sleep(20 * time.Millisecond)
+	if got := repo.sweepCount(); got != sweeps {
+		t.Errorf("sweeper ran %d more times after cancellation", got-sweeps)
+	}
+}
+
+func TestReservationSweeper_Metrics(t *testing.T) {
+	repo := &fakeReservationRepo{released: 3, outstanding: 7}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// An hour-long interval keeps the background loop out of the way
+	sweeper := NewReservationSweeper(ctx, repo, testLogger(), time.Hour)
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(sweeper); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := sweeper.Sweep(ctx); err != nil {
+			t.Fatalf("Sweep() error = %v", err)
+		}
+	}
+	repo.err = errors.New("connection reset")
+	if err := sweeper.Sweep(ctx); err == nil {
+		t.Fatal("Sweep() error = nil, want the release failure")
+	}
+
+	want := `
+# HELP stock_reservation_sweeps_failed_total Number of stock reservation sweeps that failed.
+# TYPE stock_reservation_sweeps_failed_total counter
+stock_reservation_sweeps_failed_total 1
+# HELP stock_reservations_outstanding Number of stock reservations still held after the last sweep.
+# TYPE stock_reservations_outstanding gauge
+stock_reservations_outstanding 7
+# HELP stock_reservations_released_total Number of expired stock reservations released.
+# TYPE stock_reservations_released_total counter
+stock_reservations_released_total 6
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// ReviewUseCase defines the product review business logic
+type ReviewUseCase interface {
+	CreateReview(ctx context.Context, review *entity.Review) error
+	ListReviews(ctx context.Context, productID uint, page, pageSize int) ([]entity.Review, int64, error)
+	ListReviewedNotWishlisted(ctx context.Context, userID uint, minRating int) ([]entity.Product, error)
+	PreviewReviews(ctx context.Context, productID uint) ([]entity.Review, error)
+	GetReviewSummary(ctx context.Context, productID uint) (*entity.ReviewSummary, error)
+}
+
+// reviewUseCase implements ReviewUseCase
+type reviewUseCase struct {
+	reviewRepo  storage.ReviewRepository
+	productRepo storage.ProductRepository
+	logger      *logger.Logger
+	preview     entity.ReviewPreview
+}
+
+// NewReviewUseCase creates a new ReviewUseCase
+func NewReviewUseCase(
+	reviewRepo storage.ReviewRepository,
+	productRepo storage.ProductRepository,
+	logger *logger.Logger,
+	preview entity.ReviewPreview,
+) ReviewUseCase {
+	return &reviewUseCase{
+		reviewRepo:  reviewRepo,
+		productRepo: productRepo,
+		logger:      logger,
+		preview:     preview,
+	}
+}
+
+// CreateReview creates a review. A user may review each product only once.
+func (uc *reviewUseCase) CreateReview(ctx context.Context, review *entity.Review) error {
+	if review.Rating < 1 || review.Rating > 5 {
+		return newValidationError("rating must be between 1 and 5")
+	}
+
+	// Check if product exists
+	product, err := uc.productRepo.FindByID(ctx, review.ProductID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return ErrProductNotFound
+	}
+
+	// Reject duplicate reviews
+	existing, err := uc.reviewRepo.FindByUserAndProduct(ctx, review.UserID, review.ProductID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrReviewExists
+	}
+
+	// The unique index catches a concurrent duplicate that slipped past the check
+	if err := uc.reviewRepo.Create(ctx, review); err != nil {
+		if errors.Is(err, storage.ErrDuplicateKey) {
+			return ErrReviewExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ListReviews lists one page of a product's reviews, newest first
+func (uc *reviewUseCase) ListReviews(ctx context.Context, productID uint, page, pageSize int) ([]entity.Review, int64, error) {
+	// Check if product exists
+	product, err := uc.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if product == nil {
+		return nil, 0, ErrProductNotFound
+	}
+
+	return uc.reviewRepo.List(ctx, productID, (page-1)*pageSize, pageSize)
+}
+
+// GetReviewSummary returns a product's average rating, review count and the
+// number of reviews for each rating from 1 to 5
+func (uc *reviewUseCase) GetReviewSummary(ctx context.Context, productID uint) (*entity.ReviewSummary, error) {
+	// Check if product exists
+	product, err := uc.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	counts, err := uc.reviewRepo.RatingDistribution(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report every rating, including those nobody gave
+	summary := &entity.ReviewSummary{ProductID: productID, Distribution: make(map[int]int, 5)}
+	sum := 0
+	for rating := 1; rating <= 5; rating++ {
+		summary.Distribution[rating] = counts[rating]
+		summary.Total += counts[rating]
+		sum += rating * counts[rating]
+	}
+	if summary.Total > 0 {
+		summary.Average = math.Round(float64(sum)/float64(summary.Total)*100) / 100
+	}
+
+	return summary, nil
+}
+
+// PreviewReviews lists the reviews embedded in a product's detail, using the
+// deployment's configured sort order and minimum rating
+func (uc *reviewUseCase) PreviewReviews(ctx context.Context, productID uint) ([]entity.Review, error) {
+	return uc.reviewRepo.ListPreview(ctx, productID, uc.preview)
+}
+
+// ListReviewedNotWishlisted lists the products a user rated at least minRating
+// but hasn't added to their wishlist
+func (uc *reviewUseCase) ListReviewedNotWishlisted(ctx context.Context, userID uint, minRating int) ([]entity.Product, error) {
+	if minRating < 1 || minRating > 5 {
+		return nil, newValidationError("min_rating must be between 1 and 5")
+	}
+
+	return uc.reviewRepo.ListReviewedNotWishlisted(ctx, userID, minRating)
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+)
+
+// fakeReviewRepo serves a fixed rating distribution
+type fakeReviewRepo struct {
+	storage.ReviewRepository
+	distribution map[int]int
+}
+
+func (r *fakeReviewRepo) RatingDistribution(ctx context.Context, productID uint) (map[int]int, error) {
+	return r.distribution, nil
+}
+
+// fakeProductRepo finds the products it holds by ID
+type fakeProductRepo struct {
+	storage.ProductRepository
+	products map[uint]*entity.Product
+}
+
+func (r *fakeProductRepo) FindByID(ctx context.Context, id uint) (*entity.Product, error) {
+	return r.products[id], nil
+}
+
+func TestGetReviewSummary(t *testing.T) {
+	reviews := &fakeReviewRepo{distribution: map[int]int{5: 80, 4: 20, 1: 3}}
+	products := &fakeProductRepo{products: map[uint]*entity.Product{7: {ID: 7}}}
+	uc := NewReviewUseCase(reviews, products, testLogger(), entity.ReviewPreview{})
+
+	summary, err := uc.GetReviewSummary(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetReviewSummary() error = %v", err)
+	}
+	if summary.Total != 103 {
+		t.Errorf("Total = %d, want 103", summary.Total)
+	}
+	if summary.Average != 4.69 {
+		t.Errorf("Average = %v, want 4.69", summary.Average)
+	}
+	if want := map[int]int{1: 3, 2: 0, 3: 0, 4: 20, 5: 80}; !reflect.DeepEqual(summary.Distribution, want) {
+		t.Errorf("Distribution = %v, want %v", summary.Distribution, want)
+	}
+
+	if _, err := uc.GetReviewSummary(context.Background(), 8); err != ErrProductNotFound {
+		t.Errorf("GetReviewSummary() of a missing product error = %v, want %v", err, ErrProductNotFound)
+	}
+}
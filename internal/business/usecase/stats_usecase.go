@@ -2,85 +2,138 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"math"
 	"sync"
 	"time"
 
 	"github.com/thanhnguyen/product-api/internal/business/entity"
 	"github.com/thanhnguyen/product-api/internal/storage"
-	"github.com/thanhnguyen/product-api/internal/storage/cache"
-	transportHttp "github.com/thanhnguyen/product-api/internal/transport/http"
 	"github.com/thanhnguyen/product-api/pkg/logger"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
 // StatsUseCase defines the statistics business logic
 type StatsUseCase interface {
-	GetStats(ctx context.Context) (map[string]interface{}, error)
-	GetCategoryStats(ctx context.Context) ([]entity.CategoryStat, error)
+	GetStats(ctx context.Context) (*entity.Stats, error)
+	GetCategoryStats(ctx context.Context, rollup bool) ([]entity.CategoryStat, error)
 	GetWishlistStats(ctx context.Context) ([]entity.WishlistStat, error)
 	GetTopProducts(ctx context.Context, limit int) ([]entity.TopProduct, error)
+	GetBestSellers(ctx context.Context, limit int) ([]entity.TopProduct, error)
 	RefreshStats(ctx context.Context) error
 }
 
+// Broadcaster pushes messages to connected clients. It is implemented by the
+// transport layer's WebSocket hub.
+type Broadcaster interface {
+	Broadcast(message []byte)
+}
+
+// MaxTopProducts is the largest number of top products that can be requested
+const MaxTopProducts = 50
+
+// Keys of the statistics in the cache
+const (
+	statTotalProducts = "total_products"
+	statTotalUsers    = "total_users"
+	statTotalReviews  = "total_reviews"
+	statAverageRating = "average_rating"
+	statLastRefreshed = "last_refreshed"
+)
+
 // statsUseCase implements StatsUseCase
 type statsUseCase struct {
 	productRepo    storage.ProductRepository
 	categoryRepo   storage.CategoryRepository
+	userRepo       storage.UserRepository
 	wishlistRepo   storage.WishlistRepository
 	reviewRepo     storage.ReviewRepository
-	cache          *cache.StatsCache
+	cache          storage.StatsStore
 	logger         *logger.Logger
 	refreshTimeout time.Duration
+	concurrency    int
+	bestSellers    string
 	lastRefresh    time.Time
 	mutex          sync.RWMutex
-	wsHub          *transportHttp.WebSocketHub
+	refreshGroup   singleflight.Group
+	wsHub          Broadcaster
 }
 
-// NewStatsUseCase creates a new StatsUseCase
+// NewStatsUseCase creates a new StatsUseCase. Without a wishlist or review
+// repository the wishlist stats, top products and review totals are empty. Refreshes and
+// lookups run at most concurrency queries at once. Best-sellers are ranked by
+// the bestSellerMetric, entity.MetricReviews or entity.MetricWishlist. The
+// background refresh stops when ctx is cancelled.
 func NewStatsUseCase(
+	ctx context.Context,
 	productRepo storage.ProductRepository,
 	categoryRepo storage.CategoryRepository,
+	userRepo storage.UserRepository,
 	wishlistRepo storage.WishlistRepository,
 	reviewRepo storage.ReviewRepository,
-	cache *cache.StatsCache,
+	cache storage.StatsStore,
 	logger *logger.Logger,
 	refreshTimeout time.Duration,
-	wsHub *transportHttp.WebSocketHub,
+	wsHub Broadcaster,
+	concurrency int,
+	bestSellerMetric string,
 ) StatsUseCase {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
 	// Create the use case
 	uc := &statsUseCase{
 		productRepo:    productRepo,
 		categoryRepo:   categoryRepo,
+		userRepo:       userRepo,
 		wishlistRepo:   wishlistRepo,
 		reviewRepo:     reviewRepo,
 		cache:          cache,
 		logger:         logger,
 		refreshTimeout: refreshTimeout,
 		wsHub:          wsHub,
+		concurrency:    concurrency,
+		bestSellers:    bestSellerMetric,
+	}
+
+	if wishlistRepo == nil {
+		logger.Warn("Wishlist repository not configured, wishlist stats are disabled")
+	}
+	if reviewRepo == nil {
+		logger.Warn("Review repository not configured, top products are disabled")
 	}
 
 	// Do an initial refresh
-	go uc.RefreshStats(context.Background())
+	go uc.RefreshStats(ctx)
 
 	// Start the background refresh goroutine
-	go uc.startRefreshLoop()
+	go uc.startRefreshLoop(ctx)
 
 	return uc
 }
 
-// startRefreshLoop periodically refreshes the statistics
-func (uc *statsUseCase) startRefreshLoop() {
+// startRefreshLoop periodically refreshes the statistics until ctx is cancelled
+func (uc *statsUseCase) startRefreshLoop(ctx context.Context) {
 	ticker := time.NewTicker(uc.refreshTimeout)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		if err := uc.RefreshStats(context.Background()); err != nil {
-			uc.logger.WithError(err).Error("Failed to refresh statistics")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := uc.RefreshStats(ctx); err != nil && ctx.Err() == nil {
+				uc.logger.WithError(err).Error("Failed to refresh statistics")
+			}
 		}
 	}
 }
 
-// GetStats returns all statistics
-func (uc *statsUseCase) GetStats(ctx context.Context) (map[string]interface{}, error) {
+// GetStats returns all statistics. The totals and average rating have fields
+// of their own; any other cached statistic is returned by key in Misc.
+func (uc *statsUseCase) GetStats(ctx context.Context) (*entity.Stats, error) {
 	// Check if stats need to be refreshed
 	uc.mutex.RLock()
 	needsRefresh := time.Since(uc.lastRefresh) > uc.refreshTimeout
@@ -93,20 +146,64 @@ func (uc *statsUseCase) GetStats(ctx context.Context) (map[string]interface{}, e
 	}
 
 	// Get all stats from cache
-	return uc.cache.GetAll(), nil
-}
+	all, err := uc.cache.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-// GetCategoryStats returns product counts by category
-func (uc *statsUseCase) GetCategoryStats(ctx context.Context) ([]entity.CategoryStat, error) {
-	// Get category counts from cache
-	categoryCounts := uc.cache.GetCategoryCounts()
+	stats := &entity.Stats{Misc: make(map[string]interface{})}
+	fields := map[string]interface{}{
+		statTotalProducts: &stats.TotalProducts,
+		statTotalUsers:    &stats.TotalUsers,
+		statTotalReviews:  &stats.TotalReviews,
+		statAverageRating: &stats.AverageRating,
+	}
+	for key, dest := range fields {
+		if _, err := uc.cache.Get(ctx, key, dest); err != nil {
+			return nil, err
+		}
+	}
+	for key, value := range all {
+		if _, ok := fields[key]; !ok && key != statLastRefreshed {
+			stats.Misc[key] = value
+		}
+	}
 
-	// Check if we need to refresh
-	if len(categoryCounts) == 0 {
-		if err := uc.RefreshStats(ctx); err != nil {
+	uc.mutex.RLock()
+	stats.LastRefreshed = uc.lastRefresh
+	uc.mutex.RUnlock()
+
+	return stats, nil
+}
+
+// GetCategoryStats returns product counts by category. With rollup, each count
+// includes the distinct products of the category's sub-categories; these
+// counts aren't cached.
+func (uc *statsUseCase) GetCategoryStats(ctx context.Context, rollup bool) ([]entity.CategoryStat, error) {
+	var categoryCounts map[uint]int
+	if rollup {
+		counts, err := uc.categoryRepo.CountProductsByCategoryTree(ctx)
+		if err != nil {
+			return nil, err
+		}
+		categoryCounts = counts
+	} else {
+		// Get category counts from cache
+		counts, err := uc.cache.GetCategoryCounts(ctx)
+		if err != nil {
 			return nil, err
 		}
-		categoryCounts = uc.cache.GetCategoryCounts()
+
+		// Check if we need to refresh
+		if len(counts) == 0 {
+			if err := uc.RefreshStats(ctx); err != nil {
+				return nil, err
+			}
+			if counts, err = uc.cache.GetCategoryCounts(ctx); err != nil {
+				return nil, err
+			}
+		}
+		categoryCounts = counts
 	}
 
 	// Get all categories for names
@@ -138,38 +235,49 @@ func (uc *statsUseCase) GetCategoryStats(ctx context.Context) ([]entity.Category
 
 // GetWishlistStats returns wishlist counts by product
 func (uc *statsUseCase) GetWishlistStats(ctx context.Context) ([]entity.WishlistStat, error) {
+	// Without wishlists the counts stay empty, so refreshing wouldn't help
+	if uc.wishlistRepo == nil {
+		return []entity.WishlistStat{}, nil
+	}
+
 	// Get wishlist counts from cache
-	wishlistCounts := uc.cache.GetWishlistCounts()
+	wishlistCounts, err := uc.cache.GetWishlistCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if we need to refresh
 	if len(wishlistCounts) == 0 {
 		if err := uc.RefreshStats(ctx); err != nil {
 			return nil, err
 		}
-		wishlistCounts = uc.cache.GetWishlistCounts()
+		if wishlistCounts, err = uc.cache.GetWishlistCounts(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create the result
 	stats := make([]entity.WishlistStat, 0, len(wishlistCounts))
 
-	// Use a waitgroup to fetch product details concurrently
+	// Fetch product details concurrently, at most uc.concurrency at once
 	var (
-		wg sync.WaitGroup
+		g  errgroup.Group
 		mu sync.Mutex
 	)
+	g.SetLimit(uc.concurrency)
 
 	for id, count := range wishlistCounts {
-		wg.Add(1)
-		go func(id uint, count int) {
-			defer wg.Done()
-
+		id, count := id, count
+		g.Go(func() error {
 			// Get product details
 			product, err := uc.productRepo.FindByID(ctx, id)
 			if err != nil {
-				uc.logger.WithError(err).Error("Failed to get product details for wishlist stats")
-				return
+				// Skip the product rather than failing the whole list
+				uc.logger.WithContext(ctx).WithError(err).Error("Failed to get product details for wishlist stats")
+				return nil
 			}
 
+			// Skip products deleted since they were wishlisted
 			if product != nil {
 				stat := entity.WishlistStat{
 					ProductID:     id,
@@ -181,21 +289,38 @@ func (uc *statsUseCase) GetWishlistStats(ctx context.Context) ([]entity.Wishlist
 				stats = append(stats, stat)
 				mu.Unlock()
 			}
-		}(id, count)
+			return nil
+		})
 	}
 
-	wg.Wait()
+	g.Wait()
 
 	return stats, nil
 }
 
-// GetTopProducts returns the top products by review count
+// GetTopProducts returns the top products by review count. The cache holds the
+// top MaxTopProducts, so any smaller limit is served from it.
 func (uc *statsUseCase) GetTopProducts(ctx context.Context, limit int) ([]entity.TopProduct, error) {
-	// Check if we have cached top products
-	if value, exists := uc.cache.Get("top_products"); exists {
-		if topProducts, ok := value.([]entity.TopProduct); ok {
-			return topProducts, nil
-		}
+	return uc.cachedTopProducts(ctx, "top_products", limit)
+}
+
+// GetBestSellers returns the best-selling products, approximated by the
+// configured metric. Like the top products, they are served from the cache.
+func (uc *statsUseCase) GetBestSellers(ctx context.Context, limit int) ([]entity.TopProduct, error) {
+	return uc.cachedTopProducts(ctx, "best_sellers", limit)
+}
+
+// cachedTopProducts returns at most limit products of a cached ranking,
+// refreshing the stats if it isn't cached
+func (uc *statsUseCase) cachedTopProducts(ctx context.Context, key string, limit int) ([]entity.TopProduct, error) {
+	// Check if we have the ranking cached
+	var topProducts []entity.TopProduct
+	exists, err := uc.cache.Get(ctx, key, &topProducts)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return firstTopProducts(topProducts, limit), nil
 	}
 
 	// If not cached, refresh the stats
@@ -204,136 +329,216 @@ func (uc *statsUseCase) GetTopProducts(ctx context.Context, limit int) ([]entity
 	}
 
 	// Try again from cache
-	if value, exists := uc.cache.Get("top_products"); exists {
-		if topProducts, ok := value.([]entity.TopProduct); ok {
-			return topProducts, nil
-		}
+	exists, err = uc.cache.Get(ctx, key, &topProducts)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return firstTopProducts(topProducts, limit), nil
 	}
 
 	// If still not available, return empty slice
 	return []entity.TopProduct{}, nil
 }
 
-// RefreshStats refreshes all statistics
+// broadcastUpdate pushes the current stats snapshot to WebSocket clients
+func (uc *statsUseCase) broadcastUpdate(ctx context.Context) {
+	data, err := uc.cache.GetAll(ctx)
+	if err != nil {
+		uc.logger.WithContext(ctx).WithError(err).Error("Failed to read stats for the update event")
+		return
+	}
+
+	message, err := json.Marshal(entity.StatsUpdateEvent{
+		Event:     entity.StatsUpdateEventName,
+		Timestamp: uc.lastRefresh,
+		Data:      data,
+	})
+	if err != nil {
+		uc.logger.WithError(err).Error("Failed to marshal stats update event")
+		return
+	}
+
+	uc.wsHub.Broadcast(message)
+}
+
+// firstTopProducts returns at most limit top products
+func firstTopProducts(topProducts []entity.TopProduct, limit int) []entity.TopProduct {
+	if limit < len(topProducts) {
+		return topProducts[:limit]
+	}
+	return topProducts
+}
+
+// RefreshStats refreshes all statistics. Concurrent calls, including the
+// background refresh, share a single in-flight refresh and its result. The
+// shared refresh isn't cancelled with the caller that started it; a caller
+// whose ctx is cancelled stops waiting and returns ctx's error.
 func (uc *statsUseCase) RefreshStats(ctx context.Context) error {
+	result := uc.refreshGroup.DoChan("refresh", func() (interface{}, error) {
+		return nil, uc.refresh(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-result:
+		return res.Err
+	}
+}
+
+// refresh recomputes all statistics and stores them in the cache
+func (uc *statsUseCase) refresh(ctx context.Context) error {
 	uc.mutex.Lock()
 	defer uc.mutex.Unlock()
 
 	uc.logger.Info("Refreshing statistics")
 
-	// Use waitgroup to parallelize stat collection
+	// Collect the stats in parallel, running at most uc.concurrency queries at
+	// once. The first failure cancels the other queries and is returned.
 	var (
-		wg                sync.WaitGroup
-		productCount      int64
-		userCount         int64
-		reviewCount       int64
-		avgRating         float64
-		categoryCounts    map[uint]int
-		wishlistCounts    map[uint]int
-		topProducts       []entity.TopProduct
-		productCountErr   error
-		userCountErr      error
-		reviewCountErr    error
-		avgRatingErr      error
-		categoryCountsErr error
-		wishlistCountsErr error
-		topProductsErr    error
+		productCount   int64
+		userCount      int64
+		reviewCount    int64
+		avgRating      float64
+		categoryCounts map[uint]int
+		wishlistCounts = make(map[uint]int)
+		topProducts    = make([]entity.TopProduct, 0)
+		wishlistTop    = make([]entity.TopProduct, 0)
 	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(uc.concurrency)
 
 	// Get total product count
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		var result []entity.Product
+	g.Go(func() error {
 		var err error
-		result, productCount, err = uc.productRepo.List(ctx, entity.ProductFilter{Page: 1, PageSize: 1})
+		_, productCount, err = uc.productRepo.List(gctx, entity.ProductFilter{Page: 1, PageSize: 1})
 		if err != nil {
-			productCountErr = err
-			uc.logger.WithError(err).Error("Failed to count products")
+			uc.logger.WithContext(ctx).WithError(err).Error("Failed to count products")
 		}
-		_ = result // Avoid unused variable warning
-	}()
-
-	// Get category counts
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+		return err
+	})
 
-		// This would normally call a repository method, but for now we'll simulate
-		// with a direct SQL query
+	// Get total user count
+	g.Go(func() error {
+		var err error
+		userCount, err = uc.userRepo.Count(gctx)
+		if err != nil {
+			uc.logger.WithContext(ctx).WithError(err).Error("Failed to count users")
+		}
+		return err
+	})
 
-		// TODO: Implement repository method for category counts
-		categoryCounts = make(map[uint]int)
-		categoryCountsErr = nil
-	}()
+	// Get category counts
+	g.Go(func() error {
+		var err error
+		categoryCounts, err = uc.categoryRepo.CountProductsByCategory(gctx)
+		if err != nil {
+			uc.logger.WithContext(ctx).WithError(err).Error("Failed to count products by category")
+		}
+		return err
+	})
 
 	// Get wishlist counts
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-
-		// This would normally call a repository method, but for now we'll simulate
-		// with a direct SQL query
+	if uc.wishlistRepo != nil {
+		g.Go(func() error {
+			var err error
+			wishlistCounts, err = uc.wishlistRepo.CountByProduct(gctx)
+			if err != nil {
+				uc.logger.WithContext(ctx).WithError(err).Error("Failed to count wishlisted products")
+			}
+			return err
+		})
+	}
 
-		// TODO: Implement repository method for wishlist counts
-		wishlistCounts = make(map[uint]int)
-		wishlistCountsErr = nil
-	}()
+	// Get total review count and average rating
+	if uc.reviewRepo != nil {
+		g.Go(func() error {
+			var err error
+			reviewCount, err = uc.reviewRepo.Count(gctx)
+			if err != nil {
+				uc.logger.WithContext(ctx).WithError(err).Error("Failed to count reviews")
+			}
+			return err
+		})
+		g.Go(func() error {
+			average, err := uc.reviewRepo.AverageRating(gctx)
+			if err != nil {
+				uc.logger.WithContext(ctx).WithError(err).Error("Failed to average ratings")
+				return err
+			}
+			avgRating = math.Round(average*100) / 100
+			return nil
+		})
+	}
 
 	// Get top products
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-
-		// This would normally call a repository method, but for now we'll simulate
-
-		// TODO: Implement repository method for top products
-		topProducts = make([]entity.TopProduct, 0)
-		topProductsErr = nil
-	}()
-
-	// Wait for all goroutines to finish
-	wg.Wait()
+	if uc.reviewRepo != nil {
+		g.Go(func() error {
+			var err error
+			topProducts, err = uc.reviewRepo.TopProductsByReviews(gctx, MaxTopProducts)
+			if err != nil {
+				uc.logger.WithContext(ctx).WithError(err).Error("Failed to get top products")
+			}
+			return err
+		})
+	}
 
-	// Check for errors
-	if productCountErr != nil {
-		return productCountErr
+	// Get the most wishlisted products if they rank the best-sellers
+	if uc.bestSellers == entity.MetricWishlist && uc.wishlistRepo != nil {
+		g.Go(func() error {
+			var err error
+			wishlistTop, err = uc.wishlistRepo.TopProductsByWishlist(gctx, MaxTopProducts)
+			if err != nil {
+				uc.logger.WithContext(ctx).WithError(err).Error("Failed to get most wishlisted products")
+			}
+			return err
+		})
 	}
-	if userCountErr != nil {
-		return userCountErr
+
+	if err := g.Wait(); err != nil {
+		return err
 	}
-	if reviewCountErr != nil {
-		return reviewCountErr
+
+	// Best-sellers are approximated by the configured metric until orders exist
+	bestSellers := topProducts
+	if uc.bestSellers == entity.MetricWishlist {
+		bestSellers = wishlistTop
 	}
-	if avgRatingErr != nil {
-		return avgRatingErr
+
+	// Update the cache
+	values := map[string]interface{}{
+		statTotalProducts: productCount,
+		statTotalUsers:    userCount,
+		statTotalReviews:  reviewCount,
+		statAverageRating: avgRating,
+		"top_products":    topProducts,
+		"best_sellers":    bestSellers,
 	}
-	if categoryCountsErr != nil {
-		return categoryCountsErr
+	for key, value := range values {
+		if err := uc.cache.Set(ctx, key, value); err != nil {
+			uc.logger.WithContext(ctx).WithError(err).WithField("key", key).Error("Failed to store statistic")
+			return err
+		}
 	}
-	if wishlistCountsErr != nil {
-		return wishlistCountsErr
+	if err := uc.cache.SetCategoryCounts(ctx, categoryCounts); err != nil {
+		uc.logger.WithContext(ctx).WithError(err).Error("Failed to store category counts")
+		return err
 	}
-	if topProductsErr != nil {
-		return topProductsErr
+	if err := uc.cache.SetWishlistCounts(ctx, wishlistCounts); err != nil {
+		uc.logger.WithContext(ctx).WithError(err).Error("Failed to store wishlist counts")
+		return err
 	}
 
-	// Update the cache
-	uc.cache.Set("total_products", productCount)
-	uc.cache.Set("total_users", userCount)
-	uc.cache.Set("total_reviews", reviewCount)
-	uc.cache.Set("average_rating", avgRating)
-	uc.cache.Set("top_products", topProducts)
-	uc.cache.SetCategoryCounts(categoryCounts)
-	uc.cache.SetWishlistCounts(wishlistCounts)
-
 	// Update last refresh time
 	uc.lastRefresh = time.Now()
 
 	uc.logger.Info("Statistics refreshed")
 
 	// Broadcast stats update
-	uc.wsHub.Broadcast([]byte(`{"event":"stats_update","data":...}`))
+	if uc.wsHub != nil {
+		uc.broadcastUpdate(ctx)
+	}
 
 	return nil
 }
@@ -0,0 +1,444 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/internal/storage/cache"
+)
+
+// countingProductRepo counts the product count queries, holding each until
+// release is closed
+type countingProductRepo struct {
+	storage.ProductRepository
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingProductRepo) List(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, int64, error) {
+	r.calls.Add(1)
+	<-r.release
+	return nil, 42, nil
+}
+
+// fakeUserRepo counts a fixed number of users
+type fakeUserRepo struct {
+	storage.UserRepository
+}
+
+func (r *fakeUserRepo) Count(ctx context.Context) (int64, error) {
+	return 3, nil
+}
+
+// fakeCategoryRepo counts no products in any category
+type fakeCategoryRepo struct {
+	storage.CategoryRepository
+}
+
+func (r *fakeCategoryRepo) CountProductsByCategory(ctx context.Context) (map[uint]int, error) {
+	return map[uint]int{}, nil
+}
+
+// newRefreshTestUseCase returns a stats use case without its background refresh
+func newRefreshTestUseCase(products storage.ProductRepository) *statsUseCase {
+	return &statsUseCase{
+		productRepo:    products,
+		categoryRepo:   &fakeCategoryRepo{},
+		userRepo:       &fakeUserRepo{},
+		cache:          cache.NewStatsCache(testLogger(), time.Minute),
+		logger:         testLogger(),
+		refreshTimeout: time.Minute,
+		concurrency:    2,
+		bestSellers:    entity.MetricReviews,
+	}
+}
+
+func TestRefreshStats_CoalescesConcurrentCalls(t *testing.T) {
+	products := &countingProductRepo{release: make(chan struct{})}
+	uc := newRefreshTestUseCase(products)
+
+	const callers = 10
+	var started sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		started.Add(1)
+		go func() {
+			started.Done()
+			errs <- uc.RefreshStats(context.Background())
+		}()
+	}
+	started.Wait()
+	waitFor(t, func() bool { return products.calls.Load() == 1 })
+	// Give the other callers time to join the refresh in flight
+	time.Sleep(20 * time.Millisecond)
+	close(products.release)
+
+	for i := 0; i < callers; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("RefreshStats() error = %v", err)
+		}
+	}
+	if got := products.calls.Load(); got != 1 {
+		t.Errorf("product count queried %d times, want 1", got)
+	}
+
+	var total int64
+	if _, err := uc.cache.Get(context.Background(), statTotalProducts, &total); err != nil || total != 42 {
+		t.Errorf("cached product total = %d (err %v), want 42", total, err)
+	}
+}
+
+func TestRefreshStats_CallerCancellationDoesNotAbortRefresh(t *testing.T) {
+	products := &countingProductRepo{release: make(chan struct{})}
+	uc := newRefreshTestUseCase(products)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		cancelled <- uc.RefreshStats(ctx)
+	}()
+	waitFor(t, func() bool { return products.calls.Load() == 1 })
+
+	// A second caller joins the same refresh
+	joined := make(chan error, 1)
+	go func() {
+		joined <- uc.RefreshStats(context.Background())
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller error = %v, want %v", err, context.Canceled)
+	}
+
+	close(products.release)
+	if err := <-joined; err != nil {
+		t.Fatalf("RefreshStats() error = %v", err)
+	}
+	if got := products.calls.Load(); got != 1 {
+		t.Errorf("product count queried %d times, want 1", got)
+	}
+	var total int64
+	if _, err := uc.cache.Get(context.Background(), statTotalProducts, &total); err != nil || total != 42 {
+		t.Errorf("cached product total = %d (err %v), want 42", total, err)
+	}
+}
+
+func TestGetStats_TypedTotals(t *testing.T) {
+	products := &countingProductRepo{release: make(chan struct{})}
+	close(products.release)
+	uc := newRefreshTestUseCase(products)
+	if err := uc.cache.Set(context.Background(), "orders_today", 5); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	stats, err := uc.GetStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.TotalProducts != 42 || stats.TotalUsers != 3 {
+		t.Errorf("totals = %d products, %d users, want 42 and 3", stats.TotalProducts, stats.TotalUsers)
+	}
+	if stats.LastRefreshed.IsZero() {
+		t.Error("LastRefreshed is zero after a refresh")
+	}
+	for _, key := range []string{statTotalProducts, statTotalUsers, statTotalReviews, statAverageRating, statLastRefreshed} {
+		if _, ok := stats.Misc[key]; ok {
+			t.Errorf("Misc repeats the typed statistic %q", key)
+		}
+	}
+	if got := stats.Misc["orders_today"]; got != float64(5) {
+		t.Errorf("Misc[orders_today] = %v, want 5", got)
+	}
+	if _, ok := stats.Misc["top_products"]; !ok {
+		t.Error("Misc is missing top_products")
+	}
+}
+
+// queryTracker records how many stats queries run at once
+type queryTracker struct {
+	mu       sync.Mutex
+	inFlight int
+	max      int
+	delay    time.Duration
+}
+
+func (q *queryTracker) run(ctx context.Context) error {
+	q.mu.Lock()
+	q.inFlight++
+	if q.inFlight > q.max {
+		q.max = q.inFlight
+	}
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		q.inFlight--
+		q.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(q.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// trackedProductRepo counts products through the tracker
+type trackedProductRepo struct {
+	storage.ProductRepository
+	tracker *queryTracker
+}
+
+func (r *trackedProductRepo) List(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, int64, error) {
+	return nil, 1, r.tracker.run(ctx)
+}
+
+// trackedUserRepo counts users through the tracker
+type trackedUserRepo struct {
+	storage.UserRepository
+	tracker *queryTracker
+}
+
+func (r *trackedUserRepo) Count(ctx context.Context) (int64, error) {
+	return 1, r.tracker.run(ctx)
+}
+
+// trackedCategoryRepo counts products by category through the tracker
+type trackedCategoryRepo struct {
+	storage.CategoryRepository
+	tracker *queryTracker
+}
+
+func (r *trackedCategoryRepo) CountProductsByCategory(ctx context.Context) (map[uint]int, error) {
+	return map[uint]int{}, r.tracker.run(ctx)
+}
+
+// trackedReviewRepo runs the review stats through the tracker
+type trackedReviewRepo struct {
+	storage.ReviewRepository
+	tracker *queryTracker
+}
+
+func (r *trackedReviewRepo) Count(ctx context.Context) (int64, error) {
+	return 1, r.tracker.run(ctx)
+}
+
+func (r *trackedReviewRepo) AverageRating(ctx context.Context) (float64, error) {
+	return 4, r.tracker.run(ctx)
+}
+
+func (r *trackedReviewRepo) TopProductsByReviews(ctx context.Context, limit int) ([]entity.TopProduct, error) {
+	return nil, r.tracker.run(ctx)
+}
+
+// trackedWishlistRepo counts wishlisted products through the tracker
+type trackedWishlistRepo struct {
+	storage.WishlistRepository
+	tracker *queryTracker
+}
+
+func (r *trackedWishlistRepo) CountByProduct(ctx context.Context) (map[uint]int, error) {
+	return map[uint]int{}, r.tracker.run(ctx)
+}
+
+func TestRefreshStats_BoundsConcurrentQueries(t *testing.T) {
+	for _, concurrency := range []int{1, 3} {
+		t.Run(fmt.Sprint(concurrency), func(t *testing.T) {
+			tracker := &queryTracker{delay: 5 * time.Millisecond}
+			uc := newRefreshTestUseCase(&trackedProductRepo{tracker: tracker})
+			uc.userRepo = &trackedUserRepo{tracker: tracker}
+			uc.categoryRepo = &trackedCategoryRepo{tracker: tracker}
+			uc.reviewRepo = &trackedReviewRepo{tracker: tracker}
+			uc.wishlistRepo = &trackedWishlistRepo{tracker: tracker}
+			uc.concurrency = concurrency
+
+			if err := uc.RefreshStats(context.Background()); err != nil {
+				t.Fatalf("RefreshStats() error = %v", err)
+			}
+			if tracker.max > concurrency {
+				t.Errorf("%d queries ran at once, want at most %d", tracker.max, concurrency)
+			}
+			if concurrency > 1 && tracker.max < 2 {
+				t.Error("queries never ran in parallel")
+			}
+		})
+	}
+}
+
+// failingUserRepo fails to count users
+type failingUserRepo struct {
+	storage.UserRepository
+	err error
+}
+
+func (r *failingUserRepo) Count(ctx context.Context) (int64, error) {
+	return 0, r.err
+}
+
+func TestRefreshStats_FirstFailureCancelsOtherQueries(t *testing.T) {
+	failure := errors.New("connection reset")
+	// The other queries would take a minute unless they are cancelled
+	tracker := &queryTracker{delay: time.Minute}
+	uc := newRefreshTestUseCase(&trackedProductRepo{tracker: tracker})
+	uc.userRepo = &failingUserRepo{err: failure}
+	uc.categoryRepo = &trackedCategoryRepo{tracker: tracker}
+	uc.reviewRepo = &trackedReviewRepo{tracker: tracker}
+	uc.concurrency = 10
+
+	done := make(chan error, 1)
+	go func() {
+		done <- uc.RefreshStats(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, failure) {
+			t.Errorf("RefreshStats() error = %v, want %v", err, failure)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RefreshStats() didn't cancel the other queries")
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if tracker.inFlight != 0 {
+		t.Errorf("%d queries still running", tracker.inFlight)
+	}
+
+	// A failed refresh stores nothing
+	var total int64
+	if found, _ := uc.cache.Get(context.Background(), statTotalProducts, &total); found {
+		t.Errorf("a failed refresh cached the product total %d", total)
+	}
+}
+
+// seededCategoryRepo holds named categories and their product counts
+type seededCategoryRepo struct {
+	storage.CategoryRepository
+	categories []entity.Category
+	counts     map[uint]int
+}
+
+func (r *seededCategoryRepo) List(ctx context.Context) ([]entity.Category, error) {
+	return r.categories, nil
+}
+
+func (r *seededCategoryRepo) CountProductsByCategory(ctx context.Context) (map[uint]int, error) {
+	return r.counts, nil
+}
+
+func TestGetCategoryStats_CountsSeededProducts(t *testing.T) {
+	products := &countingProductRepo{release: make(chan struct{})}
+	close(products.release)
+	uc := newRefreshTestUseCase(products)
+	uc.categoryRepo = &seededCategoryRepo{
+		categories: []entity.Category{{ID: 1, Name: "Lighting"}, {ID: 2, Name: "Desks"}, {ID: 3, Name: "Empty"}},
+		counts:     map[uint]int{1: 3, 2: 1},
+	}
+
+	// The empty cache triggers a refresh that loads the counts
+	stats, err := uc.GetCategoryStats(context.Background(), false)
+	if err != nil {
+		t.Fatalf("GetCategoryStats() error = %v", err)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].CategoryID < stats[j].CategoryID })
+
+	want := []entity.CategoryStat{
+		{CategoryID: 1, CategoryName: "Lighting", ProductCount: 3},
+		{CategoryID: 2, CategoryName: "Desks", ProductCount: 1},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("GetCategoryStats() = %+v, want %+v", stats, want)
+	}
+}
+
+// rankedReviewRepo ranks products by a fixed review count
+type rankedReviewRepo struct {
+	storage.ReviewRepository
+	top []entity.TopProduct
+}
+
+func (r *rankedReviewRepo) Count(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *rankedReviewRepo) AverageRating(ctx context.Context) (float64, error) {
+	return 0, nil
+}
+
+func (r *rankedReviewRepo) TopProductsByReviews(ctx context.Context, limit int) ([]entity.TopProduct, error) {
+	return r.top, nil
+}
+
+// rankedWishlistRepo ranks products by a fixed wishlist count
+type rankedWishlistRepo struct {
+	storage.WishlistRepository
+	top []entity.TopProduct
+}
+
+func (r *rankedWishlistRepo) CountByProduct(ctx context.Context) (map[uint]int, error) {
+	return map[uint]int{}, nil
+}
+
+func (r *rankedWishlistRepo) TopProductsByWishlist(ctx context.Context, limit int) ([]entity.TopProduct, error) {
+	return r.top, nil
+}
+
+func TestGetBestSellers_RankedBySelectedMetric(t *testing.T) {
+	// The most reviewed product is the least wishlisted one and vice versa
+	byReviews := []entity.TopProduct{
+		{ProductID: 1, ProductName: "Lamp", Count: 40, Metric: entity.MetricReviews},
+		{ProductID: 2, ProductName: "Desk", Count: 10, Metric: entity.MetricReviews},
+	}
+	byWishlist := []entity.TopProduct{
+		{ProductID: 2, ProductName: "Desk", Count: 25, Metric: entity.MetricWishlist},
+		{ProductID: 1, ProductName: "Lamp", Count: 3, Metric: entity.MetricWishlist},
+	}
+
+	tests := []struct {
+		metric string
+		want   []entity.TopProduct
+	}{
+		{metric: entity.MetricReviews, want: byReviews},
+		{metric: entity.MetricWishlist, want: byWishlist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			products := &countingProductRepo{release: make(chan struct{})}
+			close(products.release)
+			uc := newRefreshTestUseCase(products)
+			uc.reviewRepo = &rankedReviewRepo{top: byReviews}
+			uc.wishlistRepo = &rankedWishlistRepo{top: byWishlist}
+			uc.bestSellers = tt.metric
+
+			got, err := uc.GetBestSellers(context.Background(), 10)
+			if err != nil {
+				t.Fatalf("GetBestSellers() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetBestSellers() = %+v, want %+v", got, tt.want)
+			}
+
+			// The top products stay ranked by reviews either way
+			top, err := uc.GetTopProducts(context.Background(), 10)
+			if err != nil {
+				t.Fatalf("GetTopProducts() error = %v", err)
+			}
+			if !reflect.DeepEqual(top, byReviews) {
+				t.Errorf("GetTopProducts() = %+v, want %+v", top, byReviews)
+			}
+		})
+	}
+}
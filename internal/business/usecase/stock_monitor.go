@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	"github.com/thanhnguyen/product-api/pkg/notifier"
+)
+
+// StockMonitor alerts operations when a product's stock falls below the low-stock threshold
+type StockMonitor interface {
+	CheckStockChange(ctx context.Context, product *entity.Product, oldStock int)
+}
+
+// stockMonitor implements StockMonitor
+type stockMonitor struct {
+	notifier  notifier.Notifier
+	logger    *logger.Logger
+	threshold int
+	recipient string
+}
+
+// NewStockMonitor creates a new StockMonitor
+func NewStockMonitor(notifier notifier.Notifier, logger *logger.Logger, threshold int, recipient string) StockMonitor {
+	return &stockMonitor{
+		notifier:  notifier,
+		logger:    logger,
+		threshold: threshold,
+		recipient: recipient,
+	}
+}
+
+// CheckStockChange sends a low-stock alert when a stock change crosses below
+// the threshold. Only the crossing itself alerts, so further decrements while
+// already below the threshold don't re-alert until stock is replenished.
+func (m *stockMonitor) CheckStockChange(ctx context.Context, product *entity.Product, oldStock int) {
+	if m.threshold <= 0 || oldStock < m.threshold || product.StockQuantity >= m.threshold {
+		return
+	}
+
+	m.logger.WithFields(logger.Fields{
+		"product_id": product.ID,
+		"stock":      product.StockQuantity,
+		"threshold":  m.threshold,
+	}).Warn("Product stock fell below threshold")
+
+	err := m.notifier.Notify(ctx, notifier.Notification{
+		Recipient: m.recipient,
+		Subject:   fmt.Sprintf("Low stock: %s", product.Name),
+		Body: fmt.Sprintf("Stock for %s (ID %d) fell to %d, below the threshold of %d.",
+			product.Name, product.ID, product.StockQuantity, m.threshold),
+	})
+	if err != nil {
+		m.logger.WithError(err).WithField("product_id", product.ID).Error("Failed to send low-stock alert")
+	}
+}
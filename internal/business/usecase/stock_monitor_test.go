@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+)
+
+func TestCheckStockChange_AlertsOnceWhenCrossingThreshold(t *testing.T) {
+	sender := &recordingNotifier{}
+	monitor := NewStockMonitor(sender, testLogger(), 10, "ops@example.com")
+	product := &entity.Product{ID: 7, Name: "Lamp", StockQuantity: 12}
+
+	steps := []struct {
+		name     string
+		stock    int
+		wantSent int
+	}{
+		{"above the threshold", 11, 0},
+		{"down to the threshold", 10, 0},
+		{"crossing below", 9, 1},
+		{"further below", 4, 1},
+		{"still below after a restock", 8, 1},
+		{"replenished", 20, 1},
+		{"crossing below again", 3, 2},
+	}
+	for _, step := range steps {
+		oldStock := product.StockQuantity
+		product.StockQuantity = step.stock
+		monitor.CheckStockChange(context.Background(), product, oldStock)
+		if len(sender.sent) != step.wantSent {
+			t.Fatalf("%s: sent %d alerts, want %d", step.name, len(sender.sent), step.wantSent)
+		}
+	}
+	if got := sender.sent[0].Recipient; got != "ops@example.com" {
+		t.Errorf("alert recipient = %s, want ops@example.com", got)
+	}
+}
+
+func TestCheckStockChange_DisabledWithoutThreshold(t *testing.T) {
+	sender := &recordingNotifier{}
+	monitor := NewStockMonitor(sender, testLogger(), 0, "ops@example.com")
+
+	monitor.CheckStockChange(context.Background(), &entity.Product{ID: 7, StockQuantity: 0}, 50)
+	if len(sender.sent) != 0 {
+		t.Errorf("sent %d alerts with the monitor disabled", len(sender.sent))
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// WishlistUseCase defines the wishlist business logic
+type WishlistUseCase interface {
+	AddToWishlist(ctx context.Context, userID, productID uint) error
+	RemoveFromWishlist(ctx context.Context, userID, productID uint) error
+	ListWishlist(ctx context.Context, userID uint, page, pageSize int) ([]entity.Product, int64, error)
+	CheckAvailability(ctx context.Context, userID uint) ([]entity.ProductAvailability, error)
+}
+
+// wishlistUseCase implements WishlistUseCase
+type wishlistUseCase struct {
+	wishlistRepo storage.WishlistRepository
+	productRepo  storage.ProductRepository
+	logger       *logger.Logger
+}
+
+// NewWishlistUseCase creates a new WishlistUseCase
+func NewWishlistUseCase(
+	wishlistRepo storage.WishlistRepository,
+	productRepo storage.ProductRepository,
+	logger *logger.Logger,
+) WishlistUseCase {
+	return &wishlistUseCase{
+		wishlistRepo: wishlistRepo,
+		productRepo:  productRepo,
+		logger:       logger,
+	}
+}
+
+// AddToWishlist adds a product to a user's wishlist. Adding a product that is
+// already wishlisted is a no-op.
+func (uc *wishlistUseCase) AddToWishlist(ctx context.Context, userID, productID uint) error {
+	// Check if product exists
+	product, err := uc.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return ErrProductNotFound
+	}
+
+	return uc.wishlistRepo.Add(ctx, userID, productID)
+}
+
+// RemoveFromWishlist removes a product from a user's wishlist
+func (uc *wishlistUseCase) RemoveFromWishlist(ctx context.Context, userID, productID uint) error {
+	return uc.wishlistRepo.Remove(ctx, userID, productID)
+}
+
+// ListWishlist lists one page of the products in a user's wishlist, most recently added first
+func (uc *wishlistUseCase) ListWishlist(ctx context.Context, userID uint, page, pageSize int) ([]entity.Product, int64, error) {
+	return uc.wishlistRepo.List(ctx, userID, (page-1)*pageSize, pageSize)
+}
+
+// CheckAvailability reports whether each product in a user's wishlist can
+// currently be ordered
+func (uc *wishlistUseCase) CheckAvailability(ctx context.Context, userID uint) ([]entity.ProductAvailability, error) {
+	products, err := uc.wishlistRepo.ListStock(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make([]entity.ProductAvailability, len(products))
+	for i, p := range products {
+		availability[i] = entity.ProductAvailability{
+			ProductID:     p.ID,
+			Name:          p.Name,
+			Status:        p.Status,
+			StockQuantity: p.StockQuantity,
+			Available:     p.IsAvailable(),
+		}
+	}
+
+	return availability, nil
+}
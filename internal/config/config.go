@@ -11,125 +11,487 @@ import (
 	"golang.org/x/time/rate"
 )
 
-// Config holds all configuration for the application
+// Config holds all configuration for the application. Secrets are tagged
+// `redact:"true"` so they are masked wherever the configuration is shown.
 type Config struct {
-	Environment   string
-	Server        ServerConfig
-	Database      DatabaseConfig
-	JWT           JWTConfig
-	CORS          CORSConfig
-	RateLimit     RateLimitConfig
-	Logger        LoggerConfig
-	Elasticsearch ElasticsearchConfig
+	Environment   string              `yaml:"environment"`
+	Server        ServerConfig        `yaml:"server"`
+	Database      DatabaseConfig      `yaml:"database"`
+	JWT           JWTConfig           `yaml:"jwt"`
+	Auth          AuthConfig          `yaml:"auth"`
+	CORS          CORSConfig          `yaml:"cors"`
+	Security      SecurityConfig      `yaml:"security"`
+	RateLimit     RateLimitConfig     `yaml:"rate_limit"`
+	Logger        LoggerConfig        `yaml:"logger"`
+	Elasticsearch ElasticsearchConfig `yaml:"elasticsearch"`
+	Redis         RedisConfig         `yaml:"redis"`
+	Inventory     InventoryConfig     `yaml:"inventory"`
+	Search        SearchConfig        `yaml:"search"`
+	Reviews       ReviewsConfig       `yaml:"reviews"`
+	Cache         CacheConfig         `yaml:"cache"`
+	Health        HealthConfig        `yaml:"health"`
+	WebSocket     WebSocketConfig     `yaml:"websocket"`
+	Products      ProductsConfig      `yaml:"products"`
+	Bulk          BulkConfig          `yaml:"bulk"`
+	Categories    CategoriesConfig    `yaml:"categories"`
+	Stats         StatsConfig         `yaml:"stats"`
+	Features      FeatureFlags        `yaml:"features"`
 }
 
 // ServerConfig holds server-specific configuration
 type ServerConfig struct {
-	Port         int
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
+	Port         int           `yaml:"port"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// RequestTimeout bounds how long a handler may run; zero disables it
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// AllowedHosts lists the accepted Host headers; empty accepts any host
+	AllowedHosts []string `yaml:"allowed_hosts"`
 }
 
 // DatabaseConfig holds database-specific configuration
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	Username string
-	Password string
-	Name     string
-	SSLMode  string
-	MaxConns int
-	MinConns int
-	Timeout  time.Duration
+	Host     string        `yaml:"host"`
+	Port     int           `yaml:"port"`
+	Username string        `yaml:"username"`
+	Password string        `yaml:"password" redact:"true"`
+	Name     string        `yaml:"name"`
+	SSLMode  string        `yaml:"ssl_mode"`
+	MaxConns int           `yaml:"max_conns"`
+	MinConns int           `yaml:"min_conns"`
+	Timeout  time.Duration `yaml:"timeout"`
 }
 
 // JWTConfig holds JWT-specific configuration
 type JWTConfig struct {
-	Secret        string
-	ExpiryMinutes int
+	Secret        string `yaml:"secret" redact:"true"`
+	ExpiryMinutes int    `yaml:"expiry_minutes"`
+	// CookieName is the httpOnly cookie that also carries the token; empty disables it
+	CookieName     string `yaml:"cookie_name"`
+	CookieSecure   bool   `yaml:"cookie_secure"`
+	CookieSameSite string `yaml:"cookie_same_site"`
+}
+
+// AuthConfig holds user account configuration. The bootstrap admin is only
+// created when no admin exists; it has no default password.
+type AuthConfig struct {
+	DefaultRole            string `yaml:"default_role"`
+	BootstrapAdminUsername string `yaml:"bootstrap_admin_username"`
+	BootstrapAdminEmail    string `yaml:"bootstrap_admin_email"`
+	BootstrapAdminPassword string `yaml:"bootstrap_admin_password" redact:"true"`
 }
 
 // CORSConfig holds CORS-specific configuration
 type CORSConfig struct {
-	AllowOrigins     []string
-	AllowMethods     []string
-	AllowHeaders     []string
-	ExposeHeaders    []string
-	AllowCredentials bool
-	MaxAge           int
+	AllowOrigins     []string `yaml:"allow_origins"`
+	AllowMethods     []string `yaml:"allow_methods"`
+	AllowHeaders     []string `yaml:"allow_headers"`
+	ExposeHeaders    []string `yaml:"expose_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	MaxAge           int      `yaml:"max_age"`
+}
+
+// SecurityConfig holds the security headers set on every response. An empty
+// value disables the header.
+type SecurityConfig struct {
+	StrictTransportSecurity string `yaml:"strict_transport_security"`
+	ContentTypeOptions      string `yaml:"content_type_options"`
+	FrameOptions            string `yaml:"frame_options"`
+	ContentSecurityPolicy   string `yaml:"content_security_policy"`
+	ReferrerPolicy          string `yaml:"referrer_policy"`
 }
 
 // RateLimitConfig holds rate limiting configuration
 type RateLimitConfig struct {
-	Rate                   rate.Limit
-	Burst                  int
-	CleanupIntervalMinutes int
-	ExpiryDurationMinutes  int
+	Rate                   rate.Limit `yaml:"rate"`
+	Burst                  int        `yaml:"burst"`
+	AdminRate              rate.Limit `yaml:"admin_rate"`
+	AdminBurst             int        `yaml:"admin_burst"`
+	StatsRefreshRate       rate.Limit `yaml:"stats_refresh_rate"`
+	StatsRefreshBurst      int        `yaml:"stats_refresh_burst"`
+	CleanupIntervalMinutes int        `yaml:"cleanup_interval_minutes"`
+	ExpiryDurationMinutes  int        `yaml:"expiry_duration_minutes"`
 }
 
 // LoggerConfig holds logger configuration
 type LoggerConfig struct {
-	Level      string
-	Format     string
-	OutputPath string
+	Level      string `yaml:"level"`
+	Format     string `yaml:"format"`
+	OutputPath string `yaml:"output_path"`
 }
 
 // ElasticsearchConfig holds Elasticsearch configuration
 type ElasticsearchConfig struct {
-	URL string
+	URL      string `yaml:"url"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password" redact:"true"`
+	APIKey   string `yaml:"api_key" redact:"true"`
 }
 
-// LoadConfig loads configuration from environment variables
+// RedisConfig holds Redis configuration
+type RedisConfig struct {
+	Addr     string `yaml:"addr"`
+	Password string `yaml:"password" redact:"true"`
+	DB       int    `yaml:"db"`
+	// KeyPrefix precedes the keys of the stored stats
+	KeyPrefix string `yaml:"key_prefix"`
+}
+
+// InventoryConfig holds inventory monitoring configuration
+type InventoryConfig struct {
+	LowStockThreshold        int           `yaml:"low_stock_threshold"`
+	AlertRecipient           string        `yaml:"alert_recipient"`
+	ReservationSweepInterval time.Duration `yaml:"reservation_sweep_interval"`
+}
+
+// SearchConfig holds product search configuration
+type SearchConfig struct {
+	MinQueryLength int `yaml:"min_query_length"`
+	// Analyzer is the Elasticsearch analyzer of product text, e.g. english or spanish
+	Analyzer string `yaml:"analyzer"`
+}
+
+// ProductsConfig holds product editing configuration
+type ProductsConfig struct {
+	// SKUPrefix precedes the ID in SKUs generated for products created without one
+	SKUPrefix string `yaml:"sku_prefix"`
+	// ImmutableFields can't change once a product is published, unless an admin overrides it
+	ImmutableFields []string `yaml:"immutable_fields"`
+	// NewArrivalsDays is the default window of the new arrivals listing
+	NewArrivalsDays int `yaml:"new_arrivals_days"`
+}
+
+// BulkConfig holds limits of the bulk endpoints
+type BulkConfig struct {
+	MaxBatchSize int `yaml:"max_batch_size"`
+}
+
+// CategoriesConfig holds category management configuration
+type CategoriesConfig struct {
+	// DeleteCascade removes a deleted category from its products instead of
+	// refusing to delete a category that still has products
+	DeleteCascade bool `yaml:"delete_cascade"`
+}
+
+// StatsConfig holds statistics configuration
+type StatsConfig struct {
+	// Concurrency is the most queries a stats refresh or lookup runs at once
+	Concurrency int `yaml:"concurrency"`
+	// CacheTTL is how long cached stats are served; zero keeps them until the next refresh
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+	// BestSellerMetric approximates sales by reviews or wishlist until orders exist
+	BestSellerMetric string `yaml:"best_seller_metric"`
+	// Store keeps the computed stats in memory or in Redis, which shares them
+	// between instances and keeps them across restarts
+	Store string `yaml:"store"`
+}
+
+// ReviewsConfig holds configuration for the reviews embedded in product details
+type ReviewsConfig struct {
+	PreviewSort      string `yaml:"preview_sort"`
+	PreviewMinRating int    `yaml:"preview_min_rating"`
+	PreviewLimit     int    `yaml:"preview_limit"`
+}
+
+// CacheConfig holds the Cache-Control max-age of cacheable responses and
+// whether shared caches such as CDNs may store them
+type CacheConfig struct {
+	ProductMaxAge  time.Duration `yaml:"product_max_age"`
+	ProductPublic  bool          `yaml:"product_public"`
+	CategoryMaxAge time.Duration `yaml:"category_max_age"`
+	CategoryPublic bool          `yaml:"category_public"`
+	// ProductCountTTL is how long the product count of a list filter is reused
+	ProductCountTTL time.Duration `yaml:"product_count_ttl"`
+}
+
+// HealthConfig holds health check configuration
+type HealthConfig struct {
+	DegradedThreshold time.Duration `yaml:"degraded_threshold"`
+	// CheckTimeout bounds how long a single dependency ping may take
+	CheckTimeout time.Duration `yaml:"check_timeout"`
+}
+
+// WebSocketConfig holds WebSocket configuration
+type WebSocketConfig struct {
+	PingInterval time.Duration `yaml:"ping_interval"`
+}
+
+// FeatureFlags switch optional features on and off while keeping their settings
+type FeatureFlags struct {
+	// Search indexes products into Elasticsearch and searches them there
+	Search bool `yaml:"search"`
+	// WebSocket serves the stats update stream
+	WebSocket bool `yaml:"websocket"`
+	// Caching lets clients cache responses and reuses product list counts
+	Caching bool `yaml:"caching"`
+	// Maintenance answers every request but the health checks with 503
+	Maintenance bool `yaml:"maintenance"`
+}
+
+// ActiveCache returns the cache settings in effect, which are all zero, so
+// nothing is cached, when the caching feature is disabled
+func (c *Config) ActiveCache() CacheConfig {
+	if !c.Features.Caching {
+		return CacheConfig{}
+	}
+	return c.Cache
+}
+
+// LoadConfig loads configuration from the file named by the CONFIG_FILE
+// environment variable, if any, and from environment variables
 func LoadConfig() (*Config, error) {
-	// Load .env file if it exists
-	godotenv.Load()
+	return LoadConfigFile("")
+}
+
+// LoadConfigFile loads configuration from a YAML or JSON file and from
+// environment variables. Process environment variables override the file,
+// which overrides the built-in defaults. An empty path falls back to the
+// CONFIG_FILE environment variable; without either, a .env file takes the
+// file's place and is read into the environment if it exists. The .env file
+// is skipped when a config file is given so it can't override the file.
+func LoadConfigFile(path string) (*Config, error) {
+	config := defaultConfig()
 
-	config := &Config{
-		Environment: getEnv("ENVIRONMENT", "development"),
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(path, config); err != nil {
+			return nil, err
+		}
+	} else {
+		// Load .env file if it exists
+		godotenv.Load()
+	}
+
+	applyEnv(config)
+	normalize(config)
+
+	return config, nil
+}
+
+// defaultConfig returns the built-in defaults
+func defaultConfig() *Config {
+	return &Config{
+		Environment: "development",
 		Server: ServerConfig{
-			Port:         getEnvAsInt("SERVER_PORT", 8080),
-			ReadTimeout:  time.Duration(getEnvAsInt("SERVER_READ_TIMEOUT", 10)) * time.Second,
-			WriteTimeout: time.Duration(getEnvAsInt("SERVER_WRITE_TIMEOUT", 10)) * time.Second,
-			IdleTimeout:  time.Duration(getEnvAsInt("SERVER_IDLE_TIMEOUT", 60)) * time.Second,
+			Port:           8080,
+			ReadTimeout:    10 * time.Second,
+			WriteTimeout:   10 * time.Second,
+			IdleTimeout:    60 * time.Second,
+			RequestTimeout: 8 * time.Second,
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvAsInt("DB_PORT", 5432),
-			Username: getEnv("DB_USERNAME", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			Name:     getEnv("DB_NAME", "product_api"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
-			MaxConns: getEnvAsInt("DB_MAX_CONNS", 10),
-			MinConns: getEnvAsInt("DB_MIN_CONNS", 2),
-			Timeout:  time.Duration(getEnvAsInt("DB_TIMEOUT", 5)) * time.Second,
+			Host:     "localhost",
+			Port:     5432,
+			Username: "postgres",
+			Password: "postgres",
+			Name:     "product_api",
+			SSLMode:  "disable",
+			MaxConns: 10,
+			MinConns: 2,
+			Timeout:  5 * time.Second,
 		},
 		JWT: JWTConfig{
-			Secret:        getEnv("JWT_SECRET", "your-secret-key"),
-			ExpiryMinutes: getEnvAsInt("JWT_EXPIRY_MINUTES", 60),
+			Secret:         defaultJWTSecret,
+			ExpiryMinutes:  60,
+			CookieSecure:   true,
+			CookieSameSite: "lax",
+		},
+		Auth: AuthConfig{
+			DefaultRole:            "user",
+			BootstrapAdminUsername: "admin",
+			BootstrapAdminEmail:    "admin@example.com",
 		},
 		CORS: CORSConfig{
-			AllowOrigins:     getEnvAsSlice("CORS_ALLOW_ORIGINS", []string{"*"}),
-			AllowMethods:     getEnvAsSlice("CORS_ALLOW_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-			AllowHeaders:     getEnvAsSlice("CORS_ALLOW_HEADERS", []string{"Origin", "Content-Type", "Accept", "Authorization"}),
-			ExposeHeaders:    getEnvAsSlice("CORS_EXPOSE_HEADERS", []string{}),
-			AllowCredentials: getEnvAsBool("CORS_ALLOW_CREDENTIALS", false),
-			MaxAge:           getEnvAsInt("CORS_MAX_AGE", 300),
+			AllowOrigins:  []string{"*"},
+			AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowHeaders:  []string{"Origin", "Content-Type", "Accept", "Authorization", "X-CSRF-Token"},
+			ExposeHeaders: []string{"X-Request-ID"},
+			MaxAge:        300,
+		},
+		Security: SecurityConfig{
+			StrictTransportSecurity: "max-age=31536000; includeSubDomains",
+			ContentTypeOptions:      "nosniff",
+			FrameOptions:            "DENY",
+			ContentSecurityPolicy:   "default-src 'none'; frame-ancestors 'none'",
+			ReferrerPolicy:          "no-referrer",
 		},
 		RateLimit: RateLimitConfig{
-			Rate:                   rate.Limit(getEnvAsFloat("RATE_LIMIT_RATE", 10)),
-			Burst:                  getEnvAsInt("RATE_LIMIT_BURST", 20),
-			CleanupIntervalMinutes: getEnvAsInt("RATE_LIMIT_CLEANUP_INTERVAL", 5),
-			ExpiryDurationMinutes:  getEnvAsInt("RATE_LIMIT_EXPIRY_DURATION", 60),
+			Rate:                   10,
+			Burst:                  20,
+			AdminRate:              50,
+			AdminBurst:             100,
+			StatsRefreshRate:       0.1,
+			StatsRefreshBurst:      2,
+			CleanupIntervalMinutes: 5,
+			ExpiryDurationMinutes:  60,
 		},
 		Logger: LoggerConfig{
-			Level:      getEnv("LOGGER_LEVEL", "info"),
-			Format:     getEnv("LOGGER_FORMAT", "json"),
-			OutputPath: getEnv("LOGGER_OUTPUT_PATH", "stdout"),
+			Level:      "info",
+			Format:     "json",
+			OutputPath: "stdout",
+		},
+		Redis: RedisConfig{
+			Addr:      "localhost:6379",
+			KeyPrefix: "product-api:stats:",
+		},
+		Elasticsearch: ElasticsearchConfig{
+			URL: "http://localhost:9200",
+		},
+		Inventory: InventoryConfig{
+			LowStockThreshold:        10,
+			AlertRecipient:           "ops@example.com",
+			ReservationSweepInterval: 60 * time.Second,
+		},
+		Search: SearchConfig{
+			MinQueryLength: 2,
+			Analyzer:       "standard",
+		},
+		Products: ProductsConfig{
+			SKUPrefix:       "SKU-",
+			NewArrivalsDays: 7,
+		},
+		Bulk: BulkConfig{
+			MaxBatchSize: 500,
+		},
+		Stats: StatsConfig{
+			Concurrency:      4,
+			CacheTTL:         30 * time.Minute,
+			BestSellerMetric: "reviews",
+			Store:            "memory",
+		},
+		Reviews: ReviewsConfig{
+			PreviewSort:      "newest",
+			PreviewMinRating: 1,
+			PreviewLimit:     3,
+		},
+		Cache: CacheConfig{
+			ProductMaxAge:   60 * time.Second,
+			ProductPublic:   true,
+			CategoryMaxAge:  300 * time.Second,
+			CategoryPublic:  true,
+			ProductCountTTL: 10 * time.Second,
+		},
+		Health: HealthConfig{
+			DegradedThreshold: 500 * time.Millisecond,
+			CheckTimeout:      2000 * time.Millisecond,
+		},
+		WebSocket: WebSocketConfig{
+			PingInterval: 30 * time.Second,
+		},
+		Features: FeatureFlags{
+			Search:    true,
+			WebSocket: true,
+			Caching:   true,
 		},
 	}
 
-	return config, nil
+}
+
+// applyEnv overrides the configuration with the environment variables that are set
+func applyEnv(c *Config) {
+	c.Environment = getEnv("ENVIRONMENT", c.Environment)
+	c.Server.Port = getEnvAsInt("SERVER_PORT", c.Server.Port)
+	c.Server.ReadTimeout = getEnvAsDuration("SERVER_READ_TIMEOUT", time.Second, c.Server.ReadTimeout)
+	c.Server.WriteTimeout = getEnvAsDuration("SERVER_WRITE_TIMEOUT", time.Second, c.Server.WriteTimeout)
+	c.Server.IdleTimeout = getEnvAsDuration("SERVER_IDLE_TIMEOUT", time.Second, c.Server.IdleTimeout)
+	c.Server.RequestTimeout = getEnvAsDuration("SERVER_REQUEST_TIMEOUT", time.Second, c.Server.RequestTimeout)
+	c.Server.AllowedHosts = getEnvAsSlice("SERVER_ALLOWED_HOSTS", c.Server.AllowedHosts)
+	c.Database.Host = getEnv("DB_HOST", c.Database.Host)
+	c.Database.Port = getEnvAsInt("DB_PORT", c.Database.Port)
+	c.Database.Username = getEnv("DB_USERNAME", c.Database.Username)
+	c.Database.Password = getEnv("DB_PASSWORD", c.Database.Password)
+	c.Database.Name = getEnv("DB_NAME", c.Database.Name)
+	c.Database.SSLMode = getEnv("DB_SSL_MODE", c.Database.SSLMode)
+	c.Database.MaxConns = getEnvAsInt("DB_MAX_CONNS", c.Database.MaxConns)
+	c.Database.MinConns = getEnvAsInt("DB_MIN_CONNS", c.Database.MinConns)
+	c.Database.Timeout = getEnvAsDuration("DB_TIMEOUT", time.Second, c.Database.Timeout)
+	c.JWT.Secret = getEnv("JWT_SECRET", c.JWT.Secret)
+	c.JWT.ExpiryMinutes = getEnvAsInt("JWT_EXPIRY_MINUTES", c.JWT.ExpiryMinutes)
+	c.JWT.CookieName = getEnv("JWT_COOKIE_NAME", c.JWT.CookieName)
+	c.JWT.CookieSecure = getEnvAsBool("JWT_COOKIE_SECURE", c.JWT.CookieSecure)
+	c.JWT.CookieSameSite = getEnv("JWT_COOKIE_SAME_SITE", c.JWT.CookieSameSite)
+	c.Auth.DefaultRole = getEnv("AUTH_DEFAULT_ROLE", c.Auth.DefaultRole)
+	c.Auth.BootstrapAdminUsername = getEnv("BOOTSTRAP_ADMIN_USERNAME", c.Auth.BootstrapAdminUsername)
+	c.Auth.BootstrapAdminEmail = getEnv("BOOTSTRAP_ADMIN_EMAIL", c.Auth.BootstrapAdminEmail)
+	c.Auth.BootstrapAdminPassword = getEnv("BOOTSTRAP_ADMIN_PASSWORD", c.Auth.BootstrapAdminPassword)
+	c.CORS.AllowOrigins = getEnvAsSlice("CORS_ALLOW_ORIGINS", c.CORS.AllowOrigins)
+	c.CORS.AllowMethods = getEnvAsSlice("CORS_ALLOW_METHODS", c.CORS.AllowMethods)
+	c.CORS.AllowHeaders = getEnvAsSlice("CORS_ALLOW_HEADERS", c.CORS.AllowHeaders)
+	c.CORS.ExposeHeaders = getEnvAsSlice("CORS_EXPOSE_HEADERS", c.CORS.ExposeHeaders)
+	c.CORS.AllowCredentials = getEnvAsBool("CORS_ALLOW_CREDENTIALS", c.CORS.AllowCredentials)
+	c.CORS.MaxAge = getEnvAsInt("CORS_MAX_AGE", c.CORS.MaxAge)
+	c.Security.StrictTransportSecurity = getEnv("SECURITY_HSTS", c.Security.StrictTransportSecurity)
+	c.Security.ContentTypeOptions = getEnv("SECURITY_CONTENT_TYPE_OPTIONS", c.Security.ContentTypeOptions)
+	c.Security.FrameOptions = getEnv("SECURITY_FRAME_OPTIONS", c.Security.FrameOptions)
+	c.Security.ContentSecurityPolicy = getEnv("SECURITY_CONTENT_SECURITY_POLICY", c.Security.ContentSecurityPolicy)
+	c.Security.ReferrerPolicy = getEnv("SECURITY_REFERRER_POLICY", c.Security.ReferrerPolicy)
+	c.RateLimit.Rate = rate.Limit(getEnvAsFloat("RATE_LIMIT_RATE", float64(c.RateLimit.Rate)))
+	c.RateLimit.Burst = getEnvAsInt("RATE_LIMIT_BURST", c.RateLimit.Burst)
+	c.RateLimit.AdminRate = rate.Limit(getEnvAsFloat("RATE_LIMIT_ADMIN_RATE", float64(c.RateLimit.AdminRate)))
+	c.RateLimit.AdminBurst = getEnvAsInt("RATE_LIMIT_ADMIN_BURST", c.RateLimit.AdminBurst)
+	c.RateLimit.StatsRefreshRate = rate.Limit(getEnvAsFloat("RATE_LIMIT_STATS_REFRESH_RATE", float64(c.RateLimit.StatsRefreshRate)))
+	c.RateLimit.StatsRefreshBurst = getEnvAsInt("RATE_LIMIT_STATS_REFRESH_BURST", c.RateLimit.StatsRefreshBurst)
+	c.RateLimit.CleanupIntervalMinutes = getEnvAsInt("RATE_LIMIT_CLEANUP_INTERVAL", c.RateLimit.CleanupIntervalMinutes)
+	c.RateLimit.ExpiryDurationMinutes = getEnvAsInt("RATE_LIMIT_EXPIRY_DURATION", c.RateLimit.ExpiryDurationMinutes)
+	c.Logger.Level = getEnv("LOGGER_LEVEL", c.Logger.Level)
+	c.Logger.Format = getEnv("LOGGER_FORMAT", c.Logger.Format)
+	c.Logger.OutputPath = getEnv("LOGGER_OUTPUT_PATH", c.Logger.OutputPath)
+	c.Elasticsearch.URL = getEnv("ELASTICSEARCH_URL", c.Elasticsearch.URL)
+	c.Elasticsearch.Username = getEnv("ELASTICSEARCH_USERNAME", c.Elasticsearch.Username)
+	c.Elasticsearch.Password = getEnv("ELASTICSEARCH_PASSWORD", c.Elasticsearch.Password)
+	c.Elasticsearch.APIKey = getEnv("ELASTICSEARCH_API_KEY", c.Elasticsearch.APIKey)
+	c.Redis.Addr = getEnv("REDIS_ADDR", c.Redis.Addr)
+	c.Redis.Password = getEnv("REDIS_PASSWORD", c.Redis.Password)
+	c.Redis.DB = getEnvAsInt("REDIS_DB", c.Redis.DB)
+	c.Redis.KeyPrefix = getEnv("REDIS_KEY_PREFIX", c.Redis.KeyPrefix)
+	c.Inventory.LowStockThreshold = getEnvAsInt("INVENTORY_LOW_STOCK_THRESHOLD", c.Inventory.LowStockThreshold)
+	c.Inventory.AlertRecipient = getEnv("INVENTORY_ALERT_RECIPIENT", c.Inventory.AlertRecipient)
+	c.Inventory.ReservationSweepInterval = getEnvAsDuration("INVENTORY_RESERVATION_SWEEP_INTERVAL", time.Second, c.Inventory.ReservationSweepInterval)
+	c.Search.MinQueryLength = getEnvAsInt("SEARCH_MIN_QUERY_LENGTH", c.Search.MinQueryLength)
+	c.Search.Analyzer = getEnv("SEARCH_ANALYZER", c.Search.Analyzer)
+	c.Products.SKUPrefix = getEnv("PRODUCT_SKU_PREFIX", c.Products.SKUPrefix)
+	c.Products.ImmutableFields = getEnvAsSlice("PRODUCT_IMMUTABLE_FIELDS", c.Products.ImmutableFields)
+	c.Products.NewArrivalsDays = getEnvAsInt("PRODUCT_NEW_ARRIVALS_DAYS", c.Products.NewArrivalsDays)
+	c.Bulk.MaxBatchSize = getEnvAsInt("BULK_MAX_BATCH_SIZE", c.Bulk.MaxBatchSize)
+	c.Categories.DeleteCascade = getEnvAsBool("CATEGORY_DELETE_CASCADE", c.Categories.DeleteCascade)
+	c.Stats.Concurrency = getEnvAsInt("STATS_CONCURRENCY", c.Stats.Concurrency)
+	c.Stats.CacheTTL = getEnvAsDuration("STATS_CACHE_TTL", time.Second, c.Stats.CacheTTL)
+	c.Stats.BestSellerMetric = getEnv("STATS_BEST_SELLER_METRIC", c.Stats.BestSellerMetric)
+	c.Stats.Store = getEnv("STATS_STORE", c.Stats.Store)
+	c.Reviews.PreviewSort = getEnv("REVIEWS_PREVIEW_SORT", c.Reviews.PreviewSort)
+	c.Reviews.PreviewMinRating = getEnvAsInt("REVIEWS_PREVIEW_MIN_RATING", c.Reviews.PreviewMinRating)
+	c.Reviews.PreviewLimit = getEnvAsInt("REVIEWS_PREVIEW_LIMIT", c.Reviews.PreviewLimit)
+	c.Cache.ProductMaxAge = getEnvAsDuration("CACHE_PRODUCT_MAX_AGE", time.Second, c.Cache.ProductMaxAge)
+	c.Cache.ProductPublic = getEnvAsBool("CACHE_PRODUCT_PUBLIC", c.Cache.ProductPublic)
+	c.Cache.CategoryMaxAge = getEnvAsDuration("CACHE_CATEGORY_MAX_AGE", time.Second, c.Cache.CategoryMaxAge)
+	c.Cache.CategoryPublic = getEnvAsBool("CACHE_CATEGORY_PUBLIC", c.Cache.CategoryPublic)
+	c.Cache.ProductCountTTL = getEnvAsDuration("CACHE_PRODUCT_COUNT_TTL", time.Second, c.Cache.ProductCountTTL)
+	c.Health.DegradedThreshold = getEnvAsDuration("HEALTH_DEGRADED_THRESHOLD_MS", time.Millisecond, c.Health.DegradedThreshold)
+	c.Health.CheckTimeout = getEnvAsDuration("HEALTH_CHECK_TIMEOUT_MS", time.Millisecond, c.Health.CheckTimeout)
+	c.WebSocket.PingInterval = getEnvAsDuration("WS_PING_INTERVAL", time.Second, c.WebSocket.PingInterval)
+	c.Features.Search = getEnvAsBool("FEATURE_SEARCH", c.Features.Search)
+	c.Features.WebSocket = getEnvAsBool("FEATURE_WEBSOCKET", c.Features.WebSocket)
+	c.Features.Caching = getEnvAsBool("FEATURE_CACHING", c.Features.Caching)
+	c.Features.Maintenance = getEnvAsBool("FEATURE_MAINTENANCE", c.Features.Maintenance)
+}
+
+// normalize lowercases the settings chosen from a fixed set of values, which
+// are compared exactly once loaded
+func normalize(c *Config) {
+	for _, value := range []*string{
+		&c.Logger.Level,
+		&c.Logger.Format,
+		&c.JWT.CookieSameSite,
+		&c.Search.Analyzer,
+		&c.Reviews.PreviewSort,
+		&c.Stats.BestSellerMetric,
+		&c.Stats.Store,
+	} {
+		*value = strings.ToLower(strings.TrimSpace(*value))
+	}
 }
 
 // GetDatabaseURL returns the database connection URL
@@ -171,6 +533,15 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvAsDuration reads a whole number of units, e.g. seconds
+func getEnvAsDuration(key string, unit time.Duration, defaultValue time.Duration) time.Duration {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.Atoi(valueStr); err == nil {
+		return time.Duration(value) * unit
+	}
+	return defaultValue
+}
+
 func getEnvAsSlice(key string, defaultValue []string) []string {
 	valueStr := getEnv(key, "")
 	if valueStr == "" {
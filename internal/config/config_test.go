@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// chdir changes the working directory to dir for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// writeFile writes content to name in dir and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFile_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+	writeFile(t, dir, ".env", "SERVER_PORT=7000\n")
+	path := writeFile(t, dir, "config.yaml", "server:\n  port: 9000\n")
+	t.Setenv("CONFIG_FILE", "")
+
+	t.Run("file overrides defaults and .env is skipped", func(t *testing.T) {
+		t.Setenv("SERVER_PORT", "")
+		os.Unsetenv("SERVER_PORT")
+
+		cfg, err := LoadConfigFile(path)
+		if err != nil {
+			t.Fatalf("LoadConfigFile() error = %v", err)
+		}
+		if cfg.Server.Port != 9000 {
+			t.Errorf("Server.Port = %d, want the file's 9000", cfg.Server.Port)
+		}
+	})
+
+	t.Run("process environment overrides the file", func(t *testing.T) {
+		t.Setenv("SERVER_PORT", "7100")
+
+		cfg, err := LoadConfigFile(path)
+		if err != nil {
+			t.Fatalf("LoadConfigFile() error = %v", err)
+		}
+		if cfg.Server.Port != 7100 {
+			t.Errorf("Server.Port = %d, want the environment's 7100", cfg.Server.Port)
+		}
+	})
+
+	t.Run(".env is read without a file", func(t *testing.T) {
+		// Restored, and so unset again, when the subtest ends
+		t.Setenv("SERVER_PORT", "")
+		os.Unsetenv("SERVER_PORT")
+
+		cfg, err := LoadConfigFile("")
+		if err != nil {
+			t.Fatalf("LoadConfigFile() error = %v", err)
+		}
+		if cfg.Server.Port != 7000 {
+			t.Errorf("Server.Port = %d, want the .env file's 7000", cfg.Server.Port)
+		}
+	})
+}
+
+func TestLoadConfigFile_RejectsUnknownKeys(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "config.yaml", "server:\n  prot: 9000\n")
+
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Error("LoadConfigFile() accepted a misspelled key")
+	}
+}
+
+func TestLoadConfigFile_NormalizesEnumSettings(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("STATS_BEST_SELLER_METRIC", "Wishlist")
+	t.Setenv("STATS_STORE", " Memory ")
+	t.Setenv("JWT_COOKIE_SAME_SITE", "Strict")
+
+	cfg, err := LoadConfigFile(writeFile(t, t.TempDir(), "config.yaml", "logger:\n  level: DEBUG\n"))
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	if cfg.Stats.BestSellerMetric != "wishlist" {
+		t.Errorf("Stats.BestSellerMetric = %q, want %q", cfg.Stats.BestSellerMetric, "wishlist")
+	}
+	if cfg.Stats.Store != "memory" {
+		t.Errorf("Stats.Store = %q, want %q", cfg.Stats.Store, "memory")
+	}
+	if cfg.JWT.CookieSameSite != "strict" {
+		t.Errorf("JWT.CookieSameSite = %q, want %q", cfg.JWT.CookieSameSite, "strict")
+	}
+	if cfg.Logger.Level != "debug" {
+		t.Errorf("Logger.Level = %q, want %q", cfg.Logger.Level, "debug")
+	}
+}
+
+func TestLoadConfigFile_FeatureFlags(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	path := writeFile(t, t.TempDir(), "config.yaml", "features:\n  search: false\n")
+
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	want := FeatureFlags{Search: false, WebSocket: true, Caching: true, Maintenance: false}
+	if cfg.Features != want {
+		t.Errorf("Features = %+v, want %+v", cfg.Features, want)
+	}
+	if cfg.ActiveCache() != cfg.Cache {
+		t.Errorf("ActiveCache() = %+v, want the configured %+v", cfg.ActiveCache(), cfg.Cache)
+	}
+
+	t.Setenv("FEATURE_CACHING", "false")
+	t.Setenv("FEATURE_MAINTENANCE", "true")
+	cfg, err = LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	if !cfg.Features.Maintenance || cfg.Features.Caching {
+		t.Errorf("Features = %+v, want maintenance on and caching off", cfg.Features)
+	}
+	if cfg.ActiveCache() != (CacheConfig{}) {
+		t.Errorf("ActiveCache() = %+v, want nothing cached with caching disabled", cfg.ActiveCache())
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadFile overrides the configuration with the settings of a YAML or JSON
+// file. JSON is parsed as YAML, of which it is a subset, so both use the yaml
+// struct tags. Durations are written like "10s" or "500ms". Unknown keys are
+// rejected so a misspelled setting doesn't silently fall back to its default.
+func loadFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Redacted returns the effective configuration as flat, dotted keys suitable
+// for structured logging. The keys follow the config file's yaml keys, e.g.
+// server.port. Fields tagged `redact:"true"` are secrets and are masked so
+// only their length is revealed.
+func (c *Config) Redacted() map[string]interface{} {
+	values := make(map[string]interface{})
+	flatten("", reflect.ValueOf(*c), values)
+	return values
+}
+
+// flatten adds the fields of the struct v to values under prefix, descending
+// into nested sections
+func flatten(prefix string, v reflect.Value, values map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		value := v.Field(i)
+		switch {
+		case field.Tag.Get("redact") == "true":
+			values[key] = maskSecret(value.String())
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			values[key] = time.Duration(value.Int()).String()
+		case field.Type == reflect.TypeOf(rate.Limit(0)):
+			values[key] = value.Float()
+		case value.Kind() == reflect.Struct:
+			flatten(key, value, values)
+		default:
+			values[key] = value.Interface()
+		}
+	}
+}
+
+// maskSecret hides a secret, revealing only its length
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return fmt.Sprintf("[REDACTED len=%d]", len(secret))
+}
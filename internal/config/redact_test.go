@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countSettings counts the leaf settings of a config section
+func countSettings(t reflect.Type) int {
+	n := 0
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+			n += countSettings(field.Type)
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+func TestRedacted_MasksSecrets(t *testing.T) {
+	c := defaultConfig()
+	c.Database.Password = "db-password"
+	c.JWT.Secret = "jwt-secret-value"
+	c.Auth.BootstrapAdminPassword = "bootstrap-password"
+	c.Elasticsearch.Password = "es-password"
+	c.Elasticsearch.APIKey = "es-api-key"
+	c.Redis.Password = "redis-password"
+
+	redacted := c.Redacted()
+
+	secrets := map[string]string{
+		"database.password":             c.Database.Password,
+		"jwt.secret":                    c.JWT.Secret,
+		"auth.bootstrap_admin_password": c.Auth.BootstrapAdminPassword,
+		"elasticsearch.password":        c.Elasticsearch.Password,
+		"elasticsearch.api_key":         c.Elasticsearch.APIKey,
+		"redis.password":                c.Redis.Password,
+	}
+	for key, secret := range secrets {
+		want := fmt.Sprintf("[REDACTED len=%d]", len(secret))
+		if got := redacted[key]; got != want {
+			t.Errorf("%s = %v, want %q", key, got, want)
+		}
+	}
+
+	// No secret leaks under any key
+	for key, value := range redacted {
+		for _, secret := range secrets {
+			if strings.Contains(fmt.Sprint(value), secret) {
+				t.Errorf("%s reveals a secret: %v", key, value)
+			}
+		}
+	}
+}
+
+func TestRedacted_ListsEverySetting(t *testing.T) {
+	c := defaultConfig()
+	redacted := c.Redacted()
+
+	if want := countSettings(reflect.TypeOf(*c)); len(redacted) != want {
+		t.Errorf("Redacted() has %d settings, want %d", len(redacted), want)
+	}
+
+	tests := map[string]interface{}{
+		"server.port":                          8080,
+		"server.request_timeout":               "8s",
+		"auth.bootstrap_admin_username":        c.Auth.BootstrapAdminUsername,
+		"rate_limit.stats_refresh_rate":        float64(c.RateLimit.StatsRefreshRate),
+		"rate_limit.cleanup_interval_minutes":  c.RateLimit.CleanupIntervalMinutes,
+		"inventory.reservation_sweep_interval": c.Inventory.ReservationSweepInterval.String(),
+		"features.caching":                     true,
+	}
+	for key, want := range tests {
+		if got, ok := redacted[key]; !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v (present %t), want %v", key, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// defaultJWTSecret is the JWT_SECRET fallback, which must not sign production tokens
+const defaultJWTSecret = "your-secret-key"
+
+// minJWTSecretLength is the shortest JWT_SECRET accepted in production. HS256
+// keys should carry at least 256 bits.
+const minJWTSecretLength = 32
+
+// publicJWTSecrets are secrets published with the project, which must not
+// sign production tokens either
+var publicJWTSecrets = []string{defaultJWTSecret, "your-super-secure-jwt-secret-key"}
+
+// sameSiteModes are the JWT_COOKIE_SAME_SITE values
+var sameSiteModes = []string{"lax", "strict", "none"}
+
+// searchAnalyzers are the SEARCH_ANALYZER values: the built-in Elasticsearch
+// analyzers, including the language analyzers
+var searchAnalyzers = []string{
+	"standard", "simple", "whitespace", "stop", "keyword", "pattern", "fingerprint",
+	"arabic", "armenian", "basque", "bengali", "brazilian", "bulgarian", "catalan",
+	"cjk", "czech", "danish", "dutch", "english", "estonian", "finnish", "french",
+	"galician", "german", "greek", "hindi", "hungarian", "indonesian", "irish",
+	"italian", "latvian", "lithuanian", "norwegian", "persian", "portuguese",
+	"romanian", "russian", "serbian", "sorani", "spanish", "swedish", "thai", "turkish",
+}
+
+// logLevels are the LOGGER_LEVEL values the logger understands
+var logLevels = []string{"panic", "fatal", "error", "warn", "warning", "info", "debug", "trace"}
+
+// logFormats are the LOGGER_FORMAT values the logger understands
+var logFormats = []string{"json", "text"}
+
+// bestSellerMetrics are the STATS_BEST_SELLER_METRIC values
+var bestSellerMetrics = []string{"reviews", "wishlist"}
+
+// statsStores are the STATS_STORE values
+var statsStores = []string{"memory", "redis"}
+
+// Validate checks the configuration for unsafe or inconsistent settings and
+// returns every problem found, naming the environment variable to fix
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Environment == "production" {
+		switch {
+		case c.JWT.Secret == "" || oneOf(c.JWT.Secret, publicJWTSecrets):
+			errs = append(errs, errors.New("JWT_SECRET must be set to a non-default secret in production"))
+		case len(c.JWT.Secret) < minJWTSecretLength:
+			errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d characters in production, got %d", minJWTSecretLength, len(c.JWT.Secret)))
+		}
+	}
+	if !oneOf(c.JWT.CookieSameSite, sameSiteModes) {
+		errs = append(errs, fmt.Errorf("JWT_COOKIE_SAME_SITE must be one of %s, got %q", strings.Join(sameSiteModes, ", "), c.JWT.CookieSameSite))
+	}
+	if strings.EqualFold(c.JWT.CookieSameSite, "none") && !c.JWT.CookieSecure {
+		errs = append(errs, errors.New("JWT_COOKIE_SECURE must be true when JWT_COOKIE_SAME_SITE is none"))
+	}
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("SERVER_PORT must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	if c.Server.RequestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("SERVER_REQUEST_TIMEOUT must not be negative, got %s", c.Server.RequestTimeout))
+	}
+	if c.Server.WriteTimeout > 0 && c.Server.RequestTimeout > c.Server.WriteTimeout {
+		errs = append(errs, fmt.Errorf("SERVER_REQUEST_TIMEOUT (%s) must not exceed SERVER_WRITE_TIMEOUT (%s)", c.Server.RequestTimeout, c.Server.WriteTimeout))
+	}
+	if c.Database.MaxConns < c.Database.MinConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_CONNS (%d) must be at least DB_MIN_CONNS (%d)", c.Database.MaxConns, c.Database.MinConns))
+	}
+	if !oneOf(c.Logger.Level, logLevels) {
+		errs = append(errs, fmt.Errorf("LOGGER_LEVEL must be one of %s, got %q", strings.Join(logLevels, ", "), c.Logger.Level))
+	}
+	if !oneOf(c.Logger.Format, logFormats) {
+		errs = append(errs, fmt.Errorf("LOGGER_FORMAT must be one of %s, got %q", strings.Join(logFormats, ", "), c.Logger.Format))
+	}
+
+	if !oneOf(c.Stats.BestSellerMetric, bestSellerMetrics) {
+		errs = append(errs, fmt.Errorf("STATS_BEST_SELLER_METRIC must be one of %s, got %q", strings.Join(bestSellerMetrics, ", "), c.Stats.BestSellerMetric))
+	}
+
+	if c.Search.Analyzer != "" && !oneOf(c.Search.Analyzer, searchAnalyzers) {
+		errs = append(errs, fmt.Errorf("SEARCH_ANALYZER must be a built-in Elasticsearch analyzer such as standard or english, got %q", c.Search.Analyzer))
+	}
+
+	if c.Cache.ProductMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("CACHE_PRODUCT_MAX_AGE must not be negative, got %s", c.Cache.ProductMaxAge))
+	}
+	if c.Cache.CategoryMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("CACHE_CATEGORY_MAX_AGE must not be negative, got %s", c.Cache.CategoryMaxAge))
+	}
+	if c.Cache.ProductCountTTL < 0 {
+		errs = append(errs, fmt.Errorf("CACHE_PRODUCT_COUNT_TTL must not be negative, got %s", c.Cache.ProductCountTTL))
+	}
+
+	if !oneOf(c.Stats.Store, statsStores) {
+		errs = append(errs, fmt.Errorf("STATS_STORE must be one of %s, got %q", strings.Join(statsStores, ", "), c.Stats.Store))
+	}
+	if strings.EqualFold(c.Stats.Store, "redis") && c.Redis.Addr == "" {
+		errs = append(errs, errors.New("REDIS_ADDR is required when STATS_STORE is redis"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// oneOf reports whether value is one of values, ignoring case
+func oneOf(value string, values []string) bool {
+	for _, v := range values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			modify: func(c *Config) {},
+		},
+		{
+			name: "default JWT secret in production",
+			modify: func(c *Config) {
+				c.Environment = "production"
+			},
+			wantErr: "JWT_SECRET must be set to a non-default secret",
+		},
+		{
+			name: "sample JWT secret in production",
+			modify: func(c *Config) {
+				c.Environment = "production"
+				c.JWT.Secret = "your-super-secure-jwt-secret-key"
+			},
+			wantErr: "JWT_SECRET must be set to a non-default secret",
+		},
+		{
+			name: "short JWT secret in production",
+			modify: func(c *Config) {
+				c.Environment = "production"
+				c.JWT.Secret = "too-short"
+			},
+			wantErr: "JWT_SECRET must be at least 32 characters",
+		},
+		{
+			name: "strong JWT secret in production",
+			modify: func(c *Config) {
+				c.Environment = "production"
+				c.JWT.Secret = strings.Repeat("k", minJWTSecretLength)
+			},
+		},
+		{
+			name: "default JWT secret outside production",
+			modify: func(c *Config) {
+				c.Environment = "development"
+			},
+		},
+		{
+			name: "port out of range",
+			modify: func(c *Config) {
+				c.Server.Port = 0
+			},
+			wantErr: "SERVER_PORT must be between 1 and 65535",
+		},
+		{
+			name: "max conns below min conns",
+			modify: func(c *Config) {
+				c.Database.MaxConns = 1
+				c.Database.MinConns = 2
+			},
+			wantErr: "DB_MAX_CONNS (1) must be at least DB_MIN_CONNS (2)",
+		},
+		{
+			name: "unknown log level",
+			modify: func(c *Config) {
+				c.Logger.Level = "verbose"
+			},
+			wantErr: "LOGGER_LEVEL must be one of",
+		},
+		{
+			name: "unknown log format",
+			modify: func(c *Config) {
+				c.Logger.Format = "xml"
+			},
+			wantErr: "LOGGER_FORMAT must be one of",
+		},
+		{
+			name: "unknown SameSite mode",
+			modify: func(c *Config) {
+				c.JWT.CookieSameSite = "relaxed"
+			},
+			wantErr: "JWT_COOKIE_SAME_SITE must be one of",
+		},
+		{
+			name: "SameSite none without a secure cookie",
+			modify: func(c *Config) {
+				c.JWT.CookieSameSite = "none"
+				c.JWT.CookieSecure = false
+			},
+			wantErr: "JWT_COOKIE_SECURE must be true",
+		},
+		{
+			name: "request timeout longer than the write timeout",
+			modify: func(c *Config) {
+				c.Server.RequestTimeout = 20 * time.Second
+				c.Server.WriteTimeout = 10 * time.Second
+			},
+			wantErr: "SERVER_REQUEST_TIMEOUT (20s) must not exceed SERVER_WRITE_TIMEOUT (10s)",
+		},
+		{
+			name: "request timeout without a write timeout",
+			modify: func(c *Config) {
+				c.Server.RequestTimeout = 20 * time.Second
+				c.Server.WriteTimeout = 0
+			},
+		},
+		{
+			name: "unknown search analyzer",
+			modify: func(c *Config) {
+				c.Search.Analyzer = "klingon"
+			},
+			wantErr: "SEARCH_ANALYZER must be a built-in Elasticsearch analyzer",
+		},
+		{
+			name: "language search analyzer",
+			modify: func(c *Config) {
+				c.Search.Analyzer = "english"
+			},
+		},
+		{
+			name: "negative cache max-age",
+			modify: func(c *Config) {
+				c.Cache.CategoryMaxAge = -time.Second
+			},
+			wantErr: "CACHE_CATEGORY_MAX_AGE must not be negative",
+		},
+		{
+			name: "unknown best-seller metric",
+			modify: func(c *Config) {
+				c.Stats.BestSellerMetric = "orders"
+			},
+			wantErr: "STATS_BEST_SELLER_METRIC must be one of",
+		},
+		{
+			name: "redis store without an address",
+			modify: func(c *Config) {
+				c.Stats.Store = "redis"
+				c.Redis.Addr = ""
+			},
+			wantErr: "REDIS_ADDR is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfig()
+			tt.modify(c)
+
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	c := defaultConfig()
+	c.Server.Port = -1
+	c.Logger.Level = "verbose"
+
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want two problems")
+	}
+	if got := len(strings.Split(err.Error(), "\n")); got != 2 {
+		t.Errorf("Validate() reported %d problems, want 2: %v", got, err)
+	}
+}
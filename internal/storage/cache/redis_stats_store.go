@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Keys of the stats that aren't set by name
+const (
+	redisKeysKey           = "keys"
+	redisCategoryCountsKey = "category_counts"
+	redisWishlistCountsKey = "wishlist_counts"
+	redisLastRefreshedKey  = "last_refreshed"
+)
+
+// RedisStatsStore implements storage.StatsStore in Redis, so the stats survive
+// restarts and are shared by every instance. Values are stored as JSON under
+// the key prefix.
+type RedisStatsStore struct {
+	client     *redis.Client
+	prefix     string
+	defaultTTL time.Duration
+}
+
+// NewRedisStatsStore creates a new RedisStatsStore whose values set with Set
+// expire after defaultTTL. A zero defaultTTL keeps them until they are replaced.
+func NewRedisStatsStore(client *redis.Client, prefix string, defaultTTL time.Duration) *RedisStatsStore {
+	return &RedisStatsStore{
+		client:     client,
+		prefix:     prefix,
+		defaultTTL: defaultTTL,
+	}
+}
+
+// key returns the Redis key of a stat
+func (s *RedisStatsStore) key(name string) string {
+	return s.prefix + name
+}
+
+// Set stores a value for the default TTL
+func (s *RedisStatsStore) Set(ctx context.Context, key string, value interface{}) error {
+	return s.SetWithTTL(ctx, key, value, s.defaultTTL)
+}
+
+// SetWithTTL stores a value that expires after ttl. A zero ttl keeps it until
+// it is replaced. The key is remembered so GetAll can list it.
+func (s *RedisStatsStore) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.key(key), data, ttl)
+		pipe.SAdd(ctx, s.key(redisKeysKey), key)
+		pipe.Set(ctx, s.key(redisLastRefreshedKey), time.Now().Format(time.RFC3339), 0)
+		return nil
+	})
+	return err
+}
+
+// Get reads a value into dest. Expired values don't exist.
+func (s *RedisStatsStore) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	data, err := s.client.Get(ctx, s.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// pruneKeysScript forgets the remembered keys whose values have expired. It
+// runs atomically so a key set again meanwhile is never forgotten.
+var pruneKeysScript = redis.NewScript(`
+for _, name in ipairs(redis.call('SMEMBERS', KEYS[1])) do
+	if redis.call('EXISTS', ARGV[1] .. name) == 0 then
+		redis.call('SREM', KEYS[1], name)
+	end
+end
+return 0
+`)
+
+// GetAll returns all stored values that haven't expired, decoded as generic
+// JSON values like the memory store returns them. Expired keys are forgotten.
+func (s *RedisStatsStore) GetAll(ctx context.Context) (map[string]interface{}, error) {
+	names, err := s.client.SMembers(ctx, s.key(redisKeysKey)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = s.key(name)
+	}
+	keys = append(keys, s.key(redisLastRefreshedKey))
+
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(names)+1)
+	expired := false
+	for i, name := range names {
+		data, ok := values[i].(string)
+		if !ok {
+			// Expired since it was set
+			expired = true
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(data), &value); err != nil {
+			return nil, err
+		}
+		result[name] = value
+	}
+	if expired {
+		if err := pruneKeysScript.Run(ctx, s.client, []string{s.key(redisKeysKey)}, s.prefix).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Add metadata
+	lastRefreshed, _ := values[len(names)].(string)
+	result["last_refreshed"] = lastRefreshed
+
+	return result, nil
+}
+
+// SetCategoryCounts sets the product counts by category
+func (s *RedisStatsStore) SetCategoryCounts(ctx context.Context, counts map[uint]int) error {
+	return s.setCounts(ctx, redisCategoryCountsKey, counts)
+}
+
+// GetCategoryCounts gets the product counts by category
+func (s *RedisStatsStore) GetCategoryCounts(ctx context.Context) (map[uint]int, error) {
+	return s.getCounts(ctx, redisCategoryCountsKey)
+}
+
+// SetWishlistCounts sets the wishlist counts by product
+func (s *RedisStatsStore) SetWishlistCounts(ctx context.Context, counts map[uint]int) error {
+	return s.setCounts(ctx, redisWishlistCountsKey, counts)
+}
+
+// GetWishlistCounts gets the wishlist counts by product
+func (s *RedisStatsStore) GetWishlistCounts(ctx context.Context) (map[uint]int, error) {
+	return s.getCounts(ctx, redisWishlistCountsKey)
+}
+
+// setCounts stores counts by ID until they are replaced
+func (s *RedisStatsStore) setCounts(ctx context.Context, key string, counts map[uint]int) error {
+	data, err := json.Marshal(counts)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.key(key), data, 0).Err()
+}
+
+// getCounts reads counts by ID, which are empty if they were never set
+func (s *RedisStatsStore) getCounts(ctx context.Context, key string) (map[uint]int, error) {
+	counts := make(map[uint]int)
+	if _, err := s.Get(ctx, key, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// newTestRedisStore returns a RedisStatsStore backed by an in-memory Redis server
+func newTestRedisStore(t *testing.T) (*RedisStatsStore, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisStatsStore(client, "stats:", 0), server
+}
+
+func TestRedisStatsStore_GetAllMatchesMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	redisStore, _ := newTestRedisStore(t)
+	memoryStore := NewStatsCache(logger.NewLogger("error", "text", "stdout"), 0)
+
+	values := map[string]interface{}{
+		"total_products": int64(42),
+		"average_rating": 4.25,
+		"top_products":   []entity.TopProduct{{ProductID: 1, ProductName: "Widget", Count: 3, Metric: entity.MetricReviews}},
+	}
+	for key, value := range values {
+		if err := redisStore.Set(ctx, key, value); err != nil {
+			t.Fatalf("redis Set(%s) error = %v", key, err)
+		}
+		if err := memoryStore.Set(ctx, key, value); err != nil {
+			t.Fatalf("memory Set(%s) error = %v", key, err)
+		}
+	}
+
+	fromRedis, err := redisStore.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("redis GetAll() error = %v", err)
+	}
+	fromMemory, err := memoryStore.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("memory GetAll() error = %v", err)
+	}
+	for key := range values {
+		if !reflect.DeepEqual(fromRedis[key], fromMemory[key]) {
+			t.Errorf("%s: redis %#v, memory %#v", key, fromRedis[key], fromMemory[key])
+		}
+	}
+
+	// Typed reads still get the type the value was set as
+	var total int64
+	if ok, err := redisStore.Get(ctx, "total_products", &total); err != nil || !ok || total != 42 {
+		t.Errorf("Get(total_products) = %d, %t, %v, want 42", total, ok, err)
+	}
+}
+
+func TestRedisStatsStore_GetAllForgetsExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestRedisStore(t)
+
+	if err := store.SetWithTTL(ctx, "short_lived", 1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetWithTTL(ctx, "long_lived", 2, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	server.FastForward(2 * time.Second)
+
+	all, err := store.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if _, ok := all["short_lived"]; ok {
+		t.Error("GetAll() returned an expired value")
+	}
+	if got := all["long_lived"]; got != float64(2) {
+		t.Errorf("long_lived = %#v, want 2", got)
+	}
+
+	members, err := server.Members("stats:keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(members, []string{"long_lived"}) {
+		t.Errorf("remembered keys = %v, want only long_lived", members)
+	}
+}
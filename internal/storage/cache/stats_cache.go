@@ -1,68 +1,124 @@
 package cache
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/thanhnguyen/product-api/pkg/logger"
 )
 
-// StatsCache provides caching for real-time statistics
+// StatsCache implements storage.StatsStore in memory. Its stats are lost on
+// restart and aren't shared between instances.
 type StatsCache struct {
-	data           map[string]interface{}
+	data           map[string]cacheEntry
 	categoryCounts map[uint]int
 	wishlistCounts map[uint]int
 	mutex          sync.RWMutex
 	lastRefreshed  time.Time
+	defaultTTL     time.Duration
 	logger         *logger.Logger
+	now            func() time.Time
 }
 
-// NewStatsCache creates a new StatsCache
-func NewStatsCache(logger *logger.Logger) *StatsCache {
+// cacheEntry is a cached value with its expiry time. A zero expiry never expires.
+type cacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// expired reports whether the entry has expired at now
+func (e cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// NewStatsCache creates a new StatsCache whose values set with Set expire
+// after defaultTTL. A zero defaultTTL keeps them until they are replaced.
+func NewStatsCache(logger *logger.Logger, defaultTTL time.Duration) *StatsCache {
 	return &StatsCache{
-		data:           make(map[string]interface{}),
+		data:           make(map[string]cacheEntry),
 		categoryCounts: make(map[uint]int),
 		wishlistCounts: make(map[uint]int),
 		mutex:          sync.RWMutex{},
+		defaultTTL:     defaultTTL,
 		logger:         logger,
+		now:            time.Now,
 	}
 }
 
-// Set stores a value in the cache
-func (c *StatsCache) Set(key string, value interface{}) {
+// Set stores a value in the cache for the default TTL
+func (c *StatsCache) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithTTL(ctx, key, value, c.defaultTTL)
+}
+
+// SetWithTTL stores a value in the cache that expires after ttl. A zero ttl
+// keeps it until it is replaced.
+func (c *StatsCache) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	c.data[key] = value
-	c.lastRefreshed = time.Now()
+
+	now := c.now()
+	entry := cacheEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+	c.data[key] = entry
+	c.lastRefreshed = now
+
+	return nil
 }
 
-// Get retrieves a value from the cache
-func (c *StatsCache) Get(key string) (interface{}, bool) {
+// Get reads a value from the cache into dest. Expired values don't exist.
+func (c *StatsCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
 	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	value, exists := c.data[key]
-	return value, exists
+	entry, exists := c.data[key]
+	c.mutex.RUnlock()
+	if !exists || entry.expired(c.now()) {
+		return false, nil
+	}
+
+	target := reflect.ValueOf(dest)
+	value := reflect.ValueOf(entry.value)
+	if target.Kind() != reflect.Pointer || !value.Type().AssignableTo(target.Elem().Type()) {
+		return false, fmt.Errorf("cached %s is a %s, not a %T", key, value.Type(), dest)
+	}
+	target.Elem().Set(value)
+
+	return true, nil
 }
 
-// GetAll returns all cached data
-func (c *StatsCache) GetAll() map[string]interface{} {
+// GetAll returns all cached data that hasn't expired, decoded as generic JSON
+// values so the stores of every kind return the same types, e.g. float64 for
+// numbers
+func (c *StatsCache) GetAll(ctx context.Context) (map[string]interface{}, error) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
-	// Create a copy of the data to avoid concurrent access issues
+	// Copy the data to avoid concurrent access issues
+	now := c.now()
 	result := make(map[string]interface{}, len(c.data))
-	for k, v := range c.data {
-		result[k] = v
+	for k, entry := range c.data {
+		if entry.expired(now) {
+			continue
+		}
+		value, err := toJSONValue(entry.value)
+		if err != nil {
+			return nil, fmt.Errorf("cached %s: %w", k, err)
+		}
+		result[k] = value
 	}
 
 	// Add metadata
 	result["last_refreshed"] = c.lastRefreshed.Format(time.RFC3339)
 
-	return result
+	return result, nil
 }
 
 // SetCategoryCounts sets the product counts by category
-func (c *StatsCache) SetCategoryCounts(counts map[uint]int) {
+func (c *StatsCache) SetCategoryCounts(ctx context.Context, counts map[uint]int) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -72,11 +128,13 @@ func (c *StatsCache) SetCategoryCounts(counts map[uint]int) {
 		c.categoryCounts[k] = v
 	}
 
-	c.lastRefreshed = time.Now()
+	c.lastRefreshed = c.now()
+
+	return nil
 }
 
 // GetCategoryCounts gets the product counts by category
-func (c *StatsCache) GetCategoryCounts() map[uint]int {
+func (c *StatsCache) GetCategoryCounts(ctx context.Context) (map[uint]int, error) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
@@ -86,11 +144,11 @@ func (c *StatsCache) GetCategoryCounts() map[uint]int {
 		result[k] = v
 	}
 
-	return result
+	return result, nil
 }
 
 // SetWishlistCounts sets the wishlist counts by product
-func (c *StatsCache) SetWishlistCounts(counts map[uint]int) {
+func (c *StatsCache) SetWishlistCounts(ctx context.Context, counts map[uint]int) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -100,11 +158,13 @@ func (c *StatsCache) SetWishlistCounts(counts map[uint]int) {
 		c.wishlistCounts[k] = v
 	}
 
-	c.lastRefreshed = time.Now()
+	c.lastRefreshed = c.now()
+
+	return nil
 }
 
 // GetWishlistCounts gets the wishlist counts by product
-func (c *StatsCache) GetWishlistCounts() map[uint]int {
+func (c *StatsCache) GetWishlistCounts(ctx context.Context) (map[uint]int, error) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
@@ -114,17 +174,17 @@ func (c *StatsCache) GetWishlistCounts() map[uint]int {
 		result[k] = v
 	}
 
-	return result
+	return result, nil
 }
 
 // Clear clears all cached data
 func (c *StatsCache) Clear() {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	c.data = make(map[string]interface{})
+	c.data = make(map[string]cacheEntry)
 	c.categoryCounts = make(map[uint]int)
 	c.wishlistCounts = make(map[uint]int)
-	c.lastRefreshed = time.Now()
+	c.lastRefreshed = c.now()
 }
 
 // GetLastRefreshed returns the time when the cache was last refreshed
@@ -133,3 +193,51 @@ func (c *StatsCache) GetLastRefreshed() time.Time {
 	defer c.mutex.RUnlock()
 	return c.lastRefreshed
 }
+
+// CleanupTask removes expired values every interval until ctx is cancelled
+func (c *StatsCache) CleanupTask(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.cleanup()
+			}
+		}
+	}()
+}
+
+// cleanup removes expired values
+func (c *StatsCache) cleanup() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	removed := 0
+	for key, entry := range c.data {
+		if entry.expired(now) {
+			delete(c.data, key)
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		c.logger.WithField("removed", removed).Debug("Removed expired stats")
+	}
+}
+
+// toJSONValue converts a value to the generic value its JSON decodes to
+func toJSONValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
@@ -4,72 +4,411 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
 )
 
+// searchResultLimit caps the number of hits returned by a search
+const searchResultLimit = 50
+
 type Product struct {
 	ID          uint   `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// UpdatedAt is when the product was last updated before it was indexed
+	UpdatedAt time.Time `json:"updated_at"`
+	// Language is the language of the document text, named after the
+	// analyzer it was indexed with, e.g. english or spanish
+	Language string `json:"language,omitempty"`
+	// Highlights holds the matched description fragments of a search hit
+	Highlights []string `json:"-"`
 }
 
 type ProductSearch struct {
-	client *elasticsearch.Client
+	client   *elasticsearch.Client
+	analyzer string
+
+	// indexMu guards indexReady, which is set once the products index is known to exist
+	indexMu    sync.Mutex
+	indexReady bool
+}
+
+// Config contains the cluster address and credentials. Secured clusters take
+// either a username and password or an API key. Analyzer is the analyzer of
+// the searchable text, e.g. "english" or "spanish" for language-specific stemming.
+type Config struct {
+	URL      string
+	Username string
+	Password string
+	APIKey   string
+	Analyzer string
 }
 
-func NewProductSearch(esURL string) (*ProductSearch, error) {
-	cfg := elasticsearch.Config{Addresses: []string{esURL}}
+// defaultAnalyzer is used when no analyzer is configured
+const defaultAnalyzer = "standard"
+
+func NewProductSearch(config Config) (*ProductSearch, error) {
+	cfg := elasticsearch.Config{
+		Addresses: []string{config.URL},
+		Username:  config.Username,
+		Password:  config.Password,
+		APIKey:    config.APIKey,
+	}
 	client, err := elasticsearch.NewClient(cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &ProductSearch{client: client}, nil
+	analyzer := config.Analyzer
+	if analyzer == "" {
+		analyzer = defaultAnalyzer
+	}
+	return &ProductSearch{client: client, analyzer: analyzer}, nil
 }
 
-// Index a product
+// Ping checks that the cluster is reachable
+func (ps *ProductSearch) Ping(ctx context.Context) error {
+	res, err := ps.client.Ping(ps.client.Ping.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch ping failed: %s", res.Status())
+	}
+	return nil
+}
+
+// productsIndex is the index holding the searchable product fields
+const productsIndex = "products"
+
+// productsMapping returns the explicit mapping of the products index. The text
+// fields use the given analyzer and the name has a keyword sub-field for exact
+// matches and sorting.
+func productsMapping(analyzer string) map[string]interface{} {
+	return map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type": "integer",
+				},
+				"name": map[string]interface{}{
+					"type":     "text",
+					"analyzer": analyzer,
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{
+							"type":         "keyword",
+							"ignore_above": 256,
+						},
+					},
+				},
+				"description": map[string]interface{}{
+					"type":     "text",
+					"analyzer": analyzer,
+				},
+				"updated_at": map[string]interface{}{
+					"type": "date",
+				},
+				"language": map[string]interface{}{
+					"type": "keyword",
+				},
+			},
+		},
+	}
+}
+
+// EnsureIndex creates the products index with its explicit mapping unless it
+// already exists. It only checks the cluster until the index is found.
+func (ps *ProductSearch) EnsureIndex(ctx context.Context) error {
+	ps.indexMu.Lock()
+	defer ps.indexMu.Unlock()
+
+	if ps.indexReady {
+		return nil
+	}
+
+	// Check if the index exists
+	res, err := ps.client.Indices.Exists(
+		[]string{productsIndex},
+		ps.client.Indices.Exists.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+		ps.indexReady = true
+		return nil
+	case http.StatusNotFound:
+	default:
+		return fmt.Errorf("failed to check the products index: %s", res.Status())
+	}
+
+	// Create the index
+	data, err := json.Marshal(productsMapping(ps.analyzer))
+	if err != nil {
+		return err
+	}
+	res, err = ps.client.Indices.Create(
+		productsIndex,
+		ps.client.Indices.Create.WithContext(ctx),
+		ps.client.Indices.Create.WithBody(bytes.NewReader(data)),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// Another instance may have created the index in the meantime
+	if res.IsError() && !bytes.Contains(readBody(res.Body), []byte("resource_already_exists_exception")) {
+		return fmt.Errorf("failed to create the products index: %s", res.Status())
+	}
+
+	ps.indexReady = true
+	return nil
+}
+
+// readBody reads a response body, returning nothing if it can't be read
+func readBody(body io.Reader) []byte {
+	data, _ := io.ReadAll(body)
+	return data
+}
+
+// Index a product. Reindexing a product replaces its document. The index is
+// created on first use.
 func (ps *ProductSearch) IndexProduct(ctx context.Context, p Product) error {
-	data, _ := json.Marshal(p)
-	_, err := ps.client.Index("products", bytes.NewReader(data))
-	return err
+	if err := ps.EnsureIndex(ctx); err != nil {
+		return err
+	}
+
+	p.Language = ps.analyzer
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	res, err := ps.client.Index(
+		productsIndex,
+		bytes.NewReader(data),
+		ps.client.Index.WithContext(ctx),
+		ps.client.Index.WithDocumentID(strconv.FormatUint(uint64(p.ID), 10)),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("failed to index product %d: %s", p.ID, res.Status())
+	}
+	return nil
+}
+
+// DeleteProduct removes a product from the index. Removing a product that
+// isn't indexed is not an error.
+func (ps *ProductSearch) DeleteProduct(ctx context.Context, id uint) error {
+	res, err := ps.client.Delete(
+		productsIndex,
+		strconv.FormatUint(uint64(id), 10),
+		ps.client.Delete.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete product %d from the index: %s", id, res.Status())
+	}
+	return nil
 }
 
-// Search by description
-func (ps *ProductSearch) SearchByDescription(ctx context.Context, desc string) ([]Product, error) {
+// scanPageSize is the number of documents read per request when listing the index
+const scanPageSize = 1000
+
+// IndexedProducts returns the update time of every indexed product, by ID.
+// Documents indexed before update times were recorded have a zero time.
+func (ps *ProductSearch) IndexedProducts(ctx context.Context) (map[uint]time.Time, error) {
+	if err := ps.EnsureIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	// Page through the index by ID, each page starting after the last ID of
+	// the previous one
+	indexed := make(map[uint]time.Time)
+	var after []interface{}
+	for {
+		query := map[string]interface{}{
+			"size":    scanPageSize,
+			"_source": []string{"id", "updated_at"},
+			"sort":    []map[string]string{{"id": "asc"}},
+			"query": map[string]interface{}{
+				"match_all": map[string]interface{}{},
+			},
+		}
+		if after != nil {
+			query["search_after"] = after
+		}
+
+		hits, err := ps.scan(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			indexed[hit.ID] = hit.UpdatedAt
+		}
+		if len(hits) < scanPageSize {
+			return indexed, nil
+		}
+		after = []interface{}{hits[len(hits)-1].ID}
+	}
+}
+
+// scan runs a listing query against the products index and returns the
+// matching documents
+func (ps *ProductSearch) scan(ctx context.Context, query map[string]interface{}) ([]Product, error) {
+	data, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ps.client.Search(
+		ps.client.Search.WithContext(ctx),
+		ps.client.Search.WithIndex(productsIndex),
+		ps.client.Search.WithBody(bytes.NewReader(data)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to list the products index: %s", res.Status())
+	}
+
+	var scanResult struct {
+		Hits struct {
+			Hits []struct {
+				Source Product `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&scanResult); err != nil {
+		return nil, err
+	}
+
+	products := make([]Product, len(scanResult.Hits.Hits))
+	for i, hit := range scanResult.Hits.Hits {
+		products[i] = hit.Source
+	}
+	return products, nil
+}
+
+// Search by description. It returns one page of hits, with the matched
+// description fragments highlighted, along with the total number of matches.
+func (ps *ProductSearch) SearchByDescription(ctx context.Context, desc string, from, size int) ([]Product, int64, error) {
 	query := map[string]interface{}{
+		"from":             from,
+		"size":             resultSize(size),
+		"track_total_hits": true,
 		"query": map[string]interface{}{
 			"match": map[string]interface{}{
-				"description": desc,
+				"description": map[string]interface{}{
+					"query":    desc,
+					"analyzer": ps.analyzer,
+				},
+			},
+		},
+		"highlight": map[string]interface{}{
+			"fields": map[string]interface{}{
+				"description": map[string]interface{}{},
+			},
+		},
+	}
+	return ps.search(ctx, query)
+}
+
+// SearchOptions controls paging and typo tolerance of a search
+type SearchOptions struct {
+	From int
+	Size int
+	// Fuzziness is the allowed edit distance, e.g. "AUTO", "1" or "0" for exact terms.
+	// Empty means "AUTO".
+	Fuzziness string
+}
+
+// Search by name and description, ranking name matches above description-only
+// matches and tolerating small typos. It returns one page of hits along with
+// the total number of matches.
+func (ps *ProductSearch) Search(ctx context.Context, text string, opts SearchOptions) ([]Product, int64, error) {
+	fuzziness := opts.Fuzziness
+	if fuzziness == "" {
+		fuzziness = "AUTO"
+	}
+	query := map[string]interface{}{
+		"from":             opts.From,
+		"size":             resultSize(opts.Size),
+		"track_total_hits": true,
+		"query": map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":     text,
+				"fields":    []string{"name^2", "description"},
+				"type":      "best_fields",
+				"fuzziness": fuzziness,
+				"analyzer":  ps.analyzer,
 			},
 		},
 	}
+	return ps.search(ctx, query)
+}
+
+// resultSize caps the number of hits a search asks for at searchResultLimit
+func resultSize(size int) int {
+	if size > searchResultLimit {
+		return searchResultLimit
+	}
+	return size
+}
+
+// search runs a query against the products index and returns the matching
+// documents along with the total number of hits
+func (ps *ProductSearch) search(ctx context.Context, query map[string]interface{}) ([]Product, int64, error) {
 	var buf bytes.Buffer
 	json.NewEncoder(&buf).Encode(query)
 	res, err := ps.client.Search(
 		ps.client.Search.WithContext(ctx),
-		ps.client.Search.WithIndex("products"),
+		ps.client.Search.WithIndex(productsIndex),
 		ps.client.Search.WithBody(&buf),
 	)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer res.Body.Close()
+	if res.IsError() {
+		return nil, 0, fmt.Errorf("failed to search the products index: %s", res.Status())
+	}
+
 	var searchResult struct {
 		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
 			Hits []struct {
-				Source Product `json:"_source"`
+				Source    Product             `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
 			} `json:"hits"`
 		} `json:"hits"`
 	}
 
 	if err := json.NewDecoder(res.Body).Decode(&searchResult); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	products := make([]Product, len(searchResult.Hits.Hits))
 	for i, hit := range searchResult.Hits.Hits {
 		products[i] = hit.Source
+		products[i].Highlights = hit.Highlight["description"]
 	}
 
-	return products, nil
+	return products, searchResult.Hits.Total.Value, nil
 }
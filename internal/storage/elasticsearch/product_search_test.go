@@ -0,0 +1,229 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// recordedRequest is a request the mock transport received
+type recordedRequest struct {
+	Method string
+	Path   string
+	Body   map[string]interface{}
+}
+
+// mockTransport answers every request with a canned response and records
+// what the client sent
+type mockTransport struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	respond  func(req *http.Request) (int, string)
+}
+
+func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := recordedRequest{Method: req.Method, Path: req.URL.Path}
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		if len(data) > 0 {
+			json.Unmarshal(data, &rec.Body)
+		}
+	}
+	m.mu.Lock()
+	m.requests = append(m.requests, rec)
+	m.mu.Unlock()
+
+	status, body := http.StatusOK, `{}`
+	if m.respond != nil {
+		status, body = m.respond(req)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Elastic-Product", "Elasticsearch")
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func (m *mockTransport) recorded() []recordedRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]recordedRequest(nil), m.requests...)
+}
+
+// newMockSearch returns a ProductSearch backed by the mock transport
+func newMockSearch(t *testing.T, analyzer string, transport *mockTransport) *ProductSearch {
+	t.Helper()
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: []string{"http://localhost:9200"},
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("failed to create the client: %v", err)
+	}
+	return &ProductSearch{client: client, analyzer: analyzer}
+}
+
+// lookup walks nested JSON objects along the given keys
+func lookup(v interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func TestIndexProduct_UsesConfiguredAnalyzer(t *testing.T) {
+	transport := &mockTransport{respond: func(req *http.Request) (int, string) {
+		if req.Method == http.MethodHead {
+			return http.StatusNotFound, ``
+		}
+		return http.StatusOK, `{}`
+	}}
+	ps := newMockSearch(t, "english", transport)
+
+	if err := ps.IndexProduct(context.Background(), Product{ID: 7, Name: "Running shoes"}); err != nil {
+		t.Fatalf("IndexProduct() error = %v", err)
+	}
+
+	requests := transport.recorded()
+	if len(requests) != 3 {
+		t.Fatalf("expected an exists check, an index creation and a document, got %+v", requests)
+	}
+
+	create := requests[1]
+	if create.Method != http.MethodPut || create.Path != "/products" {
+		t.Fatalf("expected the index to be created, got %s %s", create.Method, create.Path)
+	}
+	for _, field := range []string{"name", "description"} {
+		got := lookup(create.Body, "mappings", "properties", field, "analyzer")
+		if got != "english" {
+			t.Errorf("%s analyzer = %v, want english", field, got)
+		}
+	}
+
+	if got := lookup(create.Body, "mappings", "properties", "language", "type"); got != "keyword" {
+		t.Errorf("language mapping type = %v, want keyword", got)
+	}
+
+	doc := requests[2]
+	if doc.Path != "/products/_doc/7" {
+		t.Fatalf("expected the document to be indexed by ID, got %s %s", doc.Method, doc.Path)
+	}
+	if got := doc.Body["language"]; got != "english" {
+		t.Errorf("document language = %v, want english", got)
+	}
+}
+
+func TestSearchByDescription_UsesConfiguredAnalyzer(t *testing.T) {
+	transport := &mockTransport{respond: func(req *http.Request) (int, string) {
+		return http.StatusOK, `{"hits":{"total":{"value":0},"hits":[]}}`
+	}}
+	ps := newMockSearch(t, "spanish", transport)
+
+	if _, _, err := ps.SearchByDescription(context.Background(), "zapatos", 0, 10); err != nil {
+		t.Fatalf("SearchByDescription() error = %v", err)
+	}
+
+	requests := transport.recorded()
+	if len(requests) != 1 {
+		t.Fatalf("expected one search request, got %+v", requests)
+	}
+	if got := lookup(requests[0].Body, "query", "match", "description", "analyzer"); got != "spanish" {
+		t.Errorf("query analyzer = %v, want spanish", got)
+	}
+}
+
+func TestSearch_FuzzyMultiMatchRanksNameHigher(t *testing.T) {
+	transport := &mockTransport{respond: func(req *http.Request) (int, string) {
+		return http.StatusOK, `{"hits":{"total":{"value":1},"hits":[{"_source":{"id":3,"name":"Laptop","description":"Thin"}}]}}`
+	}}
+	ps := newMockSearch(t, "standard", transport)
+
+	products, total, err := ps.Search(context.Background(), "labtop", SearchOptions{From: 20, Size: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if total != 1 || len(products) != 1 || products[0].ID != 3 {
+		t.Fatalf("unexpected result: total=%d products=%+v", total, products)
+	}
+
+	body := transport.recorded()[0].Body
+	if body["from"] != float64(20) || body["size"] != float64(10) {
+		t.Errorf("unexpected paging: from=%v size=%v", body["from"], body["size"])
+	}
+	match, ok := lookup(body, "query", "multi_match").(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a multi_match query, got %v", body["query"])
+	}
+	fields, _ := json.Marshal(match["fields"])
+	if string(fields) != `["name^2","description"]` {
+		t.Errorf("fields = %s, want name boosted over description", fields)
+	}
+	if match["fuzziness"] != "AUTO" {
+		t.Errorf("fuzziness = %v, want AUTO by default", match["fuzziness"])
+	}
+	if match["query"] != "labtop" {
+		t.Errorf("query = %v, want labtop", match["query"])
+	}
+}
+
+func TestSearch_ExplicitFuzziness(t *testing.T) {
+	transport := &mockTransport{respond: func(req *http.Request) (int, string) {
+		return http.StatusOK, `{"hits":{"total":{"value":0},"hits":[]}}`
+	}}
+	ps := newMockSearch(t, "standard", transport)
+
+	if _, _, err := ps.Search(context.Background(), "laptop", SearchOptions{Size: 10, Fuzziness: "0"}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got := lookup(transport.recorded()[0].Body, "query", "multi_match", "fuzziness"); got != "0" {
+		t.Errorf("fuzziness = %v, want 0", got)
+	}
+}
+
+func TestSearch_CapsResultSize(t *testing.T) {
+	transport := &mockTransport{respond: func(req *http.Request) (int, string) {
+		return http.StatusOK, `{"hits":{"total":{"value":0},"hits":[]}}`
+	}}
+	ps := newMockSearch(t, "standard", transport)
+
+	if _, _, err := ps.Search(context.Background(), "laptop", SearchOptions{Size: searchResultLimit + 50}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if _, _, err := ps.SearchByDescription(context.Background(), "thin", 0, searchResultLimit+1); err != nil {
+		t.Fatalf("SearchByDescription() error = %v", err)
+	}
+	for _, req := range transport.recorded() {
+		if req.Body["size"] != float64(searchResultLimit) {
+			t.Errorf("size = %v, want %d", req.Body["size"], searchResultLimit)
+		}
+	}
+}
+
+func TestSearch_ErrorResponse(t *testing.T) {
+	transport := &mockTransport{respond: func(req *http.Request) (int, string) {
+		return http.StatusBadRequest, `{"error":{"type":"search_phase_execution_exception"},"status":400}`
+	}}
+	ps := newMockSearch(t, "standard", transport)
+
+	products, total, err := ps.Search(context.Background(), "laptop", SearchOptions{Size: 10})
+	if err == nil {
+		t.Fatalf("Search() = %+v (total %d), want an error", products, total)
+	}
+	if !strings.Contains(err.Error(), "400") {
+		t.Errorf("Search() error = %v, want the response status", err)
+	}
+}
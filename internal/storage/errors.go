@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDuplicateKey is returned when a write violates a unique constraint
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// ErrNotFound is returned when a write targets a record that doesn't exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrTooManyRows is returned when a bulk write matches more rows than it may change
+var ErrTooManyRows = errors.New("too many rows")
+
+// ErrNotOwner is returned when a write on behalf of one user is made while
+// another is authenticated
+var ErrNotOwner = errors.New("record belongs to another user")
+
+// ErrReferenced is returned when a delete is refused because other records
+// still reference the record
+var ErrReferenced = errors.New("record is still referenced")
+
+// InsufficientStockError is returned when a stock adjustment would take a
+// product's stock below zero
+type InsufficientStockError struct {
+	ProductID uint
+	Available int
+	Requested int
+}
+
+// Error implements the error interface
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %d: %d available, %d requested", e.ProductID, e.Available, e.Requested)
+}
@@ -3,43 +3,38 @@ package postgres
 import (
 	"context"
 	"errors"
-	"sync"
+	"strings"
 
 	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
 	"github.com/thanhnguyen/product-api/pkg/logger"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CategoryRepository implements storage.CategoryRepository
 type CategoryRepository struct {
 	db     *Database
 	logger *logger.Logger
-	pool   *sync.Pool
+	counts *CountCache
 }
 
-// NewCategoryRepository creates a new CategoryRepository
-func NewCategoryRepository(db *Database, logger *logger.Logger) *CategoryRepository {
+// NewCategoryRepository creates a new CategoryRepository. Writes to products
+// invalidate the product counts cached in counts, which may be nil.
+func NewCategoryRepository(db *Database, logger *logger.Logger, counts *CountCache) *CategoryRepository {
 	return &CategoryRepository{
 		db:     db,
 		logger: logger,
-		pool: &sync.Pool{
-			New: func() interface{} {
-				return &Category{}
-			},
-		},
+		counts: counts,
 	}
 }
 
 // Create creates a new category
 func (r *CategoryRepository) Create(ctx context.Context, category *entity.Category) error {
-	// Get a model instance from the pool
-	model := r.pool.Get().(*Category)
-	defer r.pool.Put(model)
-
-	// Reset fields to avoid data leakage
-	*model = Category{
+	model := &Category{
 		Name:        category.Name,
 		Description: category.Description,
+		ParentID:    category.ParentID,
 	}
 
 	// Create the category
@@ -53,10 +48,10 @@ func (r *CategoryRepository) Create(ctx context.Context, category *entity.Catego
 	return nil
 }
 
-// List lists all categories
+// List lists all categories ordered by ID
 func (r *CategoryRepository) List(ctx context.Context) ([]entity.Category, error) {
 	var models []Category
-	if err := r.db.WithContext(ctx).Find(&models).Error; err != nil {
+	if err := r.db.WithContext(ctx).Order("id").Find(&models).Error; err != nil {
 		return nil, err
 	}
 
@@ -67,6 +62,38 @@ func (r *CategoryRepository) List(ctx context.Context) ([]entity.Category, error
 			ID:          model.ID,
 			Name:        model.Name,
 			Description: model.Description,
+			Disabled:    model.Disabled,
+			ParentID:    model.ParentID,
+		}
+	}
+
+	return categories, nil
+}
+
+// Search lists the categories matching the filter ordered by name
+func (r *CategoryRepository) Search(ctx context.Context, filter entity.CategoryFilter) ([]entity.Category, error) {
+	query := r.db.WithContext(ctx).Model(&Category{})
+	if filter.Name != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(filter.Name)+"%")
+	}
+	if !filter.IncludeDisabled {
+		query = query.Where("disabled = ?", false)
+	}
+
+	var models []Category
+	if err := query.Order("name").Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	categories := make([]entity.Category, len(models))
+	for i, model := range models {
+		categories[i] = entity.Category{
+			ID:          model.ID,
+			Name:        model.Name,
+			Description: model.Description,
+			Disabled:    model.Disabled,
+			ParentID:    model.ParentID,
 		}
 	}
 
@@ -75,9 +102,7 @@ func (r *CategoryRepository) List(ctx context.Context) ([]entity.Category, error
 
 // FindByID finds a category by ID
 func (r *CategoryRepository) FindByID(ctx context.Context, id uint) (*entity.Category, error) {
-	// Get a model instance from the pool
-	model := r.pool.Get().(*Category)
-	defer r.pool.Put(model)
+	model := &Category{}
 
 	// Find the category
 	if err := r.db.WithContext(ctx).First(model, id).Error; err != nil {
@@ -92,17 +117,20 @@ func (r *CategoryRepository) FindByID(ctx context.Context, id uint) (*entity.Cat
 		ID:          model.ID,
 		Name:        model.Name,
 		Description: model.Description,
+		Disabled:    model.Disabled,
+		ParentID:    model.ParentID,
 	}, nil
 }
 
-// FindByIDs finds categories by IDs
+// FindByIDs finds the enabled categories with the given IDs. Disabled
+// categories are skipped like missing ones, so products can't be added to them.
 func (r *CategoryRepository) FindByIDs(ctx context.Context, ids []uint) ([]entity.Category, error) {
 	if len(ids) == 0 {
 		return []entity.Category{}, nil
 	}
 
 	var models []Category
-	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&models).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id IN ? AND disabled = ?", ids, false).Find(&models).Error; err != nil {
 		return nil, err
 	}
 
@@ -113,8 +141,193 @@ func (r *CategoryRepository) FindByIDs(ctx context.Context, ids []uint) ([]entit
 			ID:          model.ID,
 			Name:        model.Name,
 			Description: model.Description,
+			Disabled:    model.Disabled,
+			ParentID:    model.ParentID,
 		}
 	}
 
 	return categories, nil
 }
+
+// Update updates a category's name, description and parent
+func (r *CategoryRepository) Update(ctx context.Context, category *entity.Category) error {
+	result := r.db.WithContext(ctx).
+		Model(&Category{}).
+		Where("id = ?", category.ID).
+		Updates(map[string]interface{}{
+			"name":        category.Name,
+			"description": category.Description,
+			"parent_id":   category.ParentID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
+
+// Tree returns the category hierarchy: the top-level categories with their
+// sub-categories nested below them
+func (r *CategoryRepository) Tree(ctx context.Context) ([]entity.CategoryNode, error) {
+	categories, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the categories by parent
+	children := make(map[uint][]entity.Category)
+	var roots []entity.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	return buildCategoryNodes(roots, children), nil
+}
+
+// buildCategoryNodes nests the children of each category below it
+func buildCategoryNodes(categories []entity.Category, children map[uint][]entity.Category) []entity.CategoryNode {
+	nodes := make([]entity.CategoryNode, 0, len(categories))
+	for _, category := range categories {
+		nodes = append(nodes, entity.CategoryNode{
+			Category: category,
+			Children: buildCategoryNodes(children[category.ID], children),
+		})
+	}
+	return nodes
+}
+
+// SetDisabled disables or re-enables a category
+func (r *CategoryRepository) SetDisabled(ctx context.Context, id uint, disabled bool) error {
+	defer r.counts.invalidate()
+
+	return r.db.WithContext(ctx).
+		Model(&Category{}).
+		Where("id = ?", id).
+		Update("disabled", disabled).Error
+}
+
+// CountProductsByCategory counts the products in each category. Categories
+// without products are omitted.
+func (r *CategoryRepository) CountProductsByCategory(ctx context.Context) (map[uint]int, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int
+	}
+	err := r.db.WithContext(ctx).
+		Table("product_categories").
+		Select("category_id, COUNT(*) AS count").
+		Group("category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// CountProductsByCategoryTree counts the distinct products in each category
+// and its sub-categories. Categories without products are omitted.
+func (r *CategoryRepository) CountProductsByCategoryTree(ctx context.Context) (map[uint]int, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id AS root_id, id FROM categories
+			UNION ALL
+			SELECT tree.root_id, c.id FROM categories c JOIN tree ON c.parent_id = tree.id
+		)
+		SELECT tree.root_id AS category_id, COUNT(DISTINCT pc.product_id) AS count
+		FROM tree
+		JOIN product_categories pc ON pc.category_id = tree.id
+		GROUP BY tree.root_id`).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// Delete deletes a category. If reassignTo is set, the category's products are
+// moved to that category in the same transaction. Otherwise they are removed
+// from the deleted category if cascade is set, and storage.ErrReferenced is
+// returned if it isn't.
+func (r *CategoryRepository) Delete(ctx context.Context, id uint, reassignTo *uint, cascade bool) error {
+	defer r.counts.invalidate()
+
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Refuse to delete a category that still has products. Locking the category
+	// blocks products from being added to it until the transaction ends.
+	if reassignTo == nil && !cascade {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&Category{}, id).Error; err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return storage.ErrNotFound
+			}
+			return err
+		}
+
+		var count int64
+		if err := tx.Table("product_categories").Where("category_id = ?", id).Count(&count).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if count > 0 {
+			tx.Rollback()
+			return storage.ErrReferenced
+		}
+	}
+
+	// Move the products to the fallback category
+	if reassignTo != nil {
+		err := tx.Exec(`
+			INSERT INTO product_categories (product_id, category_id)
+			SELECT product_id, ? FROM product_categories WHERE category_id = ?
+			ON CONFLICT DO NOTHING`, *reassignTo, id).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Remove the category's product associations
+	if err := tx.Exec("DELETE FROM product_categories WHERE category_id = ?", id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete the category
+	if err := tx.Delete(&Category{}, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
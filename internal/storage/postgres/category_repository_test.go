@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/storage"
+)
+
+func TestDeleteCategory_ReassignsProducts(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewCategoryRepository(db, testLogger(), nil)
+	target := uint(4)
+
+	// The products are added to the target before leaving the deleted category
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO product_categories \(product_id, category_id\)\s+SELECT product_id, \$1 FROM product_categories WHERE category_id = \$2\s+ON CONFLICT DO NOTHING`).
+		WithArgs(4, 3).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM product_categories WHERE category_id = \$1`).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM "categories" WHERE "categories"."id" = \$1`).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.Delete(context.Background(), 3, &target, false); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+}
+
+func TestDeleteCategory_ReassignFailureRollsBack(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewCategoryRepository(db, testLogger(), nil)
+	target := uint(4)
+
+	// A failed move leaves the category and its products in place
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO product_categories`).
+		WithArgs(4, 3).
+		WillReturnError(errors.New("foreign key violation"))
+	mock.ExpectRollback()
+
+	if err := repo.Delete(context.Background(), 3, &target, false); err == nil {
+		t.Fatal("Delete() error = nil, want the reassignment failure")
+	}
+}
+
+func TestDeleteCategory_RefusesCategoryWithProducts(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewCategoryRepository(db, testLogger(), nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "categories" WHERE "categories"."id" = \$1 .*FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "product_categories" WHERE category_id = \$1`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	if err := repo.Delete(context.Background(), 3, nil, false); !errors.Is(err, storage.ErrReferenced) {
+		t.Errorf("Delete() error = %v, want %v", err, storage.ErrReferenced)
+	}
+}
+
+func TestFindCategoriesByIDs_SkipsDisabled(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewCategoryRepository(db, testLogger(), nil)
+
+	// Category 2 is disabled, so the database leaves it out
+	mock.ExpectQuery(`SELECT \* FROM "categories" WHERE id IN \(\$1,\$2\) AND disabled = \$3`).
+		WithArgs(1, 2, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Lighting"))
+
+	categories, err := repo.FindByIDs(context.Background(), []uint{1, 2})
+	if err != nil {
+		t.Fatalf("FindByIDs() error = %v", err)
+	}
+	if len(categories) != 1 || categories[0].ID != 1 {
+		t.Errorf("categories = %+v, want only category 1", categories)
+	}
+}
+
+func TestCountProductsByCategory(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewCategoryRepository(db, testLogger(), nil)
+
+	mock.ExpectQuery(`SELECT category_id, COUNT\(\*\) AS count FROM "product_categories" GROUP BY "category_id"`).
+		WillReturnRows(sqlmock.NewRows([]string{"category_id", "count"}).
+			AddRow(1, 3).
+			AddRow(4, 1))
+
+	counts, err := repo.CountProductsByCategory(context.Background())
+	if err != nil {
+		t.Fatalf("CountProductsByCategory() error = %v", err)
+	}
+	if want := map[uint]int{1: 3, 4: 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountProductsByCategory() = %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+)
+
+// maxCachedCounts bounds the number of filters whose count is cached
+const maxCachedCounts = 1000
+
+// CountCache caches the product counts of list filters for a short time, so
+// paging through the same filter runs the count query once. It is shared by
+// every repository that writes products, each of which invalidates it. A nil
+// cache caches nothing.
+type CountCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu     sync.Mutex
+	counts map[string]cachedCount
+	// generation changes on every invalidation, so a count read before a
+	// write isn't cached after it
+	generation uint64
+}
+
+// cachedCount is a cached count and when it expires
+type cachedCount struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// NewCountCache creates a CountCache whose counts expire after ttl. A ttl of
+// zero or less disables it.
+func NewCountCache(ttl time.Duration) *CountCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &CountCache{
+		ttl:    ttl,
+		now:    time.Now,
+		counts: make(map[string]cachedCount),
+	}
+}
+
+// countKey returns the signature of a filter's count. Paging and sorting
+// don't change the count, so they aren't part of it.
+func countKey(filter entity.ProductFilter) string {
+	filter.Page = 0
+	filter.PageSize = 0
+	filter.SortBy = ""
+	filter.SortOrder = ""
+	filter.After = nil
+	data, _ := json.Marshal(filter)
+	return string(data)
+}
+
+// get returns the cached count of a key along with the current generation,
+// which must be passed to set when the count isn't cached
+func (c *CountCache) get(key string) (int64, uint64, bool) {
+	if c == nil {
+		return 0, 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.counts[key]
+	if !ok || !c.now().Before(cached.expiresAt) {
+		return 0, c.generation, false
+	}
+	return cached.count, c.generation, true
+}
+
+// set caches the count of a key unless the cache was invalidated since the
+// given generation
+func (c *CountCache) set(key string, count int64, generation uint64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	// Make room by dropping the expired counts, or all of them if none expired
+	now := c.now()
+	if len(c.counts) >= maxCachedCounts {
+		for k, cached := range c.counts {
+			if !now.Before(cached.expiresAt) {
+				delete(c.counts, k)
+			}
+		}
+		if len(c.counts) >= maxCachedCounts {
+			c.counts = make(map[string]cachedCount)
+		}
+	}
+
+	c.counts[key] = cachedCount{count: count, expiresAt: now.Add(c.ttl)}
+}
+
+// invalidate drops every cached count
+func (c *CountCache) invalidate() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts = make(map[string]cachedCount)
+	c.generation++
+}
@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+)
+
+// newTestCountCache returns a countCache whose clock is advanced by hand
+func newTestCountCache(ttl time.Duration) (*CountCache, *time.Time) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCountCache(ttl)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCountKey_IgnoresPagingAndSorting(t *testing.T) {
+	base := entity.ProductFilter{Search: "lamp", CategoryID: 3}
+	paged := base
+	paged.Page = 4
+	paged.PageSize = 50
+	paged.SortBy = "price"
+	paged.SortOrder = "desc"
+	paged.After = &entity.ProductCursor{}
+	if countKey(base) != countKey(paged) {
+		t.Errorf("paging and sorting changed the key: %s != %s", countKey(base), countKey(paged))
+	}
+
+	other := base
+	other.IncludeArchived = true
+	if countKey(base) == countKey(other) {
+		t.Error("filters with different matches share a key")
+	}
+}
+
+func TestCountCache_ExpiresAfterTTL(t *testing.T) {
+	c, now := newTestCountCache(time.Minute)
+
+	_, generation, ok := c.get("k")
+	if ok {
+		t.Fatal("empty cache returned a count")
+	}
+	c.set("k", 12, generation)
+
+	if count, _, ok := c.get("k"); !ok || count != 12 {
+		t.Fatalf("get() = %d, %v, want 12, true", count, ok)
+	}
+
+	*now = now.Add(time.Minute)
+	if _, _, ok := c.get("k"); ok {
+		t.Error("count still cached after its TTL")
+	}
+}
+
+func TestCountCache_InvalidateDropsCountsAndStaleWrites(t *testing.T) {
+	c, _ := newTestCountCache(time.Minute)
+
+	_, generation, _ := c.get("a")
+	c.set("a", 1, generation)
+
+	// A count read before a write must not be cached after it
+	_, stale, _ := c.get("b")
+	c.invalidate()
+	c.set("b", 2, stale)
+
+	if _, _, ok := c.get("a"); ok {
+		t.Error("invalidate kept a cached count")
+	}
+	if _, _, ok := c.get("b"); ok {
+		t.Error("a count read before invalidate was cached")
+	}
+
+	_, generation, _ = c.get("b")
+	c.set("b", 3, generation)
+	if count, _, ok := c.get("b"); !ok || count != 3 {
+		t.Errorf("get() = %d, %v, want 3, true", count, ok)
+	}
+}
+
+func TestCountCache_BoundedSize(t *testing.T) {
+	c, now := newTestCountCache(time.Minute)
+
+	for i := 0; i < maxCachedCounts; i++ {
+		c.set(fmt.Sprint(i), int64(i), 0)
+	}
+	*now = now.Add(time.Second)
+	c.set("new", 1, 0)
+	if len(c.counts) > maxCachedCounts {
+		t.Errorf("cache holds %d counts, want at most %d", len(c.counts), maxCachedCounts)
+	}
+	if _, _, ok := c.get("new"); !ok {
+		t.Error("newest count was not cached")
+	}
+}
+
+func TestCountCache_DisabledWithoutTTL(t *testing.T) {
+	c := NewCountCache(0)
+	if c != nil {
+		t.Fatal("NewCountCache(0) should disable the cache")
+	}
+
+	// A nil cache caches nothing
+	c.set("k", 1, 0)
+	c.invalidate()
+	if _, _, ok := c.get("k"); ok {
+		t.Error("nil cache returned a count")
+	}
+}
+
+func TestProductRepositoryList_ReusesCachedCount(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", NewCountCache(time.Minute))
+
+	expectList := func(counted bool) {
+		mock.ExpectBegin()
+		if counted {
+			mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+		}
+		mock.ExpectQuery(`SELECT \* FROM "products"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+	}
+
+	// Paging through the same filter counts once
+	expectList(true)
+	expectList(false)
+	// A write drops the cached count
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "products"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectList(true)
+
+	for i, page := range []int{1, 2} {
+		_, count, err := repo.List(context.Background(), entity.ProductFilter{Page: page, PageSize: 10})
+		if err != nil || count != 25 {
+			t.Fatalf("List() #%d = %d, %v, want 25", i+1, count, err)
+		}
+	}
+	if err := repo.Delete(context.Background(), 9); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, count, err := repo.List(context.Background(), entity.ProductFilter{Page: 1, PageSize: 10}); err != nil || count != 25 {
+		t.Fatalf("List() after a write = %d, %v, want 25", count, err)
+	}
+}
+
+func TestCountCache_InvalidatedByOtherProductWrites(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		write  func(db *Database, counts *CountCache) error
+	}{
+		{
+			name: "price schedule applied",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM "scheduled_price_changes" WHERE .* FOR UPDATE`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "new_price", "status"}).
+						AddRow(1, 10, 9.99, entity.ScheduleStatusPending))
+				mock.ExpectQuery(`SELECT products.id,products.price FROM "products"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "price"}).AddRow(10, 12.5))
+				mock.ExpectExec(`INSERT INTO "scheduled_price_change_items"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "products" SET "price"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "scheduled_price_changes" SET "status"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			write: func(db *Database, counts *CountCache) error {
+				return NewPriceScheduleRepository(db, testLogger(), counts).Apply(context.Background(), 1)
+			},
+		},
+		{
+			name: "price schedule reverted",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM "scheduled_price_changes" WHERE .* FOR UPDATE`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "new_price", "status"}).
+						AddRow(1, 10, 9.99, entity.ScheduleStatusApplied))
+				mock.ExpectExec(`UPDATE products p\s+SET price = i.original_price`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "scheduled_price_changes" SET "status"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			write: func(db *Database, counts *CountCache) error {
+				return NewPriceScheduleRepository(db, testLogger(), counts).Revert(context.Background(), 1)
+			},
+		},
+		{
+			name: "reservations released",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectExpiredReservations(mock, now, sqlmock.NewRows([]string{"id", "product_id", "quantity"}).
+					AddRow(1, 10, 2))
+				mock.ExpectExec(`UPDATE products p\s+SET stock_quantity`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE "stock_reservations" SET "released_at"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			write: func(db *Database, counts *CountCache) error {
+				_, err := NewStockReservationRepository(db, testLogger(), counts).ReleaseExpired(context.Background(), now)
+				return err
+			},
+		},
+		{
+			name: "category disabled",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "categories" SET "disabled"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			write: func(db *Database, counts *CountCache) error {
+				return NewCategoryRepository(db, testLogger(), counts).SetDisabled(context.Background(), 3, true)
+			},
+		},
+		{
+			name: "category deleted",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM product_categories WHERE category_id = \$1`).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`DELETE FROM "categories"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			write: func(db *Database, counts *CountCache) error {
+				return NewCategoryRepository(db, testLogger(), counts).Delete(context.Background(), 3, nil, true)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDatabase(t)
+			counts, _ := newTestCountCache(time.Minute)
+			_, generation, _ := counts.get("k")
+			counts.set("k", 25, generation)
+
+			tt.expect(mock)
+			if err := tt.write(db, counts); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, ok := counts.get("k"); ok {
+				t.Error("the cached count survived the write")
+			}
+		})
+	}
+}
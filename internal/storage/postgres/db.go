@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -36,6 +37,7 @@ func NewPostgresDB(dsn string, maxOpenConns, minOpenConns int, timeout time.Dura
 		NamingStrategy: schema.NamingStrategy{
 			SingularTable: true,
 		},
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -62,6 +64,20 @@ func (d *Database) WithContext(ctx context.Context) *gorm.DB {
 	return d.DB.WithContext(ctx)
 }
 
+// Pool returns the underlying connection pool, e.g. to export its statistics
+func (d *Database) Pool() (*sql.DB, error) {
+	return d.DB.DB()
+}
+
+// Ping checks that the database is reachable
+func (d *Database) Ping(ctx context.Context) error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 // Close closes the database connection
 func (d *Database) Close() error {
 	sqlDB, err := d.DB.DB()
@@ -80,6 +96,11 @@ func (d *Database) AutoMigrate() error {
 		&Category{},
 		&Review{},
 		&Wishlist{},
+		&ScheduledPriceChange{},
+		&ScheduledPriceChangeItem{},
+		&PriceAlert{},
+		&PriceAudit{},
+		&StockReservation{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate: %w", err)
@@ -88,14 +109,18 @@ func (d *Database) AutoMigrate() error {
 	return nil
 }
 
-// Seed seeds the database with initial data
-func (db *Database) Seed() error {
+// Seed seeds the database with initial data. The default admin has a
+// well-known password, so it is never created in production, where the first
+// admin comes from the bootstrap password instead.
+func (db *Database) Seed(environment string) error {
 	db.logger.Info("Seeding database with initial data")
 
 	// Check if admin user exists
 	var adminCount int64
 	db.DB.Model(&User{}).Where("role = ?", "admin").Count(&adminCount)
-	if adminCount == 0 {
+	if adminCount == 0 && environment == "production" {
+		db.logger.Warn("Refusing to seed the default admin user in production")
+	} else if adminCount == 0 {
 		admin := User{
 			Username:     "admin",
 			Email:        "admin@example.com",
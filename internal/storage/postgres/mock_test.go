@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// newMockDatabase returns a Database backed by sqlmock. Queries are matched
+// as regular expressions and every expectation must be met by the end of the test.
+func newMockDatabase(t testing.TB) (*Database, sqlmock.Sqlmock) {
+	t.Helper()
+	return newMockDatabaseWith(t, func(sqlDB *sql.DB) gorm.ConnPool { return sqlDB })
+}
+
+// newMockDatabaseWith is newMockDatabase with the sqlmock connection pool
+// wrapped by wrap, for tests that observe how the pool is used
+func newMockDatabaseWith(t testing.TB, wrap func(*sql.DB) gorm.ConnPool) (*Database, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: wrap(sqlDB)}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+		TranslateError: true,
+		Logger:         gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet database expectations: %v", err)
+		}
+	})
+
+	return &Database{DB: db, logger: testLogger()}, mock
+}
+
+// testLogger returns a logger that only reports errors
+func testLogger() *logger.Logger {
+	return logger.NewLogger("error", "text", "stdout")
+}
@@ -8,24 +8,28 @@ import (
 
 // User represents a user in the database
 type User struct {
-	ID           uint      `gorm:"primaryKey"`
-	Username     string    `gorm:"uniqueIndex;size:255;not null"`
-	Email        string    `gorm:"uniqueIndex;size:255;not null"`
-	PasswordHash string    `gorm:"size:255;not null"`
-	FullName     string    `gorm:"size:255"`
-	Role         string    `gorm:"size:50;default:user"`
-	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP"`
-	UpdatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	ID                 uint      `gorm:"primaryKey"`
+	Username           string    `gorm:"uniqueIndex;size:255;not null"`
+	Email              string    `gorm:"uniqueIndex;size:255;not null"`
+	PasswordHash       string    `gorm:"size:255;not null"`
+	FullName           string    `gorm:"size:255"`
+	Role               string    `gorm:"size:50;default:user"`
+	MustChangePassword bool      `gorm:"not null;default:false"`
+	CreatedAt          time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt          time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
 // Product represents a product in the database
 type Product struct {
 	ID            uint    `gorm:"primaryKey"`
 	Name          string  `gorm:"size:255;not null"`
+	SKU           *string `gorm:"column:sku;size:64;uniqueIndex"`
 	Description   string  `gorm:"type:text"`
 	Price         float64 `gorm:"type:decimal(10,2)"`
 	StockQuantity int
-	Status        string     `gorm:"size:50;default:active"`
+	Status        string `gorm:"size:50;default:active"`
+	Archived      bool   `gorm:"not null;default:false;index"`
+	ArchivedAt    *time.Time
 	Categories    []Category `gorm:"many2many:product_categories;"`
 	Reviews       []Review   `gorm:"foreignKey:ProductID"`
 	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
@@ -34,19 +38,23 @@ type Product struct {
 
 // Category represents a product category in the database
 type Category struct {
-	ID          uint      `gorm:"primaryKey"`
-	Name        string    `gorm:"size:255;not null"`
-	Description string    `gorm:"type:text"`
-	Products    []Product `gorm:"many2many:product_categories;"`
+	ID          uint       `gorm:"primaryKey"`
+	Name        string     `gorm:"size:255;not null"`
+	Description string     `gorm:"type:text"`
+	Disabled    bool       `gorm:"not null;default:false"`
+	ParentID    *uint      `gorm:"index"`
+	Children    []Category `gorm:"foreignKey:ParentID"`
+	Products    []Product  `gorm:"many2many:product_categories;"`
 }
 
 // Review represents a product review in the database
 type Review struct {
 	ID        uint      `gorm:"primaryKey"`
-	ProductID uint      `gorm:"not null"`
-	UserID    uint      `gorm:"not null"`
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_reviews_user_product"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_reviews_user_product"`
 	Rating    int       `gorm:"not null;check:rating >= 1 AND rating <= 5"`
 	Comment   string    `gorm:"type:text"`
+	Approved  bool      `gorm:"not null;default:true"`
 	User      User      `gorm:"foreignKey:UserID"`
 	Product   Product   `gorm:"foreignKey:ProductID"`
 	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
@@ -62,6 +70,62 @@ type Wishlist struct {
 	Product   Product   `gorm:"foreignKey:ProductID"`
 }
 
+// ScheduledPriceChange represents a scheduled price change in the database
+type ScheduledPriceChange struct {
+	ID          uint      `gorm:"primaryKey"`
+	ProductID   *uint     `gorm:"index"`
+	CategoryID  *uint     `gorm:"index"`
+	NewPrice    float64   `gorm:"type:decimal(10,2);not null"`
+	EffectiveAt time.Time `gorm:"not null"`
+	RevertAt    *time.Time
+	Status      string                     `gorm:"size:50;default:pending"`
+	Items       []ScheduledPriceChangeItem `gorm:"foreignKey:ScheduleID"`
+	CreatedAt   time.Time                  `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time                  `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// ScheduledPriceChangeItem records a product's price before a scheduled change was applied
+type ScheduledPriceChangeItem struct {
+	ScheduleID    uint    `gorm:"primaryKey;autoIncrement:false"`
+	ProductID     uint    `gorm:"primaryKey;autoIncrement:false"`
+	OriginalPrice float64 `gorm:"type:decimal(10,2);not null"`
+}
+
+// PriceAlert represents a user's price-drop subscription in the database
+type PriceAlert struct {
+	ID         uint    `gorm:"primaryKey"`
+	UserID     uint    `gorm:"not null;uniqueIndex:idx_price_alerts_user_product"`
+	ProductID  uint    `gorm:"not null;uniqueIndex:idx_price_alerts_user_product"`
+	Threshold  float64 `gorm:"type:decimal(10,2);not null"`
+	NotifiedAt *time.Time
+	User       User      `gorm:"foreignKey:UserID"`
+	Product    Product   `gorm:"foreignKey:ProductID"`
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// PriceAudit records a bulk price update in the database
+type PriceAudit struct {
+	ID             uint    `gorm:"primaryKey"`
+	UserID         *uint   `gorm:"index"`
+	AdjustmentType string  `gorm:"size:20;not null"`
+	Value          float64 `gorm:"type:decimal(10,2);not null"`
+	MinPrice       float64 `gorm:"type:decimal(10,2);not null"`
+	Updated        int     `gorm:"not null"`
+	// Changes holds the price changes as JSON
+	Changes   string    `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// StockReservation represents stock held for a product until it expires
+type StockReservation struct {
+	ID         uint      `gorm:"primaryKey"`
+	ProductID  uint      `gorm:"not null;index"`
+	Quantity   int       `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	ReleasedAt *time.Time
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
 // TableNames
 func (User) TableName() string {
 	return "users"
@@ -83,6 +147,22 @@ func (Wishlist) TableName() string {
 	return "wishlist"
 }
 
+func (ScheduledPriceChange) TableName() string {
+	return "scheduled_price_changes"
+}
+
+func (ScheduledPriceChangeItem) TableName() string {
+	return "scheduled_price_change_items"
+}
+
+func (PriceAlert) TableName() string {
+	return "price_alerts"
+}
+
+func (PriceAudit) TableName() string {
+	return "price_audits"
+}
+
 // BeforeCreate hooks
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.Role == "" {
@@ -106,3 +186,14 @@ func (r *Review) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func (s *ScheduledPriceChange) BeforeCreate(tx *gorm.DB) error {
+	if s.Status == "" {
+		s.Status = "pending"
+	}
+	return nil
+}
+
+func (StockReservation) TableName() string {
+	return "stock_reservations"
+}
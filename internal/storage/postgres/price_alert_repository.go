@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	"gorm.io/gorm/clause"
+)
+
+// PriceAlertRepository implements storage.PriceAlertRepository
+type PriceAlertRepository struct {
+	db     *Database
+	logger *logger.Logger
+}
+
+// NewPriceAlertRepository creates a new PriceAlertRepository
+func NewPriceAlertRepository(db *Database, logger *logger.Logger) *PriceAlertRepository {
+	return &PriceAlertRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Subscribe creates a price alert, or updates the threshold and re-arms an
+// existing alert for the same user and product
+func (r *PriceAlertRepository) Subscribe(ctx context.Context, alert *entity.PriceAlert) error {
+	if err := checkOwner(ctx, alert.UserID); err != nil {
+		return err
+	}
+
+	model := &PriceAlert{
+		UserID:    alert.UserID,
+		ProductID: alert.ProductID,
+		Threshold: alert.Threshold,
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "product_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"threshold":   alert.Threshold,
+			"notified_at": nil,
+		}),
+	}).Create(model).Error
+	if err != nil {
+		return err
+	}
+
+	// Update the entity with the generated fields
+	alert.ID = model.ID
+	alert.NotifiedAt = nil
+	alert.CreatedAt = model.CreatedAt
+
+	return nil
+}
+
+// Unsubscribe removes a user's price alert for a product
+func (r *PriceAlertRepository) Unsubscribe(ctx context.Context, userID, productID uint) error {
+	return r.db.WithContext(ctx).
+		Scopes(ownedBy(ctx, "user_id")).
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		Delete(&PriceAlert{}).Error
+}
+
+// ListByUser lists a user's price alerts
+func (r *PriceAlertRepository) ListByUser(ctx context.Context, userID uint) ([]entity.PriceAlert, error) {
+	var models []PriceAlert
+	err := r.db.WithContext(ctx).
+		Scopes(ownedBy(ctx, "user_id")).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	alerts := make([]entity.PriceAlert, len(models))
+	for i, model := range models {
+		alerts[i] = toPriceAlertEntity(model)
+	}
+
+	return alerts, nil
+}
+
+// FindTriggered finds the alerts for a product whose threshold the given
+// price has reached and that haven't been notified yet
+func (r *PriceAlertRepository) FindTriggered(ctx context.Context, productID uint, price float64) ([]entity.PriceAlert, error) {
+	var models []PriceAlert
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("product_id = ? AND threshold >= ? AND notified_at IS NULL", productID, price).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	alerts := make([]entity.PriceAlert, len(models))
+	for i, model := range models {
+		alerts[i] = toPriceAlertEntity(model)
+		alerts[i].UserEmail = model.User.Email
+	}
+
+	return alerts, nil
+}
+
+// MarkNotified marks alerts as notified so they don't fire again
+func (r *PriceAlertRepository) MarkNotified(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&PriceAlert{}).
+		Where("id IN ?", ids).
+		Update("notified_at", time.Now()).Error
+}
+
+// toPriceAlertEntity maps a PriceAlert model to an entity
+func toPriceAlertEntity(model PriceAlert) entity.PriceAlert {
+	return entity.PriceAlert{
+		ID:         model.ID,
+		UserID:     model.UserID,
+		ProductID:  model.ProductID,
+		Threshold:  model.Threshold,
+		NotifiedAt: model.NotifiedAt,
+		CreatedAt:  model.CreatedAt,
+	}
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/ctxutil"
+)
+
+func TestSubscribe_RefusesAnotherUsersAlert(t *testing.T) {
+	db, _ := newMockDatabase(t)
+	repo := NewPriceAlertRepository(db, testLogger())
+	ctx := ctxutil.WithUserID(context.Background(), 5)
+
+	// No query is expected, so any insert fails the test
+	alert := &entity.PriceAlert{UserID: 6, ProductID: 9, Threshold: 10}
+	if err := repo.Subscribe(ctx, alert); !errors.Is(err, storage.ErrNotOwner) {
+		t.Errorf("Subscribe() error = %v, want %v", err, storage.ErrNotOwner)
+	}
+}
+
+func TestSubscribe_ContextUser(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewPriceAlertRepository(db, testLogger())
+	ctx := ctxutil.WithUserID(context.Background(), 5)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "price_alerts" .* ON CONFLICT \("user_id","product_id"\) DO UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+	mock.ExpectCommit()
+
+	alert := &entity.PriceAlert{UserID: 5, ProductID: 9, Threshold: 10}
+	if err := repo.Subscribe(ctx, alert); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if alert.ID != 3 {
+		t.Errorf("alert ID = %d, want 3", alert.ID)
+	}
+}
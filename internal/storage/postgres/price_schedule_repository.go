@@ -0,0 +1,266 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PriceScheduleRepository implements storage.PriceScheduleRepository
+type PriceScheduleRepository struct {
+	db     *Database
+	logger *logger.Logger
+	counts *CountCache
+}
+
+// NewPriceScheduleRepository creates a new PriceScheduleRepository. Writes to products
+// invalidate the product counts cached in counts, which may be nil.
+func NewPriceScheduleRepository(db *Database, logger *logger.Logger, counts *CountCache) *PriceScheduleRepository {
+	return &PriceScheduleRepository{
+		db:     db,
+		logger: logger,
+		counts: counts,
+	}
+}
+
+// Create creates a new scheduled price change
+func (r *PriceScheduleRepository) Create(ctx context.Context, schedule *entity.ScheduledPriceChange) error {
+	model := &ScheduledPriceChange{
+		ProductID:   schedule.ProductID,
+		CategoryID:  schedule.CategoryID,
+		NewPrice:    schedule.NewPrice,
+		EffectiveAt: schedule.EffectiveAt,
+		RevertAt:    schedule.RevertAt,
+		Status:      schedule.Status,
+	}
+
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return err
+	}
+
+	// Update the entity with the generated fields
+	schedule.ID = model.ID
+	schedule.Status = model.Status
+	schedule.CreatedAt = model.CreatedAt
+	schedule.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// List lists all scheduled price changes ordered by effective time
+func (r *PriceScheduleRepository) List(ctx context.Context) ([]entity.ScheduledPriceChange, error) {
+	var models []ScheduledPriceChange
+	if err := r.db.WithContext(ctx).Order("effective_at ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	schedules := make([]entity.ScheduledPriceChange, len(models))
+	for i, model := range models {
+		schedules[i] = toScheduleEntity(model)
+	}
+
+	return schedules, nil
+}
+
+// FindByID finds a scheduled price change by ID
+func (r *PriceScheduleRepository) FindByID(ctx context.Context, id uint) (*entity.ScheduledPriceChange, error) {
+	var model ScheduledPriceChange
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	schedule := toScheduleEntity(model)
+	return &schedule, nil
+}
+
+// Update updates a scheduled price change
+func (r *PriceScheduleRepository) Update(ctx context.Context, schedule *entity.ScheduledPriceChange) error {
+	var model ScheduledPriceChange
+	if err := r.db.WithContext(ctx).First(&model, schedule.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Update fields
+	model.ProductID = schedule.ProductID
+	model.CategoryID = schedule.CategoryID
+	model.NewPrice = schedule.NewPrice
+	model.EffectiveAt = schedule.EffectiveAt
+	model.RevertAt = schedule.RevertAt
+
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		return err
+	}
+
+	// Update the entity
+	schedule.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// Delete deletes a scheduled price change
+func (r *PriceScheduleRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&ScheduledPriceChange{}, id).Error
+}
+
+// FindDue finds pending schedules whose effective time has passed and
+// applied schedules whose revert time has passed
+func (r *PriceScheduleRepository) FindDue(ctx context.Context, now time.Time) ([]entity.ScheduledPriceChange, error) {
+	var models []ScheduledPriceChange
+	err := r.db.WithContext(ctx).
+		Where("(status = ? AND effective_at <= ?) OR (status = ? AND revert_at <= ?)",
+			entity.ScheduleStatusPending, now, entity.ScheduleStatusApplied, now).
+		Order("effective_at ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	schedules := make([]entity.ScheduledPriceChange, len(models))
+	for i, model := range models {
+		schedules[i] = toScheduleEntity(model)
+	}
+
+	return schedules, nil
+}
+
+// Apply sets the scheduled price on every targeted product, remembering the
+// original prices. Applying a schedule that is no longer pending is a no-op.
+func (r *PriceScheduleRepository) Apply(ctx context.Context, id uint) error {
+	defer r.counts.invalidate()
+
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the schedule so concurrent schedulers can't apply it twice
+	var schedule ScheduledPriceChange
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&schedule, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if schedule.Status != entity.ScheduleStatusPending {
+		return tx.Rollback().Error
+	}
+
+	// Find the targeted products
+	query := tx.Model(&Product{}).Clauses(clause.Locking{
+		Strength: "UPDATE",
+		Table:    clause.Table{Name: "products"},
+	})
+	if schedule.ProductID != nil {
+		query = query.Where("products.id = ?", *schedule.ProductID)
+	} else {
+		query = query.Joins("JOIN product_categories pc ON products.id = pc.product_id").
+			Where("pc.category_id = ?", *schedule.CategoryID)
+	}
+
+	var products []Product
+	if err := query.Select("products.id", "products.price").Find(&products).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, p := range products {
+		// Remember the original price
+		item := ScheduledPriceChangeItem{
+			ScheduleID:    schedule.ID,
+			ProductID:     p.ID,
+			OriginalPrice: p.Price,
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Model(&Product{}).Where("id = ?", p.ID).Update("price", schedule.NewPrice).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&schedule).Update("status", entity.ScheduleStatusApplied).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Revert restores the original prices recorded when the schedule was applied.
+// Products whose price was changed by someone else in the meantime are left
+// untouched. Reverting a schedule that isn't applied is a no-op.
+func (r *PriceScheduleRepository) Revert(ctx context.Context, id uint) error {
+	defer r.counts.invalidate()
+
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the schedule so concurrent schedulers can't revert it twice
+	var schedule ScheduledPriceChange
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&schedule, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if schedule.Status != entity.ScheduleStatusApplied {
+		return tx.Rollback().Error
+	}
+
+	err := tx.Exec(`
+		UPDATE products p
+		SET price = i.original_price
+		FROM scheduled_price_change_items i
+		WHERE i.schedule_id = ? AND p.id = i.product_id AND p.price = ?`,
+		schedule.ID, schedule.NewPrice).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&schedule).Update("status", entity.ScheduleStatusReverted).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// toScheduleEntity maps a ScheduledPriceChange model to an entity
+func toScheduleEntity(model ScheduledPriceChange) entity.ScheduledPriceChange {
+	return entity.ScheduledPriceChange{
+		ID:          model.ID,
+		ProductID:   model.ProductID,
+		CategoryID:  model.CategoryID,
+		NewPrice:    model.NewPrice,
+		EffectiveAt: model.EffectiveAt,
+		RevertAt:    model.RevertAt,
+		Status:      model.Status,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
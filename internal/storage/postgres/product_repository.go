@@ -2,50 +2,48 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
 	"github.com/thanhnguyen/product-api/pkg/logger"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ProductRepository implements storage.ProductRepository
 type ProductRepository struct {
-	db           *Database
-	logger       *logger.Logger
-	productPool  *sync.Pool
-	categoryPool *sync.Pool
+	db        *Database
+	logger    *logger.Logger
+	skuPrefix string
+	counts    *CountCache
 }
 
-// NewProductRepository creates a new ProductRepository
-func NewProductRepository(db *Database, logger *logger.Logger) *ProductRepository {
+// NewProductRepository creates a new ProductRepository. Products created
+// without a SKU get skuPrefix followed by their ID. List reuses the product
+// counts cached in counts, which may be nil.
+func NewProductRepository(db *Database, logger *logger.Logger, skuPrefix string, counts *CountCache) *ProductRepository {
 	return &ProductRepository{
-		db:     db,
-		logger: logger,
-		productPool: &sync.Pool{
-			New: func() interface{} {
-				return &Product{}
-			},
-		},
-		categoryPool: &sync.Pool{
-			New: func() interface{} {
-				return &Category{}
-			},
-		},
+		db:        db,
+		logger:    logger,
+		skuPrefix: skuPrefix,
+		counts:    counts,
 	}
 }
 
 // Create creates a new product
 func (r *ProductRepository) Create(ctx context.Context, product *entity.Product) error {
-	// Get a model instance from the pool
-	model := r.productPool.Get().(*Product)
-	defer r.productPool.Put(model)
+	defer r.counts.invalidate()
 
-	// Reset fields to avoid data leakage
-	*model = Product{
+	model := &Product{
 		Name:          product.Name,
+		SKU:           optionalString(product.SKU),
 		Description:   product.Description,
 		Price:         product.Price,
 		StockQuantity: product.StockQuantity,
@@ -66,9 +64,25 @@ func (r *ProductRepository) Create(ctx context.Context, product *entity.Product)
 	// Create the product
 	if err := tx.Create(model).Error; err != nil {
 		tx.Rollback()
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return storage.ErrDuplicateKey
+		}
 		return err
 	}
 
+	// Generate a SKU from the ID if none was given
+	if model.SKU == nil {
+		sku := fmt.Sprintf("%s%d", r.skuPrefix, model.ID)
+		if err := tx.Model(model).Update("sku", sku).Error; err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return storage.ErrDuplicateKey
+			}
+			return err
+		}
+		model.SKU = &sku
+	}
+
 	// Add categories
 	if len(product.Categories) > 0 {
 		for _, cat := range product.Categories {
@@ -84,56 +98,56 @@ func (r *ProductRepository) Create(ctx context.Context, product *entity.Product)
 		return err
 	}
 
-	// Update the entity with the generated ID
+	// Update the entity with the generated ID and SKU
 	product.ID = model.ID
+	product.SKU = stringValue(model.SKU)
 	product.CreatedAt = model.CreatedAt
 	product.UpdatedAt = model.UpdatedAt
 
 	return nil
 }
 
-// List lists products with filtering and pagination
+// List lists products with filtering and pagination. The count and the page
+// are read in one repeatable-read transaction so they agree with each other,
+// unless the count of the filter is cached; the page is always read fresh.
+// Products are ordered by the sort column and then by ID, so the order is
+// total. With a cursor the page starts right after it instead of at an offset,
+// which never skips or repeats a product however many are inserted between
+// requests; products inserted later show up only if they sort after the cursor.
 func (r *ProductRepository) List(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, int64, error) {
 	var (
 		products []Product
 		count    int64
-		wg       sync.WaitGroup
-		countErr error
-		listErr  error
-		mu       sync.Mutex
 	)
 
-	// Build query
-	query := r.db.WithContext(ctx).Model(&Product{})
-
-	// Apply filters
-	if filter.Search != "" {
-		searchTerm := "%" + strings.ToLower(filter.Search) + "%"
-		query = query.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", searchTerm, searchTerm)
-	}
-
-	if filter.CategoryID != 0 {
-		query = query.Joins("JOIN product_categories pc ON products.id = pc.product_id").
-			Where("pc.category_id = ?", filter.CategoryID)
-	}
-
-	if filter.MinPrice != nil {
-		query = query.Where("price >= ?", *filter.MinPrice)
+	// Start a read-only transaction so both queries see the same snapshot
+	tx := r.db.WithContext(ctx).Begin(&sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if tx.Error != nil {
+		return nil, 0, tx.Error
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
 
-	if filter.MaxPrice != nil {
-		query = query.Where("price <= ?", *filter.MaxPrice)
-	}
+	// Build query. Start a new session so the count and the page query don't share a statement.
+	query := applyProductFilter(tx.Model(&Product{}), filter).Session(&gorm.Session{})
 
-	// Count total in a goroutine
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		q := query
-		if countErr = q.Count(&count).Error; countErr != nil {
-			r.logger.WithError(countErr).Error("Failed to count products")
+	// Count total, unless the count of the filter is cached
+	key := countKey(filter)
+	count, generation, cached := r.counts.get(key)
+	if !cached {
+		if err := query.Count(&count).Error; err != nil {
+			tx.Rollback()
+			r.logger.WithError(err).Error("Failed to count products")
+			return nil, 0, err
 		}
-	}()
+		r.counts.set(key, count, generation)
+	}
 
 	// Apply pagination
 	pageSize := filter.PageSize
@@ -144,92 +158,196 @@ func (r *ProductRepository) List(ctx context.Context, filter entity.ProductFilte
 	if page <= 0 {
 		page = 1
 	}
-	offset := (page - 1) * pageSize
-
-	// Apply sorting
-	if filter.SortBy != "" {
-		order := "ASC"
-		if filter.SortOrder == "desc" {
-			order = "DESC"
+	if filter.After != nil {
+		after, err := keysetCondition(filter.After, filter)
+		if err != nil {
+			tx.Rollback()
+			return nil, 0, err
 		}
-		query = query.Order(filter.SortBy + " " + order)
+		query = query.Where(after)
 	} else {
-		query = query.Order("id DESC")
+		query = query.Offset((page - 1) * pageSize)
 	}
 
-	// Get products in a goroutine
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		q := query
-		if listErr = q.Offset(offset).Limit(pageSize).Find(&products).Error; listErr != nil {
-			r.logger.WithError(listErr).Error("Failed to list products")
-		}
-	}()
+	// Apply sorting, breaking ties by ID. Unknown columns fall back to the default order.
+	column, desc := productOrder(filter)
+	if column != "id" {
+		query = query.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: "products", Name: column},
+			Desc:   desc,
+		})
+	}
+	query = query.Order(clause.OrderByColumn{
+		Column: clause.Column{Table: "products", Name: "id"},
+		Desc:   desc,
+	})
+
+	// Get products
+	if err := query.Limit(pageSize).Find(&products).Error; err != nil {
+		tx.Rollback()
+		r.logger.WithError(err).Error("Failed to list products")
+		return nil, 0, err
+	}
 
-	// Wait for both goroutines to finish
-	wg.Wait()
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, 0, err
+	}
 
-	// Check for errors
-	if countErr != nil {
-		return nil, 0, countErr
+	// Load the categories of the whole page at once. A failure leaves the
+	// products without categories rather than failing the whole list.
+	ids := make([]uint, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
 	}
-	if listErr != nil {
-		return nil, 0, listErr
+	categories, err := r.loadCategories(ctx, ids)
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to load product categories")
 	}
 
-	// Map to entities with goroutines
+	// Map to entities
 	result := make([]entity.Product, len(products))
-	if len(products) > 0 {
-		wg = sync.WaitGroup{}
-		wg.Add(len(products))
-
-		for i, p := range products {
-			go func(i int, p Product) {
-				defer wg.Done()
-
-				// Map product
-				product := entity.Product{
-					ID:            p.ID,
-					Name:          p.Name,
-					Description:   p.Description,
-					Price:         p.Price,
-					StockQuantity: p.StockQuantity,
-					Status:        p.Status,
-					CreatedAt:     p.CreatedAt,
-					UpdatedAt:     p.UpdatedAt,
-				}
-
-				// Get categories
-				var categories []Category
-				if err := r.db.WithContext(ctx).Model(&p).Association("Categories").Find(&categories); err == nil {
-					for _, c := range categories {
-						product.Categories = append(product.Categories, entity.Category{
-							ID:          c.ID,
-							Name:        c.Name,
-							Description: c.Description,
-						})
-					}
-				}
-
-				// Store in result
-				mu.Lock()
-				result[i] = product
-				mu.Unlock()
-			}(i, p)
+	for i, p := range products {
+		result[i] = entity.Product{
+			ID:            p.ID,
+			Name:          p.Name,
+			SKU:           stringValue(p.SKU),
+			Description:   p.Description,
+			Price:         p.Price,
+			StockQuantity: p.StockQuantity,
+			Status:        p.Status,
+			Archived:      p.Archived,
+			ArchivedAt:    p.ArchivedAt,
+			Categories:    categories[p.ID],
+			CreatedAt:     p.CreatedAt,
+			UpdatedAt:     p.UpdatedAt,
 		}
+	}
 
-		wg.Wait()
+	// Products of a canceled request would be missing their categories
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
 	}
 
 	return result, count, nil
 }
 
+// loadCategories loads the categories of the given products in a single query,
+// grouped by product ID
+func (r *ProductRepository) loadCategories(ctx context.Context, productIDs []uint) (map[uint][]entity.Category, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	var rows []struct {
+		ProductID   uint
+		ID          uint
+		Name        string
+		Description string
+	}
+	err := r.db.WithContext(ctx).
+		Table("product_categories pc").
+		Select("pc.product_id, c.id, c.name, c.description").
+		Joins("JOIN categories c ON c.id = pc.category_id").
+		Where("pc.product_id IN ?", productIDs).
+		Order("c.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[uint][]entity.Category, len(productIDs))
+	for _, row := range rows {
+		categories[row.ProductID] = append(categories[row.ProductID], entity.Category{
+			ID:          row.ID,
+			Name:        row.Name,
+			Description: row.Description,
+		})
+	}
+	return categories, nil
+}
+
+// productSortColumns are the columns products can be sorted by. Sort columns
+// come from the query string, so nothing else may reach the ORDER BY clause.
+var productSortColumns = map[string]bool{
+	"id":             true,
+	"name":           true,
+	"price":          true,
+	"created_at":     true,
+	"stock_quantity": true,
+}
+
+// productOrder returns the column products are sorted by and whether the order
+// is descending. Without a known sort column products are listed newest first.
+func productOrder(filter entity.ProductFilter) (string, bool) {
+	if !productSortColumns[filter.SortBy] {
+		return "id", true
+	}
+	return filter.SortBy, filter.SortOrder == "desc"
+}
+
+// keysetCondition returns the condition selecting the products that sort after
+// the cursor in the filter's order
+func keysetCondition(cursor *entity.ProductCursor, filter entity.ProductFilter) (clause.Expr, error) {
+	if cursor.SortBy != filter.SortBy || cursor.SortOrder != filter.SortOrder {
+		return clause.Expr{}, entity.ErrInvalidCursor
+	}
+	column, desc := productOrder(filter)
+	op := ">"
+	if desc {
+		op = "<"
+	}
+
+	if column == "id" {
+		return clause.Expr{SQL: "products.id " + op + " ?", Vars: []interface{}{cursor.ID}}, nil
+	}
+	value, err := cursor.SortKey()
+	if err != nil {
+		return clause.Expr{}, entity.ErrInvalidCursor
+	}
+	// column is one of productSortColumns, so it is safe to build into the SQL
+	return clause.Expr{
+		SQL:  "(products." + column + ", products.id) " + op + " (?, ?)",
+		Vars: []interface{}{value, cursor.ID},
+	}, nil
+}
+
+// applyProductFilter applies the search, category, price and creation time
+// filters to a product query. Archived products are excluded unless the filter
+// includes them.
+func applyProductFilter(query *gorm.DB, filter entity.ProductFilter) *gorm.DB {
+	if filter.Search != "" {
+		searchTerm := "%" + strings.ToLower(filter.Search) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", searchTerm, searchTerm)
+	}
+
+	if filter.CategoryID != 0 {
+		query = query.Joins("JOIN product_categories pc ON products.id = pc.product_id").
+			Where("pc.category_id = ?", filter.CategoryID)
+	}
+
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+
+	if filter.CreatedAfter != nil {
+		query = query.Where("products.created_at >= ?", *filter.CreatedAfter)
+	}
+
+	if !filter.IncludeArchived {
+		query = query.Where("products.archived = ?", false)
+	}
+
+	return query
+}
+
 // FindByID finds a product by ID
 func (r *ProductRepository) FindByID(ctx context.Context, id uint) (*entity.Product, error) {
-	// Get a model instance from the pool
-	model := r.productPool.Get().(*Product)
-	defer r.productPool.Put(model)
+	model := &Product{}
 
 	// Find the product
 	if err := r.db.WithContext(ctx).First(model, id).Error; err != nil {
@@ -243,10 +361,13 @@ func (r *ProductRepository) FindByID(ctx context.Context, id uint) (*entity.Prod
 	product := &entity.Product{
 		ID:            model.ID,
 		Name:          model.Name,
+		SKU:           stringValue(model.SKU),
 		Description:   model.Description,
 		Price:         model.Price,
 		StockQuantity: model.StockQuantity,
 		Status:        model.Status,
+		Archived:      model.Archived,
+		ArchivedAt:    model.ArchivedAt,
 		CreatedAt:     model.CreatedAt,
 		UpdatedAt:     model.UpdatedAt,
 	}
@@ -266,11 +387,102 @@ func (r *ProductRepository) FindByID(ctx context.Context, id uint) (*entity.Prod
 	return product, nil
 }
 
+// FindBySKU finds a product by SKU
+func (r *ProductRepository) FindBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	var model Product
+	if err := r.db.WithContext(ctx).Preload("Categories").Where("sku = ?", sku).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Map model to entity
+	product := &entity.Product{
+		ID:            model.ID,
+		Name:          model.Name,
+		SKU:           stringValue(model.SKU),
+		Description:   model.Description,
+		Price:         model.Price,
+		StockQuantity: model.StockQuantity,
+		Status:        model.Status,
+		Archived:      model.Archived,
+		ArchivedAt:    model.ArchivedAt,
+		CreatedAt:     model.CreatedAt,
+		UpdatedAt:     model.UpdatedAt,
+	}
+	for _, c := range model.Categories {
+		product.Categories = append(product.Categories, entity.Category{
+			ID:          c.ID,
+			Name:        c.Name,
+			Description: c.Description,
+		})
+	}
+
+	return product, nil
+}
+
+// optionalString maps an empty string to NULL
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// stringValue maps NULL to an empty string
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// FindByIDs finds products by IDs along with their categories. Missing IDs
+// are skipped and the order of the result is unspecified.
+func (r *ProductRepository) FindByIDs(ctx context.Context, ids []uint) ([]entity.Product, error) {
+	if len(ids) == 0 {
+		return []entity.Product{}, nil
+	}
+
+	var models []Product
+	if err := r.db.WithContext(ctx).Preload("Categories").Where("id IN ?", ids).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	products := make([]entity.Product, len(models))
+	for i, p := range models {
+		products[i] = entity.Product{
+			ID:            p.ID,
+			Name:          p.Name,
+			SKU:           stringValue(p.SKU),
+			Description:   p.Description,
+			Price:         p.Price,
+			StockQuantity: p.StockQuantity,
+			Status:        p.Status,
+			Archived:      p.Archived,
+			ArchivedAt:    p.ArchivedAt,
+			CreatedAt:     p.CreatedAt,
+			UpdatedAt:     p.UpdatedAt,
+		}
+		for _, c := range p.Categories {
+			products[i].Categories = append(products[i].Categories, entity.Category{
+				ID:          c.ID,
+				Name:        c.Name,
+				Description: c.Description,
+			})
+		}
+	}
+
+	return products, nil
+}
+
 // Update updates a product
 func (r *ProductRepository) Update(ctx context.Context, product *entity.Product) error {
-	// Get a model instance from the pool
-	model := r.productPool.Get().(*Product)
-	defer r.productPool.Put(model)
+	defer r.counts.invalidate()
+
+	model := &Product{}
 
 	// Find the product
 	if err := r.db.WithContext(ctx).First(model, product.ID).Error; err != nil {
@@ -280,8 +492,11 @@ func (r *ProductRepository) Update(ctx context.Context, product *entity.Product)
 		return err
 	}
 
-	// Update fields
+	// Update fields. An empty SKU keeps the current one.
 	model.Name = product.Name
+	if product.SKU != "" {
+		model.SKU = optionalString(product.SKU)
+	}
 	model.Description = product.Description
 	model.Price = product.Price
 	model.StockQuantity = product.StockQuantity
@@ -301,6 +516,9 @@ func (r *ProductRepository) Update(ctx context.Context, product *entity.Product)
 	// Update the product
 	if err := tx.Save(model).Error; err != nil {
 		tx.Rollback()
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return storage.ErrDuplicateKey
+		}
 		return err
 	}
 
@@ -332,13 +550,70 @@ func (r *ProductRepository) Update(ctx context.Context, product *entity.Product)
 	return nil
 }
 
+// SetArchived archives or unarchives a product. Archiving records when the
+// product was archived; unarchiving clears it.
+func (r *ProductRepository) SetArchived(ctx context.Context, id uint, archived bool) error {
+	defer r.counts.invalidate()
+
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+
+	return r.db.WithContext(ctx).
+		Model(&Product{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"archived":    archived,
+			"archived_at": archivedAt,
+		}).Error
+}
+
+// SetStatus changes a product's status
+func (r *ProductRepository) SetStatus(ctx context.Context, id uint, status string) error {
+	defer r.counts.invalidate()
+
+	return r.db.WithContext(ctx).
+		Model(&Product{}).
+		Where("id = ?", id).
+		Update("status", status).Error
+}
+
+// ListUpdateTimes returns when each product, archived or not, was last updated
+func (r *ProductRepository) ListUpdateTimes(ctx context.Context) (map[uint]time.Time, error) {
+	var rows []struct {
+		ID        uint
+		UpdatedAt time.Time
+	}
+	err := r.db.WithContext(ctx).
+		Model(&Product{}).
+		Select("id, updated_at").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		updated[row.ID] = row.UpdatedAt
+	}
+
+	return updated, nil
+}
+
 // Delete deletes a product
 func (r *ProductRepository) Delete(ctx context.Context, id uint) error {
+	defer r.counts.invalidate()
+
 	return r.db.WithContext(ctx).Delete(&Product{}, id).Error
 }
 
-// AddCategories adds categories to a product
+// AddCategories adds categories to a product. Adding a category the product
+// already has is a no-op.
 func (r *ProductRepository) AddCategories(ctx context.Context, productID uint, categoryIDs []uint) error {
+	defer r.counts.invalidate()
+
 	tx := r.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return tx.Error
@@ -350,7 +625,10 @@ func (r *ProductRepository) AddCategories(ctx context.Context, productID uint, c
 	}()
 
 	for _, categoryID := range categoryIDs {
-		if err := tx.Exec("INSERT INTO product_categories (product_id, category_id) VALUES (?, ?)", productID, categoryID).Error; err != nil {
+		err := tx.Exec(`
+			INSERT INTO product_categories (product_id, category_id) VALUES (?, ?)
+			ON CONFLICT (product_id, category_id) DO NOTHING`, productID, categoryID).Error
+		if err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -358,3 +636,237 @@ func (r *ProductRepository) AddCategories(ctx context.Context, productID uint, c
 
 	return tx.Commit().Error
 }
+
+// Search finds unarchived products whose name or description contains text, ranking
+// name matches above description-only matches. It returns one page of
+// products along with the total number of matches.
+func (r *ProductRepository) Search(ctx context.Context, text string, offset, limit int) ([]entity.Product, int64, error) {
+	searchTerm := "%" + strings.ToLower(text) + "%"
+	// Start a new session so the count and the page query don't share a statement
+	query := r.db.WithContext(ctx).Model(&Product{}).
+		Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", searchTerm, searchTerm).
+		Where("archived = ?", false).
+		Session(&gorm.Session{})
+
+	// Count total matches
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []Product
+	err := query.
+		Order(clause.Expr{SQL: "CASE WHEN LOWER(name) LIKE ? THEN 0 ELSE 1 END, id DESC", Vars: []interface{}{searchTerm}}).
+		Offset(offset).
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Map to entities
+	products := make([]entity.Product, len(models))
+	for i, p := range models {
+		products[i] = entity.Product{
+			ID:            p.ID,
+			Name:          p.Name,
+			SKU:           stringValue(p.SKU),
+			Description:   p.Description,
+			Price:         p.Price,
+			StockQuantity: p.StockQuantity,
+			Status:        p.Status,
+			Archived:      p.Archived,
+			ArchivedAt:    p.ArchivedAt,
+			CreatedAt:     p.CreatedAt,
+			UpdatedAt:     p.UpdatedAt,
+		}
+	}
+
+	return products, total, nil
+}
+
+// BulkUpdatePrice applies a price adjustment to all matching products in a single transaction
+// and records it in the price audit log. A dry run computes the same changes and rolls the
+// transaction back. It returns storage.ErrTooManyRows if more than MaxProducts products match.
+func (r *ProductRepository) BulkUpdatePrice(ctx context.Context, update entity.BulkPriceUpdate) ([]entity.PriceChange, error) {
+	defer r.counts.invalidate()
+
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the affected rows so concurrent updates can't interleave
+	query := tx.Model(&Product{})
+	if !update.DryRun {
+		query = query.Clauses(clause.Locking{
+			Strength: "UPDATE",
+			Table:    clause.Table{Name: "products"},
+		})
+	}
+	if len(update.ProductIDs) > 0 {
+		query = query.Where("products.id IN ?", update.ProductIDs)
+	} else {
+		query = applyProductFilter(query, update.Filter)
+	}
+
+	// Read one row more than the cap to tell whether it is exceeded
+	if update.MaxProducts > 0 {
+		query = query.Order("products.id").Limit(update.MaxProducts + 1)
+	}
+
+	var models []Product
+	if err := query.Select("products.id", "products.name", "products.price").Find(&models).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if update.MaxProducts > 0 && len(models) > update.MaxProducts {
+		tx.Rollback()
+		return nil, storage.ErrTooManyRows
+	}
+
+	changes := make([]entity.PriceChange, 0, len(models))
+	for _, m := range models {
+		newPrice := update.Adjustment.Apply(m.Price)
+		if newPrice == m.Price {
+			continue
+		}
+
+		if !update.DryRun {
+			if err := tx.Model(&Product{}).Where("id = ?", m.ID).Update("price", newPrice).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+
+		changes = append(changes, entity.PriceChange{
+			ProductID: m.ID,
+			Name:      m.Name,
+			OldPrice:  m.Price,
+			NewPrice:  newPrice,
+		})
+	}
+
+	if update.DryRun {
+		tx.Rollback()
+		return changes, nil
+	}
+
+	// Record the update in the audit log along with the prices it changed
+	if err := tx.Create(newPriceAudit(update, changes)).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// newPriceAudit creates the audit record of a bulk price update
+func newPriceAudit(update entity.BulkPriceUpdate, changes []entity.PriceChange) *PriceAudit {
+	audit := &PriceAudit{
+		AdjustmentType: update.Adjustment.Type,
+		Value:          update.Adjustment.Value,
+		MinPrice:       update.Adjustment.MinPrice,
+		Updated:        len(changes),
+		Changes:        "[]",
+	}
+	if update.UserID != 0 {
+		userID := update.UserID
+		audit.UserID = &userID
+	}
+	if data, err := json.Marshal(changes); err == nil {
+		audit.Changes = string(data)
+	}
+	return audit
+}
+
+// AdjustStockBatch adds a delta to the stock of each product in a single
+// transaction. Nothing is changed if any product is missing or would end up
+// with negative stock.
+func (r *ProductRepository) AdjustStockBatch(ctx context.Context, deltas map[uint]int) ([]entity.StockChange, error) {
+	defer r.counts.invalidate()
+
+	// Lock the rows in a fixed order so concurrent batches can't deadlock
+	ids := make([]uint, 0, len(deltas))
+	for id := range deltas {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var models []Product
+	err := tx.Model(&Product{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "name", "stock_quantity").
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&models).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Check that every product exists
+	found := make(map[uint]bool, len(models))
+	for _, m := range models {
+		found[m.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			tx.Rollback()
+			return nil, fmt.Errorf("product %d: %w", id, storage.ErrNotFound)
+		}
+	}
+
+	changes := make([]entity.StockChange, 0, len(models))
+	for _, m := range models {
+		delta := deltas[m.ID]
+		newQuantity := m.StockQuantity + delta
+		if newQuantity < 0 {
+			tx.Rollback()
+			return nil, &storage.InsufficientStockError{
+				ProductID: m.ID,
+				Available: m.StockQuantity,
+				Requested: -delta,
+			}
+		}
+
+		if err := tx.Model(&Product{}).Where("id = ?", m.ID).Update("stock_quantity", newQuantity).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		changes = append(changes, entity.StockChange{
+			ProductID:   m.ID,
+			Name:        m.Name,
+			OldQuantity: m.StockQuantity,
+			NewQuantity: newQuantity,
+		})
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
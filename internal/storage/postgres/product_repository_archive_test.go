@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+)
+
+// recentTime matches a timestamp within a minute of now
+type recentTime struct{}
+
+func (recentTime) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && time.Since(t) < time.Minute
+}
+
+// nilValue matches a NULL argument
+type nilValue struct{}
+
+func (nilValue) Match(v driver.Value) bool {
+	return v == nil
+}
+
+func TestSetArchived(t *testing.T) {
+	tests := []struct {
+		name           string
+		archived       bool
+		wantArchivedAt sqlmock.Argument
+	}{
+		{name: "archive records the time", archived: true, wantArchivedAt: recentTime{}},
+		{name: "unarchive clears the time", archived: false, wantArchivedAt: nilValue{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDatabase(t)
+			repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "products" SET "archived"=\$1,"archived_at"=\$2,"updated_at"=\$3 WHERE id = \$4`).
+				WithArgs(tt.archived, tt.wantArchivedAt, sqlmock.AnyArg(), 5).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			if err := repo.SetArchived(context.Background(), 5, tt.archived); err != nil {
+				t.Fatalf("SetArchived() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestList_ExcludesArchivedByDefault(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    entity.ProductFilter
+		wantCount string
+	}{
+		{name: "catalog", filter: entity.ProductFilter{}, wantCount: `SELECT count\(\*\) FROM "products" WHERE products.archived = \$1$`},
+		{name: "including archived", filter: entity.ProductFilter{IncludeArchived: true}, wantCount: `SELECT count\(\*\) FROM "products"$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDatabase(t)
+			repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+			mock.ExpectBegin()
+			count := mock.ExpectQuery(tt.wantCount)
+			if !tt.filter.IncludeArchived {
+				count = count.WithArgs(false)
+			}
+			count.WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectQuery(`SELECT \* FROM "products"`).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectCommit()
+
+			if _, _, err := repo.List(context.Background(), tt.filter); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+		})
+	}
+}
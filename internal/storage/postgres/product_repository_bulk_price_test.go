@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+)
+
+// expectBulkPriceSelect expects the locked read of the products to adjust
+func expectBulkPriceSelect(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT products.id,products.name,products.price FROM "products" WHERE products.id IN \(\$1,\$2\) FOR UPDATE OF "products"`).
+		WithArgs(1, 2).
+		WillReturnRows(rows)
+}
+
+// expectPriceUpdate expects a product's price to be set to price
+func expectPriceUpdate(mock sqlmock.Sqlmock, id uint, price float64) {
+	mock.ExpectExec(`UPDATE "products" SET "price"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+		WithArgs(price, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// expectPriceAudit expects the audit record of an update changing updated products
+func expectPriceAudit(mock sqlmock.Sqlmock, adjustmentType string, updated int) {
+	mock.ExpectQuery(`INSERT INTO "price_audits"`).
+		WithArgs(sqlmock.AnyArg(), adjustmentType, sqlmock.AnyArg(), sqlmock.AnyArg(), updated, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "id"}).AddRow(time.Now(), 1))
+}
+
+func TestBulkUpdatePrice(t *testing.T) {
+	tests := []struct {
+		name       string
+		adjustment entity.PriceAdjustment
+		want       []float64
+	}{
+		{
+			name:       "percentage",
+			adjustment: entity.PriceAdjustment{Type: entity.PriceAdjustmentPercentage, Value: -10},
+			want:       []float64{90, 45},
+		},
+		{
+			name:       "absolute",
+			adjustment: entity.PriceAdjustment{Type: entity.PriceAdjustmentAbsolute, Value: 5.5},
+			want:       []float64{105.5, 55.5},
+		},
+		{
+			name:       "clamped to the minimum price",
+			adjustment: entity.PriceAdjustment{Type: entity.PriceAdjustmentAbsolute, Value: -60, MinPrice: 45},
+			want:       []float64{45, 45},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDatabase(t)
+			repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+			expectBulkPriceSelect(mock, sqlmock.NewRows([]string{"id", "name", "price"}).
+				AddRow(1, "Lamp", 100.0).
+				AddRow(2, "Chair", 50.0))
+			expectPriceUpdate(mock, 1, tt.want[0])
+			expectPriceUpdate(mock, 2, tt.want[1])
+			expectPriceAudit(mock, tt.adjustment.Type, 2)
+			mock.ExpectCommit()
+
+			changes, err := repo.BulkUpdatePrice(context.Background(), entity.BulkPriceUpdate{
+				ProductIDs: []uint{1, 2},
+				Adjustment: tt.adjustment,
+			})
+			if err != nil {
+				t.Fatalf("BulkUpdatePrice() error = %v", err)
+			}
+			if len(changes) != 2 {
+				t.Fatalf("got %d changes, want 2", len(changes))
+			}
+			for i, change := range changes {
+				if change.NewPrice != tt.want[i] {
+					t.Errorf("product %d new price = %v, want %v", change.ProductID, change.NewPrice, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBulkUpdatePriceDryRunRollsBack(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT products.id,products.name,products.price FROM "products" WHERE products.id IN \(\$1,\$2\)$`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price"}).AddRow(1, "Lamp", 100.0).AddRow(2, "Chair", 50.0))
+	mock.ExpectRollback()
+
+	changes, err := repo.BulkUpdatePrice(context.Background(), entity.BulkPriceUpdate{
+		ProductIDs: []uint{1, 2},
+		Adjustment: entity.PriceAdjustment{Type: entity.PriceAdjustmentPercentage, Value: 10},
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatalf("BulkUpdatePrice() error = %v", err)
+	}
+	if len(changes) != 2 || changes[0].NewPrice != 110 || changes[1].NewPrice != 55 {
+		t.Errorf("changes = %+v, want prices 110 and 55", changes)
+	}
+}
+
+func TestBulkUpdatePriceRejectsTooManyProducts(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM "products" WHERE price >= \$1 AND products.archived = \$2 ORDER BY products.id LIMIT 2 FOR UPDATE`).
+		WithArgs(10.0, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price"}).
+			AddRow(1, "Lamp", 100.0).
+			AddRow(2, "Chair", 50.0))
+	mock.ExpectRollback()
+
+	minPrice := 10.0
+	_, err := repo.BulkUpdatePrice(context.Background(), entity.BulkPriceUpdate{
+		Filter:      entity.ProductFilter{MinPrice: &minPrice},
+		Adjustment:  entity.PriceAdjustment{Type: entity.PriceAdjustmentPercentage, Value: -10},
+		MaxProducts: 1,
+	})
+	if !errors.Is(err, storage.ErrTooManyRows) {
+		t.Fatalf("BulkUpdatePrice() error = %v, want ErrTooManyRows", err)
+	}
+}
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// categoryRows returns the category rows of products 1 to n, each in two categories
+func categoryRows(n int) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"product_id", "id", "name", "description"})
+	for id := 1; id <= n; id++ {
+		rows.AddRow(id, 1, "Books", "").AddRow(id, 2, "Games", "")
+	}
+	return rows
+}
+
+func TestList_LoadsCategoriesInOneQuery(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT \* FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "C").AddRow(2, "B").AddRow(1, "A"))
+	mock.ExpectCommit()
+	// Expectations are ordered and exhaustive, so a query per product would fail
+	mock.ExpectQuery(`SELECT pc.product_id, c.id, c.name, c.description FROM product_categories pc JOIN categories c ON c.id = pc.category_id WHERE pc.product_id IN \(\$1,\$2,\$3\) ORDER BY c.id`).
+		WithArgs(3, 2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "id", "name", "description"}).
+			AddRow(1, 1, "Books", "Printed").
+			AddRow(3, 1, "Books", "Printed").
+			AddRow(3, 2, "Games", ""))
+
+	products, _, err := repo.List(context.Background(), entity.ProductFilter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	want := map[uint][]entity.Category{
+		3: {{ID: 1, Name: "Books", Description: "Printed"}, {ID: 2, Name: "Games"}},
+		2: nil,
+		1: {{ID: 1, Name: "Books", Description: "Printed"}},
+	}
+	if len(products) != 3 {
+		t.Fatalf("got %d products, want 3", len(products))
+	}
+	for _, p := range products {
+		if !reflect.DeepEqual(p.Categories, want[p.ID]) {
+			t.Errorf("product %d categories = %+v, want %+v", p.ID, p.Categories, want[p.ID])
+		}
+	}
+}
+
+// roundTrip is the simulated latency of a database query in the benchmarks
+const roundTrip = 200 * time.Microsecond
+
+// BenchmarkLoadCategories loads the categories of a page of products with a
+// single query
+func BenchmarkLoadCategories(b *testing.B) {
+	const pageSize = 50
+	db, mock := newMockDatabase(b)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+	ids := make([]uint, pageSize)
+	for i := range ids {
+		ids[i] = uint(i + 1)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mock.ExpectQuery(`FROM product_categories pc`).WillDelayFor(roundTrip).WillReturnRows(categoryRows(pageSize))
+		if _, err := repo.loadCategories(context.Background(), ids); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(1, "queries/op")
+}
+
+// BenchmarkLoadCategoriesPerProduct loads the same categories with a query per
+// product, as List used to, for comparison with BenchmarkLoadCategories
+func BenchmarkLoadCategoriesPerProduct(b *testing.B) {
+	const pageSize = 50
+	db, mock := newMockDatabase(b)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for id := uint(1); id <= pageSize; id++ {
+			mock.ExpectQuery(`FROM product_categories pc`).WillDelayFor(roundTrip).WillReturnRows(categoryRows(1))
+			if _, err := repo.loadCategories(context.Background(), []uint{id}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(pageSize, "queries/op")
+}
+
+func TestAddCategories_IsIdempotent(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+	insert := `INSERT INTO product_categories \(product_id, category_id\) VALUES \(\$1, \$2\)\s+ON CONFLICT \(product_id, category_id\) DO NOTHING`
+
+	// The first call links both categories
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insert).WithArgs(1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// Repeating it conflicts on both rows, which inserts nothing and isn't an error
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(insert).WithArgs(1, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	for i := 0; i < 2; i++ {
+		if err := repo.AddCategories(context.Background(), 1, []uint{2, 3}); err != nil {
+			t.Fatalf("AddCategories() call %d error = %v", i+1, err)
+		}
+	}
+}
+
+func TestList_ServesProductsWhenCategoriesFailToLoad(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	log := logger.NewLogger("warn", "text", "stdout")
+	log.SetOutput(io.Discard)
+	hook := logtest.NewLocal(log.Logger)
+	repo := NewProductRepository(db, log, "SKU-", nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT \* FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "B").AddRow(1, "A"))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT pc.product_id, c.id, c.name, c.description FROM product_categories pc`).
+		WillReturnError(errors.New("connection reset"))
+
+	products, total, err := repo.List(context.Background(), entity.ProductFilter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if total != 2 || len(products) != 2 || products[0].ID != 2 || products[1].ID != 1 {
+		t.Errorf("List() = %+v (total %d), want products 2 and 1", products, total)
+	}
+	for _, p := range products {
+		if p.Categories != nil {
+			t.Errorf("product %d categories = %+v, want none", p.ID, p.Categories)
+		}
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 1 || entries[0].Level != logrus.WarnLevel || entries[0].Data[logrus.ErrorKey] == nil {
+		t.Fatalf("log entries = %+v, want one warning with the error", entries)
+	}
+	if got := entries[0].Data[logrus.ErrorKey].(error).Error(); got != "connection reset" {
+		t.Errorf("logged error = %q, want the category load failure", got)
+	}
+}
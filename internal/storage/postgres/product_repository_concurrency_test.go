@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+)
+
+// TestProductRepository_ConcurrentReadsAndWrites hammers FindByID and Create
+// from many goroutines. Each call must see only its own product; run it with
+// -race to catch models shared between requests.
+func TestProductRepository_ConcurrentReadsAndWrites(t *testing.T) {
+	const workers = 20
+	db, mock := newMockDatabase(t)
+	mock.MatchExpectationsInOrder(false)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	now := time.Now()
+	for i := 1; i <= workers; i++ {
+		id := i
+		mock.ExpectQuery(`SELECT \* FROM "products" WHERE "products"."id" = \$1 ORDER BY "products"."id" LIMIT 1`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price"}).AddRow(id, fmt.Sprintf("product-%d", id), float64(id)))
+		mock.ExpectQuery(`FROM "categories" JOIN "product_categories"`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id, fmt.Sprintf("category-%d", id)))
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "products"`).
+			WithArgs(fmt.Sprintf("new-%d", id), fmt.Sprintf("NEW-%d", id), "", 0.0, 0, "active", false, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at", "id"}).AddRow(now, now, 100+id))
+		mock.ExpectCommit()
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for i := 1; i <= workers; i++ {
+		wg.Add(2)
+		go func(id uint) {
+			defer wg.Done()
+			product, err := repo.FindByID(context.Background(), id)
+			switch {
+			case err != nil:
+				errs <- err
+			case product == nil || product.Name != fmt.Sprintf("product-%d", id) || product.Price != float64(id):
+				errs <- fmt.Errorf("FindByID(%d) = %+v", id, product)
+			case len(product.Categories) != 1 || product.Categories[0].ID != id:
+				errs <- fmt.Errorf("FindByID(%d) categories = %+v", id, product.Categories)
+			}
+		}(uint(i))
+		go func(id uint) {
+			defer wg.Done()
+			product := &entity.Product{Name: fmt.Sprintf("new-%d", id), SKU: fmt.Sprintf("NEW-%d", id), Status: "active"}
+			if err := repo.Create(context.Background(), product); err != nil {
+				errs <- err
+				return
+			}
+			if product.ID != 100+id || product.SKU != fmt.Sprintf("NEW-%d", id) {
+				errs <- fmt.Errorf("Create() of new-%d = ID %d, SKU %q", id, product.ID, product.SKU)
+			}
+		}(uint(i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
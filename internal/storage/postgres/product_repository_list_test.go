@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"gorm.io/gorm"
+)
+
+// txOptionsRecorder records the options of the transactions begun on a pool
+type txOptionsRecorder struct {
+	*sql.DB
+	opts []*sql.TxOptions
+}
+
+func (r *txOptionsRecorder) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	r.opts = append(r.opts, opts)
+	return r.DB.BeginTx(ctx, opts)
+}
+
+func TestList_CountsAndPagesInOneTransaction(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	// Expectations are ordered, so both queries must run between begin and commit
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT \* FROM "products" WHERE products.archived = \$1 ORDER BY "products"."id" DESC LIMIT 10`).
+		WithArgs(false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Widget").AddRow(2, "Gadget"))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`FROM product_categories pc JOIN categories c`).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "id", "name"}))
+
+	products, count, err := repo.List(context.Background(), entity.ProductFilter{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if count != 2 || len(products) != 2 {
+		t.Fatalf("List() = %d products of %d, want 2 of 2", len(products), count)
+	}
+}
+
+func TestList_ReadsOneRepeatableReadSnapshot(t *testing.T) {
+	var recorder *txOptionsRecorder
+	db, mock := newMockDatabaseWith(t, func(sqlDB *sql.DB) gorm.ConnPool {
+		recorder = &txOptionsRecorder{DB: sqlDB}
+		return recorder
+	})
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	if _, _, err := repo.List(context.Background(), entity.ProductFilter{Page: 1, PageSize: 10}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	// A product inserted between the count and the page query isn't visible
+	// to either under repeatable read, so the total matches the pages
+	if len(recorder.opts) != 1 {
+		t.Fatalf("began %d transactions, want 1", len(recorder.opts))
+	}
+	opts := recorder.opts[0]
+	if opts == nil || opts.Isolation != sql.LevelRepeatableRead || !opts.ReadOnly {
+		t.Errorf("transaction options = %+v, want a read-only repeatable read", opts)
+	}
+}
+
+func TestList_RollsBackOnFailure(t *testing.T) {
+	failure := errors.New("connection reset")
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "count fails",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).WillReturnError(failure)
+			},
+		},
+		{
+			name: "page fails",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery(`SELECT \* FROM "products"`).WillReturnError(failure)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDatabase(t)
+			repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+			mock.ExpectBegin()
+			tt.expect(mock)
+			mock.ExpectRollback()
+
+			if _, _, err := repo.List(context.Background(), entity.ProductFilter{}); !errors.Is(err, failure) {
+				t.Errorf("List() error = %v, want %v", err, failure)
+			}
+		})
+	}
+}
+
+func TestProductOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		sortBy     string
+		sortOrder  string
+		wantColumn string
+		wantDesc   bool
+	}{
+		{name: "default", wantColumn: "id", wantDesc: true},
+		{name: "whitelisted ascending", sortBy: "price", sortOrder: "asc", wantColumn: "price"},
+		{name: "whitelisted descending", sortBy: "created_at", sortOrder: "desc", wantColumn: "created_at", wantDesc: true},
+		{name: "unknown column", sortBy: "password_hash", sortOrder: "asc", wantColumn: "id", wantDesc: true},
+		{name: "injection", sortBy: "price; DROP TABLE products", wantColumn: "id", wantDesc: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column, desc := productOrder(entity.ProductFilter{SortBy: tt.sortBy, SortOrder: tt.sortOrder})
+			if column != tt.wantColumn || desc != tt.wantDesc {
+				t.Errorf("productOrder() = %q, %v, want %q, %v", column, desc, tt.wantColumn, tt.wantDesc)
+			}
+		})
+	}
+}
+
+func TestList_SortsByWhitelistedColumnThenID(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM "products" WHERE products.archived = \$1 ORDER BY "products"."price","products"."id" LIMIT 10$`).
+		WithArgs(false).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	if _, _, err := repo.List(context.Background(), entity.ProductFilter{SortBy: "price", SortOrder: "asc"}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+}
+
+func TestList_StartsAfterCursor(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	cursor := entity.ProductCursor{ID: 8, SortBy: "price", SortOrder: "asc", Value: "12.5"}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM "products" WHERE products.archived = \$1 AND \(products.price, products.id\) > \(\$2, \$3\) ORDER BY "products"."price","products"."id" LIMIT 10$`).
+		WithArgs(false, 12.5, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	filter := entity.ProductFilter{SortBy: "price", SortOrder: "asc", After: &cursor}
+	if _, _, err := repo.List(context.Background(), filter); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+}
+
+func TestList_RejectsCursorOfAnotherOrder(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	cursor := entity.ProductCursor{ID: 8, SortBy: "name", SortOrder: "asc", Value: "Widget"}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+
+	filter := entity.ProductFilter{SortBy: "price", SortOrder: "asc", After: &cursor}
+	if _, _, err := repo.List(context.Background(), filter); !errors.Is(err, entity.ErrInvalidCursor) {
+		t.Errorf("List() error = %v, want %v", err, entity.ErrInvalidCursor)
+	}
+}
+
+func TestList_CancelledMidListRollsBack(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The caller goes away while the page query is running
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT \* FROM "products"`).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectRollback()
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	// Neither a commit nor the category query is expected
+	products, _, err := repo.List(ctx, entity.ProductFilter{Page: 1, PageSize: 10})
+	if err == nil {
+		t.Fatalf("List() = %+v, want the cancellation error", products)
+	}
+	if products != nil {
+		t.Errorf("List() = %+v, want no products", products)
+	}
+
+	// database/sql rolls the transaction back as soon as the context is done,
+	// which may finish just after List returns
+	deadline := time.Now().Add(time.Second)
+	for mock.ExpectationsWereMet() != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("transaction not rolled back: %v", mock.ExpectationsWereMet())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+)
+
+// expectStockSelect expects the locked read of the products to adjust
+func expectStockSelect(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id","name","stock_quantity" FROM "products" WHERE id IN \(\$1,\$2\) .*ORDER BY id FOR UPDATE`).
+		WithArgs(1, 2).
+		WillReturnRows(rows)
+}
+
+// expectStockUpdate expects a product's stock to be set to quantity
+func expectStockUpdate(mock sqlmock.Sqlmock, id uint, quantity int) {
+	mock.ExpectExec(`UPDATE "products" SET "stock_quantity"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+		WithArgs(quantity, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestAdjustStockBatch(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	expectStockSelect(mock, sqlmock.NewRows([]string{"id", "name", "stock_quantity"}).
+		AddRow(1, "Lamp", 10).
+		AddRow(2, "Desk", 2))
+	expectStockUpdate(mock, 1, 7)
+	expectStockUpdate(mock, 2, 12)
+	mock.ExpectCommit()
+
+	changes, err := repo.AdjustStockBatch(context.Background(), map[uint]int{2: 10, 1: -3})
+	if err != nil {
+		t.Fatalf("AdjustStockBatch() error = %v", err)
+	}
+	want := []entity.StockChange{
+		{ProductID: 1, Name: "Lamp", OldQuantity: 10, NewQuantity: 7},
+		{ProductID: 2, Name: "Desk", OldQuantity: 2, NewQuantity: 12},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestAdjustStockBatch_RollsBackWhenStockWouldGoNegative(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	// The first product is adjusted before the second turns out short, so
+	// the whole batch is rolled back
+	expectStockSelect(mock, sqlmock.NewRows([]string{"id", "name", "stock_quantity"}).
+		AddRow(1, "Lamp", 10).
+		AddRow(2, "Desk", 2))
+	expectStockUpdate(mock, 1, 7)
+	mock.ExpectRollback()
+
+	changes, err := repo.AdjustStockBatch(context.Background(), map[uint]int{1: -3, 2: -5})
+	var stockErr *storage.InsufficientStockError
+	if !errors.As(err, &stockErr) {
+		t.Fatalf("AdjustStockBatch() error = %v, want insufficient stock", err)
+	}
+	if stockErr.ProductID != 2 || stockErr.Available != 2 || stockErr.Requested != 5 {
+		t.Errorf("error = %+v, want product 2 with 2 available and 5 requested", stockErr)
+	}
+	if changes != nil {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+}
+
+func TestAdjustStockBatch_RollsBackWhenProductMissing(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewProductRepository(db, testLogger(), "SKU-", nil)
+
+	expectStockSelect(mock, sqlmock.NewRows([]string{"id", "name", "stock_quantity"}).
+		AddRow(1, "Lamp", 10))
+	mock.ExpectRollback()
+
+	if _, err := repo.AdjustStockBatch(context.Background(), map[uint]int{1: -3, 2: 1}); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("AdjustStockBatch() error = %v, want %v", err, storage.ErrNotFound)
+	}
+}
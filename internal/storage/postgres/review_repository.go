@@ -0,0 +1,275 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ReviewRepository implements storage.ReviewRepository
+type ReviewRepository struct {
+	db     *Database
+	logger *logger.Logger
+}
+
+// NewReviewRepository creates a new ReviewRepository
+func NewReviewRepository(db *Database, logger *logger.Logger) *ReviewRepository {
+	return &ReviewRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new review
+func (r *ReviewRepository) Create(ctx context.Context, review *entity.Review) error {
+	model := &Review{
+		ProductID: review.ProductID,
+		UserID:    review.UserID,
+		Rating:    review.Rating,
+		Comment:   review.Comment,
+	}
+
+	// Omit associations so the zero-valued User and Product aren't upserted
+	if err := r.db.WithContext(ctx).Omit("User", "Product").Create(model).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return storage.ErrDuplicateKey
+		}
+		return err
+	}
+
+	// Update the entity with the generated fields
+	review.ID = model.ID
+	review.Approved = model.Approved
+	review.CreatedAt = model.CreatedAt
+	review.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// List lists one page of a product's reviews, newest first, along with the
+// total number of reviews
+func (r *ReviewRepository) List(ctx context.Context, productID uint, offset, limit int) ([]entity.Review, int64, error) {
+	// Start a new session so the count and the page query don't share a statement
+	query := r.db.WithContext(ctx).Model(&Review{}).Where("product_id = ?", productID).Session(&gorm.Session{})
+
+	// Count total reviews
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []Review
+	err := query.
+		Preload("User").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Map to entities
+	reviews := make([]entity.Review, len(models))
+	for i, model := range models {
+		reviews[i] = toReviewEntity(model)
+	}
+
+	return reviews, total, nil
+}
+
+// FindByID finds a review by ID
+func (r *ReviewRepository) FindByID(ctx context.Context, id uint) (*entity.Review, error) {
+	var model Review
+	if err := r.db.WithContext(ctx).Preload("User").First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	review := toReviewEntity(model)
+	return &review, nil
+}
+
+// FindByUserAndProduct finds a user's review of a product
+func (r *ReviewRepository) FindByUserAndProduct(ctx context.Context, userID, productID uint) (*entity.Review, error) {
+	var model Review
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	review := toReviewEntity(model)
+	return &review, nil
+}
+
+// ListPreview lists the few reviews embedded in a product's detail, newest or
+// highest-rated first, hiding reviews rated below the preview's minimum
+func (r *ReviewRepository) ListPreview(ctx context.Context, productID uint, preview entity.ReviewPreview) ([]entity.Review, error) {
+	order := "created_at DESC"
+	if preview.Sort == entity.ReviewSortRating {
+		order = "rating DESC, created_at DESC"
+	}
+
+	var models []Review
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("product_id = ? AND rating >= ?", productID, preview.MinRating).
+		Order(order).
+		Limit(preview.Limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	reviews := make([]entity.Review, len(models))
+	for i, model := range models {
+		reviews[i] = toReviewEntity(model)
+	}
+
+	return reviews, nil
+}
+
+// ListReviewedNotWishlisted lists the products a user reviewed with at least
+// minRating that aren't in their wishlist, most recently reviewed first
+func (r *ReviewRepository) ListReviewedNotWishlisted(ctx context.Context, userID uint, minRating int) ([]entity.Product, error) {
+	var models []Product
+	err := r.db.WithContext(ctx).
+		Joins("JOIN reviews r ON r.product_id = products.id").
+		Joins("LEFT JOIN wishlist w ON w.product_id = r.product_id AND w.user_id = r.user_id").
+		Scopes(ownedBy(ctx, "r.user_id")).
+		Where("r.user_id = ? AND r.rating >= ? AND w.product_id IS NULL", userID, minRating).
+		Order("r.created_at DESC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	products := make([]entity.Product, len(models))
+	for i, p := range models {
+		products[i] = entity.Product{
+			ID:            p.ID,
+			Name:          p.Name,
+			Description:   p.Description,
+			Price:         p.Price,
+			StockQuantity: p.StockQuantity,
+			Status:        p.Status,
+			CreatedAt:     p.CreatedAt,
+			UpdatedAt:     p.UpdatedAt,
+		}
+	}
+
+	return products, nil
+}
+
+// TopProductsByReviews finds the most reviewed products, breaking ties by
+// average rating
+func (r *ReviewRepository) TopProductsByReviews(ctx context.Context, limit int) ([]entity.TopProduct, error) {
+	var rows []struct {
+		ProductID   uint
+		ProductName string
+		Count       int
+	}
+	err := r.db.WithContext(ctx).
+		Table("reviews r").
+		Select("r.product_id, p.name AS product_name, COUNT(*) AS count").
+		Joins("JOIN products p ON p.id = r.product_id").
+		Group("r.product_id, p.name").
+		Order("count DESC, AVG(r.rating) DESC, r.product_id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	topProducts := make([]entity.TopProduct, len(rows))
+	for i, row := range rows {
+		topProducts[i] = entity.TopProduct{
+			ProductID:   row.ProductID,
+			ProductName: row.ProductName,
+			Count:       row.Count,
+			Metric:      entity.MetricReviews,
+		}
+	}
+
+	return topProducts, nil
+}
+
+// toReviewEntity maps a Review model and its author to an entity
+func toReviewEntity(model Review) entity.Review {
+	return entity.Review{
+		ID:        model.ID,
+		ProductID: model.ProductID,
+		UserID:    model.UserID,
+		Rating:    model.Rating,
+		Comment:   model.Comment,
+		Approved:  model.Approved,
+		User: entity.User{
+			ID:        model.User.ID,
+			Username:  model.User.Username,
+			FullName:  model.User.FullName,
+			CreatedAt: model.User.CreatedAt,
+			UpdatedAt: model.User.UpdatedAt,
+		},
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+// RatingDistribution counts a product's approved reviews by rating. Ratings
+// nobody gave are missing from the map.
+func (r *ReviewRepository) RatingDistribution(ctx context.Context, productID uint) (map[int]int, error) {
+	var rows []struct {
+		Rating int
+		Count  int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&Review{}).
+		Select("rating, COUNT(*) AS count").
+		Where("product_id = ? AND approved", productID).
+		Group("rating").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := make(map[int]int, len(rows))
+	for _, row := range rows {
+		distribution[row.Rating] = row.Count
+	}
+
+	return distribution, nil
+}
+
+// Count counts all reviews
+func (r *ReviewRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&Review{}).
+		Count(&count).Error
+	return count, err
+}
+
+// AverageRating returns the average rating of all reviews, or zero without reviews
+func (r *ReviewRepository) AverageRating(ctx context.Context) (float64, error) {
+	var average float64
+	err := r.db.WithContext(ctx).
+		Model(&Review{}).
+		Select("COALESCE(AVG(rating), 0)").
+		Scan(&average).Error
+	return average, err
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/pkg/ctxutil"
+)
+
+func TestRatingDistribution_CountsApprovedReviews(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewReviewRepository(db, testLogger())
+
+	mock.ExpectQuery(`SELECT rating, COUNT\(\*\) AS count FROM "reviews" WHERE product_id = \$1 AND approved GROUP BY "rating"`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"rating", "count"}).
+			AddRow(5, 80).
+			AddRow(4, 20).
+			AddRow(1, 3))
+
+	got, err := repo.RatingDistribution(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("RatingDistribution() error = %v", err)
+	}
+	if want := map[int]int{5: 80, 4: 20, 1: 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RatingDistribution() = %v, want %v", got, want)
+	}
+}
+
+func TestListReviewedNotWishlisted_ExcludesWishlistedProducts(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewReviewRepository(db, testLogger())
+	ctx := ctxutil.WithUserID(context.Background(), 5)
+
+	// The anti-join keeps only the reviews without a wishlist row of the same user
+	mock.ExpectQuery(`SELECT "products"\."id",.* FROM "products" ` +
+		`JOIN reviews r ON r\.product_id = products\.id ` +
+		`LEFT JOIN wishlist w ON w\.product_id = r\.product_id AND w\.user_id = r\.user_id ` +
+		`WHERE \(r\.user_id = \$1 AND r\.rating >= \$2 AND w\.product_id IS NULL\) AND r\.user_id = \$3 ` +
+		`ORDER BY r\.created_at DESC`).
+		WithArgs(5, 4, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price"}).
+			AddRow(3, "Lamp", 50).
+			AddRow(1, "Desk", 200))
+
+	products, err := repo.ListReviewedNotWishlisted(ctx, 5, 4)
+	if err != nil {
+		t.Fatalf("ListReviewedNotWishlisted() error = %v", err)
+	}
+	if len(products) != 2 || products[0].ID != 3 || products[1].ID != 1 {
+		t.Errorf("products = %+v, want products 3 and 1 in review order", products)
+	}
+}
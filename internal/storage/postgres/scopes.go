@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/ctxutil"
+	"gorm.io/gorm"
+)
+
+// ownedBy restricts a query to rows owned by the authenticated user in ctx.
+// Ownership-scoped repository methods apply it on top of any explicit user ID
+// so a handler passing the wrong ID can never read another user's rows.
+func ownedBy(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if userID, ok := ctxutil.UserIDFromContext(ctx); ok {
+			return db.Where(column+" = ?", userID)
+		}
+		return db
+	}
+}
+
+// checkOwner is ownedBy for inserts, which can't be scoped: it refuses to
+// create rows for any user but the authenticated user in ctx
+func checkOwner(ctx context.Context, userID uint) error {
+	if ownerID, ok := ctxutil.UserIDFromContext(ctx); ok && ownerID != userID {
+		return storage.ErrNotOwner
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	"gorm.io/gorm/clause"
+)
+
+// StockReservationRepository implements storage.StockReservationRepository
+type StockReservationRepository struct {
+	db     *Database
+	logger *logger.Logger
+	counts *CountCache
+}
+
+// NewStockReservationRepository creates a new StockReservationRepository. Writes to products
+// invalidate the product counts cached in counts, which may be nil.
+func NewStockReservationRepository(db *Database, logger *logger.Logger, counts *CountCache) *StockReservationRepository {
+	return &StockReservationRepository{
+		db:     db,
+		logger: logger,
+		counts: counts,
+	}
+}
+
+// ReleaseExpired returns the stock held by reservations that expired before
+// now to their products and marks them released, all in one transaction.
+// Rows locked by a concurrent sweeper are skipped and released reservations
+// are never selected again, so each reservation is restored exactly once.
+func (r *StockReservationRepository) ReleaseExpired(ctx context.Context, now time.Time) (int, error) {
+	defer r.counts.invalidate()
+
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the expired reservations
+	var reservations []StockReservation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("released_at IS NULL AND expires_at <= ?", now).
+		Find(&reservations).Error
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if len(reservations) == 0 {
+		return 0, tx.Rollback().Error
+	}
+
+	ids := make([]uint, len(reservations))
+	for i, reservation := range reservations {
+		ids[i] = reservation.ID
+	}
+
+	// Return the reserved quantities to stock
+	err = tx.Exec(`
+		UPDATE products p
+		SET stock_quantity = p.stock_quantity + s.quantity
+		FROM (
+			SELECT product_id, SUM(quantity) AS quantity
+			FROM stock_reservations
+			WHERE id IN ?
+			GROUP BY product_id
+		) s
+		WHERE p.id = s.product_id`, ids).Error
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Model(&StockReservation{}).Where("id IN ?", ids).Update("released_at", now).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return len(reservations), nil
+}
+
+// CountOutstanding counts the reservations that haven't been released yet
+func (r *StockReservationRepository) CountOutstanding(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&StockReservation{}).
+		Where("released_at IS NULL").
+		Count(&count).Error
+	return count, err
+}
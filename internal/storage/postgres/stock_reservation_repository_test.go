@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectExpiredReservations expects the locked read of the unreleased
+// reservations that expired before now
+func expectExpiredReservations(mock sqlmock.Sqlmock, now time.Time, rows *sqlmock.Rows) {
+	mock.ExpectQuery(`SELECT \* FROM "stock_reservations" WHERE released_at IS NULL AND expires_at <= \$1 FOR UPDATE SKIP LOCKED`).
+		WithArgs(now).
+		WillReturnRows(rows)
+}
+
+func TestReleaseExpired_RestoresStockOnce(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewStockReservationRepository(db, testLogger(), nil)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "product_id", "quantity", "expires_at", "released_at", "created_at"}
+
+	// The first sweep restores the stock and marks the reservations released
+	mock.ExpectBegin()
+	expectExpiredReservations(mock, now, sqlmock.NewRows(columns).
+		AddRow(1, 10, 2, now.Add(-time.Minute), nil, now.Add(-time.Hour)).
+		AddRow(2, 10, 3, now.Add(-time.Second), nil, now.Add(-time.Hour)))
+	mock.ExpectExec(`UPDATE products p\s+SET stock_quantity = p.stock_quantity \+ s.quantity`).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "stock_reservations" SET "released_at"=\$1 WHERE id IN \(\$2,\$3\)`).
+		WithArgs(now, 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// Released reservations are no longer selected, so the second sweep
+	// leaves the stock alone
+	mock.ExpectBegin()
+	expectExpiredReservations(mock, now, sqlmock.NewRows(columns))
+	mock.ExpectRollback()
+
+	released, err := repo.ReleaseExpired(context.Background(), now)
+	if err != nil {
+		t.Fatalf("first ReleaseExpired() error = %v", err)
+	}
+	if released != 2 {
+		t.Errorf("first ReleaseExpired() = %d, want 2", released)
+	}
+
+	released, err = repo.ReleaseExpired(context.Background(), now)
+	if err != nil {
+		t.Fatalf("second ReleaseExpired() error = %v", err)
+	}
+	if released != 0 {
+		t.Errorf("second ReleaseExpired() = %d, want 0", released)
+	}
+}
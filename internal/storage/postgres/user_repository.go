@@ -3,9 +3,9 @@ package postgres
 import (
 	"context"
 	"errors"
-	"sync"
 
 	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/storage"
 	"github.com/thanhnguyen/product-api/pkg/logger"
 	"gorm.io/gorm"
 )
@@ -14,7 +14,6 @@ import (
 type UserRepository struct {
 	db     *Database
 	logger *logger.Logger
-	pool   *sync.Pool
 }
 
 // NewUserRepository creates a new UserRepository
@@ -22,31 +21,25 @@ func NewUserRepository(db *Database, logger *logger.Logger) *UserRepository {
 	return &UserRepository{
 		db:     db,
 		logger: logger,
-		pool: &sync.Pool{
-			New: func() interface{} {
-				return &User{}
-			},
-		},
 	}
 }
 
 // Create creates a new user
 func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
-	// Get a model instance from the pool
-	model := r.pool.Get().(*User)
-	defer r.pool.Put(model)
-
-	// Reset fields to avoid data leakage
-	*model = User{
-		Username:     user.Username,
-		Email:        user.Email,
-		PasswordHash: user.PasswordHash,
-		FullName:     user.FullName,
-		Role:         user.Role,
+	model := &User{
+		Username:           user.Username,
+		Email:              user.Email,
+		PasswordHash:       user.PasswordHash,
+		FullName:           user.FullName,
+		Role:               user.Role,
+		MustChangePassword: user.MustChangePassword,
 	}
 
 	// Create the user
 	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return storage.ErrDuplicateKey
+		}
 		return err
 	}
 
@@ -60,9 +53,7 @@ func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
 
 // FindByID finds a user by ID
 func (r *UserRepository) FindByID(ctx context.Context, id uint) (*entity.User, error) {
-	// Get a model instance from the pool
-	model := r.pool.Get().(*User)
-	defer r.pool.Put(model)
+	model := &User{}
 
 	// Find the user
 	if err := r.db.WithContext(ctx).First(model, id).Error; err != nil {
@@ -74,22 +65,21 @@ func (r *UserRepository) FindByID(ctx context.Context, id uint) (*entity.User, e
 
 	// Map model to entity
 	return &entity.User{
-		ID:           model.ID,
-		Username:     model.Username,
-		Email:        model.Email,
-		PasswordHash: model.PasswordHash,
-		FullName:     model.FullName,
-		Role:         model.Role,
-		CreatedAt:    model.CreatedAt,
-		UpdatedAt:    model.UpdatedAt,
+		ID:                 model.ID,
+		Username:           model.Username,
+		Email:              model.Email,
+		PasswordHash:       model.PasswordHash,
+		FullName:           model.FullName,
+		Role:               model.Role,
+		MustChangePassword: model.MustChangePassword,
+		CreatedAt:          model.CreatedAt,
+		UpdatedAt:          model.UpdatedAt,
 	}, nil
 }
 
 // FindByUsername finds a user by username
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
-	// Get a model instance from the pool
-	model := r.pool.Get().(*User)
-	defer r.pool.Put(model)
+	model := &User{}
 
 	// Find the user
 	if err := r.db.WithContext(ctx).Where("username = ?", username).First(model).Error; err != nil {
@@ -101,22 +91,21 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 
 	// Map model to entity
 	return &entity.User{
-		ID:           model.ID,
-		Username:     model.Username,
-		Email:        model.Email,
-		PasswordHash: model.PasswordHash,
-		FullName:     model.FullName,
-		Role:         model.Role,
-		CreatedAt:    model.CreatedAt,
-		UpdatedAt:    model.UpdatedAt,
+		ID:                 model.ID,
+		Username:           model.Username,
+		Email:              model.Email,
+		PasswordHash:       model.PasswordHash,
+		FullName:           model.FullName,
+		Role:               model.Role,
+		MustChangePassword: model.MustChangePassword,
+		CreatedAt:          model.CreatedAt,
+		UpdatedAt:          model.UpdatedAt,
 	}, nil
 }
 
 // FindByEmail finds a user by email
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
-	// Get a model instance from the pool
-	model := r.pool.Get().(*User)
-	defer r.pool.Put(model)
+	model := &User{}
 
 	// Find the user
 	if err := r.db.WithContext(ctx).Where("email = ?", email).First(model).Error; err != nil {
@@ -128,22 +117,40 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity
 
 	// Map model to entity
 	return &entity.User{
-		ID:           model.ID,
-		Username:     model.Username,
-		Email:        model.Email,
-		PasswordHash: model.PasswordHash,
-		FullName:     model.FullName,
-		Role:         model.Role,
-		CreatedAt:    model.CreatedAt,
-		UpdatedAt:    model.UpdatedAt,
+		ID:                 model.ID,
+		Username:           model.Username,
+		Email:              model.Email,
+		PasswordHash:       model.PasswordHash,
+		FullName:           model.FullName,
+		Role:               model.Role,
+		MustChangePassword: model.MustChangePassword,
+		CreatedAt:          model.CreatedAt,
+		UpdatedAt:          model.UpdatedAt,
 	}, nil
 }
 
+// CountByRole counts the users with a role
+func (r *UserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&User{}).
+		Where("role = ?", role).
+		Count(&count).Error
+	return count, err
+}
+
+// Count counts all users
+func (r *UserRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&User{}).
+		Count(&count).Error
+	return count, err
+}
+
 // Update updates a user
 func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
-	// Get a model instance from the pool
-	model := r.pool.Get().(*User)
-	defer r.pool.Put(model)
+	model := &User{}
 
 	// Find the user
 	if err := r.db.WithContext(ctx).First(model, user.ID).Error; err != nil {
@@ -159,6 +166,7 @@ func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 	model.PasswordHash = user.PasswordHash
 	model.FullName = user.FullName
 	model.Role = user.Role
+	model.MustChangePassword = user.MustChangePassword
 
 	// Save the user
 	if err := r.db.WithContext(ctx).Save(model).Error; err != nil {
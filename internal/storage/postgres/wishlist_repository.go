@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WishlistRepository implements storage.WishlistRepository
+type WishlistRepository struct {
+	db     *Database
+	logger *logger.Logger
+}
+
+// NewWishlistRepository creates a new WishlistRepository
+func NewWishlistRepository(db *Database, logger *logger.Logger) *WishlistRepository {
+	return &WishlistRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Add adds a product to a user's wishlist. Adding a product that is already
+// wishlisted is a no-op.
+func (r *WishlistRepository) Add(ctx context.Context, userID, productID uint) error {
+	if err := checkOwner(ctx, userID); err != nil {
+		return err
+	}
+
+	model := &Wishlist{
+		UserID:    userID,
+		ProductID: productID,
+	}
+
+	return r.db.WithContext(ctx).
+		Omit("User", "Product").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(model).Error
+}
+
+// Remove removes a product from a user's wishlist
+func (r *WishlistRepository) Remove(ctx context.Context, userID, productID uint) error {
+	return r.db.WithContext(ctx).
+		Scopes(ownedBy(ctx, "user_id")).
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		Delete(&Wishlist{}).Error
+}
+
+// List lists one page of the products in a user's wishlist, most recently
+// added first, along with the total number of wishlisted products. The
+// categories of the page are loaded in a single batched query.
+func (r *WishlistRepository) List(ctx context.Context, userID uint, offset, limit int) ([]entity.Product, int64, error) {
+	// Start a new session so the count and the page query don't share a statement
+	query := r.db.WithContext(ctx).
+		Model(&Product{}).
+		Joins("JOIN wishlist w ON w.product_id = products.id").
+		Scopes(ownedBy(ctx, "w.user_id")).
+		Where("w.user_id = ?", userID).
+		Session(&gorm.Session{})
+
+	// Count total wishlisted products
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []Product
+	err := query.
+		Preload("Categories").
+		Order("w.added_at DESC, products.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Map to entities
+	products := make([]entity.Product, len(models))
+	for i, p := range models {
+		products[i] = entity.Product{
+			ID:            p.ID,
+			Name:          p.Name,
+			SKU:           stringValue(p.SKU),
+			Description:   p.Description,
+			Price:         p.Price,
+			StockQuantity: p.StockQuantity,
+			Status:        p.Status,
+			Archived:      p.Archived,
+			ArchivedAt:    p.ArchivedAt,
+			CreatedAt:     p.CreatedAt,
+			UpdatedAt:     p.UpdatedAt,
+		}
+		for _, c := range p.Categories {
+			products[i].Categories = append(products[i].Categories, entity.Category{
+				ID:          c.ID,
+				Name:        c.Name,
+				Description: c.Description,
+			})
+		}
+	}
+
+	return products, total, nil
+}
+
+// ListStock lists the stock and sale status of every product in a user's
+// wishlist in a single query, most recently added first. Only the ID, name,
+// status, archival flag and stock quantity of the products are loaded.
+func (r *WishlistRepository) ListStock(ctx context.Context, userID uint) ([]entity.Product, error) {
+	var models []Product
+	err := r.db.WithContext(ctx).
+		Select("products.id", "products.name", "products.status", "products.archived", "products.stock_quantity").
+		Joins("JOIN wishlist w ON w.product_id = products.id").
+		Scopes(ownedBy(ctx, "w.user_id")).
+		Where("w.user_id = ?", userID).
+		Order("w.added_at DESC, products.id DESC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	products := make([]entity.Product, len(models))
+	for i, p := range models {
+		products[i] = entity.Product{
+			ID:            p.ID,
+			Name:          p.Name,
+			Status:        p.Status,
+			Archived:      p.Archived,
+			StockQuantity: p.StockQuantity,
+		}
+	}
+
+	return products, nil
+}
+
+// IsProductInWishlist checks whether a product is in a user's wishlist
+func (r *WishlistRepository) IsProductInWishlist(ctx context.Context, userID, productID uint) (bool, error) {
+	db := r.db.WithContext(ctx)
+	wishlisted := db.
+		Model(&Wishlist{}).
+		Select("1").
+		Scopes(ownedBy(ctx, "user_id")).
+		Where("user_id = ? AND product_id = ?", userID, productID)
+
+	var exists bool
+	err := db.Raw("SELECT EXISTS (?)", wishlisted).Scan(&exists).Error
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// CountByProduct counts how many users wishlisted each product
+func (r *WishlistRepository) CountByProduct(ctx context.Context) (map[uint]int, error) {
+	var rows []struct {
+		ProductID uint
+		Count     int
+	}
+	err := r.db.WithContext(ctx).
+		Table("wishlist").
+		Select("product_id, COUNT(*) AS count").
+		Group("product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.ProductID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// TopProductsByWishlist finds the most wishlisted products
+func (r *WishlistRepository) TopProductsByWishlist(ctx context.Context, limit int) ([]entity.TopProduct, error) {
+	var rows []struct {
+		ProductID   uint
+		ProductName string
+		Count       int
+	}
+	err := r.db.WithContext(ctx).
+		Table("wishlist w").
+		Select("w.product_id, p.name AS product_name, COUNT(*) AS count").
+		Joins("JOIN products p ON p.id = w.product_id").
+		Group("w.product_id, p.name").
+		Order("count DESC, w.product_id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Map to entities
+	topProducts := make([]entity.TopProduct, len(rows))
+	for i, row := range rows {
+		topProducts[i] = entity.TopProduct{
+			ProductID:   row.ProductID,
+			ProductName: row.ProductName,
+			Count:       row.Count,
+			Metric:      entity.MetricWishlist,
+		}
+	}
+
+	return topProducts, nil
+}
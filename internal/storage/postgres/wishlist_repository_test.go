@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/pkg/ctxutil"
+)
+
+func TestIsProductInWishlist_FilteredByContextUser(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewWishlistRepository(db, testLogger())
+	ctx := ctxutil.WithUserID(context.Background(), 5)
+
+	// A caller passing another user's ID still only sees the context user's rows
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM "wishlist" WHERE \(user_id = \$1 AND product_id = \$2\) AND user_id = \$3\)`).
+		WithArgs(6, 9, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	exists, err := repo.IsProductInWishlist(ctx, 6, 9)
+	if err != nil {
+		t.Fatalf("IsProductInWishlist() error = %v", err)
+	}
+	if exists {
+		t.Error("IsProductInWishlist() = true for another user's wishlist")
+	}
+}
+
+func TestListStock_FilteredByContextUser(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewWishlistRepository(db, testLogger())
+	ctx := ctxutil.WithUserID(context.Background(), 5)
+
+	mock.ExpectQuery(`JOIN wishlist w ON w.product_id = products.id WHERE w.user_id = \$1 AND w.user_id = \$2`).
+		WithArgs(6, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "archived", "stock_quantity"}))
+
+	if _, err := repo.ListStock(ctx, 6); err != nil {
+		t.Fatalf("ListStock() error = %v", err)
+	}
+}
+
+func TestAdd_RefusesAnotherUsersWishlist(t *testing.T) {
+	db, _ := newMockDatabase(t)
+	repo := NewWishlistRepository(db, testLogger())
+	ctx := ctxutil.WithUserID(context.Background(), 5)
+
+	// No query is expected, so any insert fails the test
+	if err := repo.Add(ctx, 6, 9); !errors.Is(err, storage.ErrNotOwner) {
+		t.Errorf("Add() error = %v, want %v", err, storage.ErrNotOwner)
+	}
+}
+
+func TestAdd_ContextUser(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewWishlistRepository(db, testLogger())
+	ctx := ctxutil.WithUserID(context.Background(), 5)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "wishlist" \("user_id","product_id"\) VALUES \(\$1,\$2\) ON CONFLICT DO NOTHING`).
+		WithArgs(5, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"added_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	if err := repo.Add(ctx, 5, 9); err != nil {
+		t.Errorf("Add() error = %v", err)
+	}
+}
+
+func TestWishlistList_PagesNewestFirst(t *testing.T) {
+	db, mock := newMockDatabase(t)
+	repo := NewWishlistRepository(db, testLogger())
+	ctx := ctxutil.WithUserID(context.Background(), 5)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "products" JOIN wishlist w ON w.product_id = products.id WHERE w.user_id = \$1 AND w.user_id = \$2`).
+		WithArgs(5, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	mock.ExpectQuery(`SELECT "products"."id",.* FROM "products" JOIN wishlist w ON w.product_id = products.id WHERE w.user_id = \$1 AND w.user_id = \$2 ORDER BY w.added_at DESC, products.id DESC LIMIT 5 OFFSET 10`).
+		WithArgs(5, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Lamp").AddRow(1, "Desk"))
+	mock.ExpectQuery(`SELECT \* FROM "product_categories" WHERE "product_categories"."product_id" IN \(\$1,\$2\)`).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "category_id"}).AddRow(3, 8))
+	mock.ExpectQuery(`SELECT \* FROM "categories" WHERE "categories"."id" = \$1`).
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(8, "Lighting"))
+
+	products, total, err := repo.List(ctx, 5, 10, 5)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if total != 12 {
+		t.Errorf("total = %d, want 12", total)
+	}
+	if len(products) != 2 || products[0].ID != 3 || products[1].ID != 1 {
+		t.Fatalf("products = %+v, want 3 then 1", products)
+	}
+	if len(products[0].Categories) != 1 || products[0].Categories[0].Name != "Lighting" {
+		t.Errorf("categories of product 3 = %+v, want Lighting", products[0].Categories)
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/thanhnguyen/product-api/internal/business/entity"
 )
@@ -12,6 +13,8 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id uint) (*entity.User, error)
 	FindByUsername(ctx context.Context, username string) (*entity.User, error)
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	CountByRole(ctx context.Context, role string) (int64, error)
+	Count(ctx context.Context) (int64, error)
 	Update(ctx context.Context, user *entity.User) error
 }
 
@@ -23,27 +26,94 @@ type ProductRepository interface {
 	Update(ctx context.Context, product *entity.Product) error
 	Delete(ctx context.Context, id uint) error
 	AddCategories(ctx context.Context, productID uint, categoryIDs []uint) error
+	FindByIDs(ctx context.Context, ids []uint) ([]entity.Product, error)
+	FindBySKU(ctx context.Context, sku string) (*entity.Product, error)
+	Search(ctx context.Context, text string, offset, limit int) ([]entity.Product, int64, error)
+	BulkUpdatePrice(ctx context.Context, update entity.BulkPriceUpdate) ([]entity.PriceChange, error)
+	SetArchived(ctx context.Context, id uint, archived bool) error
+	SetStatus(ctx context.Context, id uint, status string) error
+	AdjustStockBatch(ctx context.Context, deltas map[uint]int) ([]entity.StockChange, error)
+	ListUpdateTimes(ctx context.Context) (map[uint]time.Time, error)
 }
 
 // CategoryRepository defines methods for category storage operations
 type CategoryRepository interface {
 	Create(ctx context.Context, category *entity.Category) error
 	List(ctx context.Context) ([]entity.Category, error)
+	Search(ctx context.Context, filter entity.CategoryFilter) ([]entity.Category, error)
 	FindByID(ctx context.Context, id uint) (*entity.Category, error)
 	FindByIDs(ctx context.Context, ids []uint) ([]entity.Category, error)
+	CountProductsByCategory(ctx context.Context) (map[uint]int, error)
+	CountProductsByCategoryTree(ctx context.Context) (map[uint]int, error)
+	Tree(ctx context.Context) ([]entity.CategoryNode, error)
+	Update(ctx context.Context, category *entity.Category) error
+	SetDisabled(ctx context.Context, id uint, disabled bool) error
+	Delete(ctx context.Context, id uint, reassignTo *uint, cascade bool) error
 }
 
 // ReviewRepository defines methods for review storage operations
 type ReviewRepository interface {
 	Create(ctx context.Context, review *entity.Review) error
-	List(ctx context.Context, productID uint) ([]entity.Review, error)
+	List(ctx context.Context, productID uint, offset, limit int) ([]entity.Review, int64, error)
 	FindByID(ctx context.Context, id uint) (*entity.Review, error)
+	FindByUserAndProduct(ctx context.Context, userID, productID uint) (*entity.Review, error)
+	ListReviewedNotWishlisted(ctx context.Context, userID uint, minRating int) ([]entity.Product, error)
+	ListPreview(ctx context.Context, productID uint, preview entity.ReviewPreview) ([]entity.Review, error)
+	TopProductsByReviews(ctx context.Context, limit int) ([]entity.TopProduct, error)
+	RatingDistribution(ctx context.Context, productID uint) (map[int]int, error)
+	Count(ctx context.Context) (int64, error)
+	AverageRating(ctx context.Context) (float64, error)
 }
 
 // WishlistRepository defines methods for wishlist storage operations
 type WishlistRepository interface {
 	Add(ctx context.Context, userID, productID uint) error
 	Remove(ctx context.Context, userID, productID uint) error
-	List(ctx context.Context, userID uint) ([]entity.Product, error)
+	List(ctx context.Context, userID uint, offset, limit int) ([]entity.Product, int64, error)
+	ListStock(ctx context.Context, userID uint) ([]entity.Product, error)
 	IsProductInWishlist(ctx context.Context, userID, productID uint) (bool, error)
+	CountByProduct(ctx context.Context) (map[uint]int, error)
+	TopProductsByWishlist(ctx context.Context, limit int) ([]entity.TopProduct, error)
+}
+
+// PriceScheduleRepository defines methods for scheduled price change storage operations
+type PriceScheduleRepository interface {
+	Create(ctx context.Context, schedule *entity.ScheduledPriceChange) error
+	List(ctx context.Context) ([]entity.ScheduledPriceChange, error)
+	FindByID(ctx context.Context, id uint) (*entity.ScheduledPriceChange, error)
+	Update(ctx context.Context, schedule *entity.ScheduledPriceChange) error
+	Delete(ctx context.Context, id uint) error
+	FindDue(ctx context.Context, now time.Time) ([]entity.ScheduledPriceChange, error)
+	Apply(ctx context.Context, id uint) error
+	Revert(ctx context.Context, id uint) error
+}
+
+// PriceAlertRepository defines methods for price alert storage operations
+type PriceAlertRepository interface {
+	Subscribe(ctx context.Context, alert *entity.PriceAlert) error
+	Unsubscribe(ctx context.Context, userID, productID uint) error
+	ListByUser(ctx context.Context, userID uint) ([]entity.PriceAlert, error)
+	FindTriggered(ctx context.Context, productID uint, price float64) ([]entity.PriceAlert, error)
+	MarkNotified(ctx context.Context, ids []uint) error
+}
+
+// StockReservationRepository defines methods for stock reservation storage operations
+type StockReservationRepository interface {
+	ReleaseExpired(ctx context.Context, now time.Time) (int, error)
+	CountOutstanding(ctx context.Context) (int64, error)
+}
+
+// StatsStore defines methods for storing the computed statistics. Values are
+// read back into dest, which must be a pointer to the type they were set as.
+// GetAll returns them as generic JSON values, e.g. float64 for numbers, the
+// same whichever store holds them.
+type StatsStore interface {
+	Get(ctx context.Context, key string, dest interface{}) (bool, error)
+	Set(ctx context.Context, key string, value interface{}) error
+	SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	GetAll(ctx context.Context) (map[string]interface{}, error)
+	SetCategoryCounts(ctx context.Context, counts map[uint]int) error
+	GetCategoryCounts(ctx context.Context) (map[uint]int, error)
+	SetWishlistCounts(ctx context.Context, counts map[uint]int) error
+	GetWishlistCounts(ctx context.Context) (map[uint]int, error)
 }
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+)
+
+// RegisterRequest represents a request to register a new user
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=255"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	FullName string `json:"full_name" binding:"max=255"`
+}
+
+// LoginRequest represents a login request. Username accepts either a username or an email.
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ChangePasswordRequest represents a request to change the current user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8,max=72"`
+}
+
+// UserResponse represents a user in the response
+type UserResponse struct {
+	ID                 uint   `json:"id"`
+	Username           string `json:"username"`
+	Email              string `json:"email"`
+	FullName           string `json:"full_name"`
+	Role               string `json:"role"`
+	MustChangePassword bool   `json:"must_change_password"`
+	CreatedAt          string `json:"created_at"`
+}
+
+// AuthResponse represents an issued token and the authenticated user
+type AuthResponse struct {
+	Token   string       `json:"token"`
+	Expires time.Time    `json:"expires"`
+	User    UserResponse `json:"user"`
+}
+
+// ToEntity converts a RegisterRequest to an entity.User
+func (r *RegisterRequest) ToEntity() *entity.User {
+	return &entity.User{
+		Username: r.Username,
+		Email:    r.Email,
+		FullName: r.FullName,
+	}
+}
+
+// FromUserEntity converts an entity.User to a UserResponse
+func FromUserEntity(u entity.User) UserResponse {
+	return UserResponse{
+		ID:                 u.ID,
+		Username:           u.Username,
+		Email:              u.Email,
+		FullName:           u.FullName,
+		Role:               u.Role,
+		MustChangePassword: u.MustChangePassword,
+		CreatedAt:          formatTime(u.CreatedAt),
+	}
+}
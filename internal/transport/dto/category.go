@@ -0,0 +1,76 @@
+package dto
+
+import "github.com/thanhnguyen/product-api/internal/business/entity"
+
+// CategoryRequest represents a request to create or update a category
+type CategoryRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description" binding:"max=2000"`
+	ParentID    *uint  `json:"parent_id"`
+}
+
+// ToEntity converts a CategoryRequest to an entity.Category
+func (r *CategoryRequest) ToEntity() *entity.Category {
+	return &entity.Category{
+		Name:        r.Name,
+		Description: r.Description,
+		ParentID:    r.ParentID,
+	}
+}
+
+// CategoryListRequest represents a request to list categories
+type CategoryListRequest struct {
+	Search string `form:"search" binding:"max=255"`
+}
+
+// CategoryResponse represents a category in the response
+type CategoryResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Disabled    bool   `json:"disabled"`
+	ParentID    *uint  `json:"parent_id"`
+}
+
+// FromCategoryEntity converts an entity.Category to a CategoryResponse
+func FromCategoryEntity(c entity.Category) CategoryResponse {
+	return CategoryResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		Disabled:    c.Disabled,
+		ParentID:    c.ParentID,
+	}
+}
+
+// CategorySummaryResponse represents a listed category with its product count
+type CategorySummaryResponse struct {
+	CategoryResponse
+	ProductCount int `json:"product_count"`
+}
+
+// FromCategorySummary converts an entity.CategorySummary to a CategorySummaryResponse
+func FromCategorySummary(s entity.CategorySummary) CategorySummaryResponse {
+	return CategorySummaryResponse{
+		CategoryResponse: FromCategoryEntity(s.Category),
+		ProductCount:     s.ProductCount,
+	}
+}
+
+// CategoryNodeResponse represents a category with its sub-categories in the response
+type CategoryNodeResponse struct {
+	CategoryResponse
+	Children []CategoryNodeResponse `json:"children"`
+}
+
+// FromCategoryNodes converts a category hierarchy to CategoryNodeResponses
+func FromCategoryNodes(nodes []entity.CategoryNode) []CategoryNodeResponse {
+	responses := make([]CategoryNodeResponse, 0, len(nodes))
+	for _, node := range nodes {
+		responses = append(responses, CategoryNodeResponse{
+			CategoryResponse: FromCategoryEntity(node.Category),
+			Children:         FromCategoryNodes(node.Children),
+		})
+	}
+	return responses
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "math"
+
+// Page sizes of the list endpoints
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// PageRequest represents the pagination parameters of a list request
+type PageRequest struct {
+	Page     int `form:"page,default=1"`
+	PageSize int `form:"page_size,default=10"`
+}
+
+// Normalize replaces out of range values with the defaults: pages start at 1
+// and page sizes above MaxPageSize fall back to DefaultPageSize
+func (r *PageRequest) Normalize() {
+	if r.Page <= 0 {
+		r.Page = 1
+	}
+	if r.PageSize <= 0 || r.PageSize > MaxPageSize {
+		r.PageSize = DefaultPageSize
+	}
+}
+
+// Page represents one page of a paginated list
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	TotalItems int64  `json:"total_items"`
+	TotalPages int    `json:"total_pages"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewPage creates the page of a list requested by req. An empty page has an
+// empty items array rather than null.
+func NewPage[T any](items []T, totalItems int64, req PageRequest) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{
+		Items:      items,
+		TotalItems: totalItems,
+		TotalPages: int(math.Ceil(float64(totalItems) / float64(req.PageSize))),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+	}
+}
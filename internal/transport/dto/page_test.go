@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPageRequest_Normalize(t *testing.T) {
+	tests := []struct {
+		name string
+		req  PageRequest
+		want PageRequest
+	}{
+		{name: "in range", req: PageRequest{Page: 3, PageSize: 25}, want: PageRequest{Page: 3, PageSize: 25}},
+		{name: "zero page", req: PageRequest{Page: 0, PageSize: 25}, want: PageRequest{Page: 1, PageSize: 25}},
+		{name: "negative page", req: PageRequest{Page: -2, PageSize: 25}, want: PageRequest{Page: 1, PageSize: 25}},
+		{name: "zero page size", req: PageRequest{Page: 1, PageSize: 0}, want: PageRequest{Page: 1, PageSize: DefaultPageSize}},
+		{name: "largest page size", req: PageRequest{Page: 1, PageSize: MaxPageSize}, want: PageRequest{Page: 1, PageSize: MaxPageSize}},
+		{name: "page size over the limit", req: PageRequest{Page: 1, PageSize: MaxPageSize + 1}, want: PageRequest{Page: 1, PageSize: DefaultPageSize}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Normalize()
+			if req != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", req, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalItems int64
+		pageSize   int
+		wantPages  int
+	}{
+		{name: "no items", totalItems: 0, pageSize: 10, wantPages: 0},
+		{name: "partial last page", totalItems: 21, pageSize: 10, wantPages: 3},
+		{name: "exact pages", totalItems: 20, pageSize: 10, wantPages: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewPage([]string{"a"}, tt.totalItems, PageRequest{Page: 2, PageSize: tt.pageSize})
+			if page.TotalPages != tt.wantPages {
+				t.Errorf("TotalPages = %d, want %d", page.TotalPages, tt.wantPages)
+			}
+			if page.Page != 2 || page.PageSize != tt.pageSize || page.TotalItems != tt.totalItems {
+				t.Errorf("page = %+v, want the request's page and size", page)
+			}
+		})
+	}
+}
+
+func TestNewPage_EmptyItemsIsAnArray(t *testing.T) {
+	page := NewPage[string](nil, 0, PageRequest{Page: 1, PageSize: 10})
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		t.Fatalf("failed to encode the page: %v", err)
+	}
+	if !strings.Contains(string(data), `"items":[]`) {
+		t.Errorf("page = %s, want an empty items array", data)
+	}
+}
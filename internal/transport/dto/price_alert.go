@@ -0,0 +1,28 @@
+package dto
+
+import "github.com/thanhnguyen/product-api/internal/business/entity"
+
+// PriceAlertRequest represents a request to subscribe to a price-drop alert
+type PriceAlertRequest struct {
+	Threshold float64 `json:"threshold" binding:"required,gt=0"`
+}
+
+// PriceAlertResponse represents a price-drop alert in the response
+type PriceAlertResponse struct {
+	ID         uint    `json:"id"`
+	ProductID  uint    `json:"product_id"`
+	Threshold  float64 `json:"threshold"`
+	NotifiedAt *string `json:"notified_at,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// FromPriceAlertEntity converts an entity.PriceAlert to a PriceAlertResponse
+func FromPriceAlertEntity(a entity.PriceAlert) PriceAlertResponse {
+	return PriceAlertResponse{
+		ID:         a.ID,
+		ProductID:  a.ProductID,
+		Threshold:  a.Threshold,
+		NotifiedAt: formatOptionalTime(a.NotifiedAt),
+		CreatedAt:  formatTime(a.CreatedAt),
+	}
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+)
+
+// PriceScheduleRequest represents a request to create or update a scheduled price change
+type PriceScheduleRequest struct {
+	ProductID   *uint      `json:"product_id"`
+	CategoryID  *uint      `json:"category_id"`
+	NewPrice    float64    `json:"new_price" binding:"required,gt=0"`
+	EffectiveAt time.Time  `json:"effective_at" binding:"required"`
+	RevertAt    *time.Time `json:"revert_at"`
+}
+
+// PriceScheduleResponse represents a scheduled price change in the response
+type PriceScheduleResponse struct {
+	ID          uint    `json:"id"`
+	ProductID   *uint   `json:"product_id,omitempty"`
+	CategoryID  *uint   `json:"category_id,omitempty"`
+	NewPrice    float64 `json:"new_price"`
+	EffectiveAt string  `json:"effective_at"`
+	RevertAt    *string `json:"revert_at,omitempty"`
+	Status      string  `json:"status"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+// ToEntity converts a PriceScheduleRequest to an entity.ScheduledPriceChange
+func (r *PriceScheduleRequest) ToEntity() *entity.ScheduledPriceChange {
+	return &entity.ScheduledPriceChange{
+		ProductID:   r.ProductID,
+		CategoryID:  r.CategoryID,
+		NewPrice:    r.NewPrice,
+		EffectiveAt: r.EffectiveAt,
+		RevertAt:    r.RevertAt,
+	}
+}
+
+// FromScheduleEntity converts an entity.ScheduledPriceChange to a PriceScheduleResponse
+func FromScheduleEntity(s entity.ScheduledPriceChange) PriceScheduleResponse {
+	return PriceScheduleResponse{
+		ID:          s.ID,
+		ProductID:   s.ProductID,
+		CategoryID:  s.CategoryID,
+		NewPrice:    s.NewPrice,
+		EffectiveAt: formatTime(s.EffectiveAt),
+		RevertAt:    formatOptionalTime(s.RevertAt),
+		Status:      s.Status,
+		CreatedAt:   formatTime(s.CreatedAt),
+		UpdatedAt:   formatTime(s.UpdatedAt),
+	}
+}
@@ -1,76 +1,120 @@
 package dto
 
-import (
-	"time"
-
-	"github.com/thanhnguyen/product-api/internal/business/entity"
-)
+import "github.com/thanhnguyen/product-api/internal/business/entity"
 
 // ProductRequest represents a request to create or update a product
 type ProductRequest struct {
 	Name          string  `json:"name" binding:"required"`
+	SKU           string  `json:"sku" binding:"omitempty,max=64"`
 	Description   string  `json:"description" binding:"required"`
 	Price         float64 `json:"price" binding:"required,gt=0"`
 	StockQuantity int     `json:"stock_quantity" binding:"required,gte=0"`
+	Status        string  `json:"status" binding:"omitempty,oneof=active inactive out_of_stock discontinued"`
 	CategoryIDs   []uint  `json:"category_ids" binding:"required"`
 }
 
+// ProductStatusRequest represents a request to change a product's status
+type ProductStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active inactive out_of_stock discontinued"`
+}
+
+// ProductUpdateOptions represents the query options of a product update
+type ProductUpdateOptions struct {
+	// OverrideLocked allows an admin to change fields locked after publish
+	OverrideLocked bool `form:"override_locked"`
+}
+
 // ProductResponse represents a product in the response
 type ProductResponse struct {
 	ID            uint     `json:"id"`
 	Name          string   `json:"name"`
+	SKU           string   `json:"sku"`
 	Description   string   `json:"description"`
 	Price         float64  `json:"price"`
 	StockQuantity int      `json:"stock_quantity"`
 	Status        string   `json:"status"`
+	Archived      bool     `json:"archived"`
+	ArchivedAt    *string  `json:"archived_at,omitempty"`
 	Categories    []string `json:"categories"`
 	CreatedAt     string   `json:"created_at"`
 	UpdatedAt     string   `json:"updated_at"`
 }
 
+// ProductDetailRequest represents a request to get a product
+type ProductDetailRequest struct {
+	Include string `form:"include"`
+}
+
+// ProductDetailResponse represents a product with optionally embedded reviews
+type ProductDetailResponse struct {
+	ProductResponse
+	Reviews []ReviewResponse `json:"reviews,omitempty"`
+}
+
 // ProductListRequest represents a request to list products
 type ProductListRequest struct {
-	Search     string   `form:"search"`
-	Page       int      `form:"page,default=1"`
-	PageSize   int      `form:"page_size,default=10"`
-	CategoryID uint     `form:"category_id"`
-	MinPrice   *float64 `form:"min_price"`
-	MaxPrice   *float64 `form:"max_price"`
-	SortBy     string   `form:"sort_by"`
-	SortOrder  string   `form:"sort_order"`
+	PageRequest
+	Search          string   `form:"search"`
+	CategoryID      uint     `form:"category_id"`
+	MinPrice        *float64 `form:"min_price"`
+	MaxPrice        *float64 `form:"max_price"`
+	SortBy          string   `form:"sort_by" binding:"omitempty,oneof=id name price created_at stock_quantity"`
+	SortOrder       string   `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+	IncludeArchived bool     `form:"include_archived"`
+	// Cursor is the next_cursor of the previous page. It replaces page.
+	Cursor string `form:"cursor"`
+}
+
+// NewArrivalsRequest represents a request to list recently added products.
+// Without days the configured window is used.
+type NewArrivalsRequest struct {
+	Days  int `form:"days" binding:"omitempty,min=1,max=365"`
+	Limit int `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+// ProductSearchRequest represents a request to search products
+type ProductSearchRequest struct {
+	PageRequest
+	Query string `form:"query" binding:"required"`
 }
 
-// ProductListResponse represents a paginated list of products
-type ProductListResponse struct {
-	Items      []ProductResponse `json:"items"`
-	TotalItems int64             `json:"total_items"`
-	TotalPages int               `json:"total_pages"`
-	Page       int               `json:"page"`
-	PageSize   int               `json:"page_size"`
+// ProductDescriptionSearchRequest represents a request to search products by description
+type ProductDescriptionSearchRequest struct {
+	PageRequest
+	Query string `form:"query" binding:"required"`
+}
+
+// ProductSearchResultResponse represents a product found by a search, with the
+// matched text fragments
+type ProductSearchResultResponse struct {
+	ProductResponse
+	Highlights []string `json:"highlights,omitempty"`
 }
 
 // ToEntity converts a ProductRequest to an entity.Product
 func (r *ProductRequest) ToEntity() *entity.Product {
 	return &entity.Product{
 		Name:          r.Name,
+		SKU:           r.SKU,
 		Description:   r.Description,
 		Price:         r.Price,
 		StockQuantity: r.StockQuantity,
-		Status:        "active", // Default status
+		Status:        r.Status,
 	}
 }
 
 // ToProductFilter converts a ProductListRequest to an entity.ProductFilter
 func (r *ProductListRequest) ToProductFilter() entity.ProductFilter {
 	return entity.ProductFilter{
-		Search:     r.Search,
-		Page:       r.Page,
-		PageSize:   r.PageSize,
-		CategoryID: r.CategoryID,
-		MinPrice:   r.MinPrice,
-		MaxPrice:   r.MaxPrice,
-		SortBy:     r.SortBy,
-		SortOrder:  r.SortOrder,
+		Search:          r.Search,
+		Page:            r.Page,
+		PageSize:        r.PageSize,
+		CategoryID:      r.CategoryID,
+		MinPrice:        r.MinPrice,
+		MaxPrice:        r.MaxPrice,
+		SortBy:          r.SortBy,
+		SortOrder:       r.SortOrder,
+		IncludeArchived: r.IncludeArchived,
 	}
 }
 
@@ -85,12 +129,158 @@ func FromEntity(p entity.Product) ProductResponse {
 	return ProductResponse{
 		ID:            p.ID,
 		Name:          p.Name,
+		SKU:           p.SKU,
 		Description:   p.Description,
 		Price:         p.Price,
 		StockQuantity: p.StockQuantity,
 		Status:        p.Status,
+		Archived:      p.Archived,
+		ArchivedAt:    formatOptionalTime(p.ArchivedAt),
 		Categories:    categories,
-		CreatedAt:     p.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:     p.UpdatedAt.Format(time.RFC3339),
+		CreatedAt:     formatTime(p.CreatedAt),
+		UpdatedAt:     formatTime(p.UpdatedAt),
+	}
+}
+
+// BulkPriceRequest represents a request to adjust the price of many products at once.
+// Explicit product IDs take precedence over the filter.
+type BulkPriceRequest struct {
+	ProductIDs []uint           `json:"product_ids"`
+	Filter     *BulkPriceFilter `json:"filter"`
+	Type       string           `json:"type" binding:"required,oneof=percentage absolute"`
+	Value      float64          `json:"value" binding:"required"`
+	MinPrice   float64          `json:"min_price" binding:"gte=0"`
+}
+
+// BulkPriceOptions represents the query options of a bulk price update
+type BulkPriceOptions struct {
+	// DryRun returns the price changes without applying them
+	DryRun bool `form:"dry_run"`
+}
+
+// BulkPriceFilter selects the products affected by a bulk price update
+type BulkPriceFilter struct {
+	Search     string   `json:"search"`
+	CategoryID uint     `json:"category_id"`
+	MinPrice   *float64 `json:"min_price"`
+	MaxPrice   *float64 `json:"max_price"`
+}
+
+// ConsistencyCheckOptions represents the query options of a search index
+// consistency check
+type ConsistencyCheckOptions struct {
+	// Repair re-indexes missing and stale products and removes orphaned documents
+	Repair bool `form:"repair"`
+}
+
+// StockAdjustmentRequest represents a request to adjust the stock of many
+// products at once, all or nothing
+type StockAdjustmentRequest struct {
+	Items []StockAdjustmentItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// StockAdjustmentItem represents the stock change of a single product.
+// Negative deltas take stock out.
+type StockAdjustmentItem struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Delta     int  `json:"delta" binding:"required"`
+}
+
+// StockChangeResponse represents the effect of a stock adjustment on a single product
+type StockChangeResponse struct {
+	ProductID   uint   `json:"product_id"`
+	Name        string `json:"name"`
+	OldQuantity int    `json:"old_quantity"`
+	NewQuantity int    `json:"new_quantity"`
+}
+
+// StockAdjustmentResponse represents the result of a stock adjustment
+type StockAdjustmentResponse struct {
+	Updated int                   `json:"updated"`
+	Changes []StockChangeResponse `json:"changes"`
+}
+
+// ToDeltas converts a StockAdjustmentRequest to stock deltas by product ID.
+// Items for the same product are added up.
+func (r *StockAdjustmentRequest) ToDeltas() map[uint]int {
+	deltas := make(map[uint]int, len(r.Items))
+	for _, item := range r.Items {
+		deltas[item.ProductID] += item.Delta
+	}
+	return deltas
+}
+
+// FromStockChanges converts stock changes to a StockAdjustmentResponse
+func FromStockChanges(changes []entity.StockChange) StockAdjustmentResponse {
+	items := make([]StockChangeResponse, 0, len(changes))
+	for _, c := range changes {
+		items = append(items, StockChangeResponse{
+			ProductID:   c.ProductID,
+			Name:        c.Name,
+			OldQuantity: c.OldQuantity,
+			NewQuantity: c.NewQuantity,
+		})
+	}
+
+	return StockAdjustmentResponse{
+		Updated: len(items),
+		Changes: items,
+	}
+}
+
+// PriceChangeResponse represents the effect of a price update on a single product
+type PriceChangeResponse struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	OldPrice  float64 `json:"old_price"`
+	NewPrice  float64 `json:"new_price"`
+}
+
+// BulkPriceResponse represents the result of a bulk price update
+type BulkPriceResponse struct {
+	Updated int                   `json:"updated"`
+	DryRun  bool                  `json:"dry_run"`
+	Changes []PriceChangeResponse `json:"changes"`
+}
+
+// ToEntity converts a BulkPriceRequest to an entity.BulkPriceUpdate
+func (r *BulkPriceRequest) ToEntity(opts BulkPriceOptions) entity.BulkPriceUpdate {
+	update := entity.BulkPriceUpdate{
+		DryRun:     opts.DryRun,
+		ProductIDs: r.ProductIDs,
+		Adjustment: entity.PriceAdjustment{
+			Type:     r.Type,
+			Value:    r.Value,
+			MinPrice: r.MinPrice,
+		},
+	}
+	if r.Filter != nil {
+		update.Filter = entity.ProductFilter{
+			Search:     r.Filter.Search,
+			CategoryID: r.Filter.CategoryID,
+			MinPrice:   r.Filter.MinPrice,
+			MaxPrice:   r.Filter.MaxPrice,
+		}
+	}
+	return update
+}
+
+// FromPriceChanges converts price changes to a BulkPriceResponse. Updated
+// counts the products that changed, or would change in a dry run.
+func FromPriceChanges(changes []entity.PriceChange, dryRun bool) BulkPriceResponse {
+	items := make([]PriceChangeResponse, 0, len(changes))
+	for _, c := range changes {
+		items = append(items, PriceChangeResponse{
+			ProductID: c.ProductID,
+			Name:      c.Name,
+			OldPrice:  c.OldPrice,
+			NewPrice:  c.NewPrice,
+		})
+	}
+
+	return BulkPriceResponse{
+		Updated: len(items),
+		DryRun:  dryRun,
+		Changes: items,
 	}
 }
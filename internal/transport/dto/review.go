@@ -0,0 +1,61 @@
+package dto
+
+import "github.com/thanhnguyen/product-api/internal/business/entity"
+
+// ReviewRequest represents a request to review a product
+type ReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+// ReviewListRequest represents a request to list a product's reviews
+type ReviewListRequest struct {
+	PageRequest
+}
+
+// ReviewedNotWishlistedRequest represents a request to list reviewed products missing from the wishlist
+type ReviewedNotWishlistedRequest struct {
+	MinRating int `form:"min_rating,default=4"`
+}
+
+// ReviewResponse represents a review in the response
+type ReviewResponse struct {
+	ID        uint   `json:"id"`
+	ProductID uint   `json:"product_id"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username,omitempty"`
+	Rating    int    `json:"rating"`
+	Comment   string `json:"comment"`
+	CreatedAt string `json:"created_at"`
+}
+
+// FromReviewEntity converts an entity.Review to a ReviewResponse
+func FromReviewEntity(r entity.Review) ReviewResponse {
+	return ReviewResponse{
+		ID:        r.ID,
+		ProductID: r.ProductID,
+		UserID:    r.UserID,
+		Username:  r.User.Username,
+		Rating:    r.Rating,
+		Comment:   r.Comment,
+		CreatedAt: formatTime(r.CreatedAt),
+	}
+}
+
+// ReviewSummaryResponse represents a product's rating summary in the response
+type ReviewSummaryResponse struct {
+	ProductID    uint        `json:"product_id"`
+	Average      float64     `json:"average"`
+	Total        int         `json:"total"`
+	Distribution map[int]int `json:"distribution"`
+}
+
+// FromReviewSummaryEntity converts an entity.ReviewSummary to a ReviewSummaryResponse
+func FromReviewSummaryEntity(s entity.ReviewSummary) ReviewSummaryResponse {
+	return ReviewSummaryResponse{
+		ProductID:    s.ProductID,
+		Average:      s.Average,
+		Total:        s.Total,
+		Distribution: s.Distribution,
+	}
+}
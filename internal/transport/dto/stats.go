@@ -0,0 +1,38 @@
+package dto
+
+import "github.com/thanhnguyen/product-api/internal/business/entity"
+
+// CategoryStatsRequest represents a request for product counts by category
+type CategoryStatsRequest struct {
+	// Rollup counts the products of a category's sub-categories as its own
+	Rollup bool `form:"rollup"`
+}
+
+// StatsExportRequest represents a request to export statistics
+type StatsExportRequest struct {
+	Type   string `form:"type" binding:"required,oneof=category wishlist top-products"`
+	Format string `form:"format,default=csv" binding:"oneof=csv"`
+}
+
+// StatsResponse represents the statistics overview
+type StatsResponse struct {
+	TotalProducts int64   `json:"total_products"`
+	TotalUsers    int64   `json:"total_users"`
+	TotalReviews  int64   `json:"total_reviews"`
+	AverageRating float64 `json:"average_rating"`
+	LastRefreshed string  `json:"last_refreshed"`
+	// Misc holds the other cached statistics, e.g. the top products
+	Misc map[string]interface{} `json:"misc,omitempty"`
+}
+
+// FromStatsEntity converts an entity.Stats to a StatsResponse
+func FromStatsEntity(s entity.Stats) StatsResponse {
+	return StatsResponse{
+		TotalProducts: s.TotalProducts,
+		TotalUsers:    s.TotalUsers,
+		TotalReviews:  s.TotalReviews,
+		AverageRating: s.AverageRating,
+		LastRefreshed: formatTime(s.LastRefreshed),
+		Misc:          s.Misc,
+	}
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// TimestampFormat is the layout of every timestamp in a response
+const TimestampFormat = time.RFC3339
+
+// formatTime formats a timestamp for a response
+func formatTime(t time.Time) string {
+	return t.Format(TimestampFormat)
+}
+
+// formatOptionalTime formats an optional timestamp for a response, keeping nil as nil
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := formatTime(*t)
+	return &formatted
+}
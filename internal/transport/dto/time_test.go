@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTime(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{name: "utc", t: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), want: "2024-05-01T12:30:00Z"},
+		{name: "fractional seconds dropped", t: time.Date(2024, 5, 1, 12, 30, 0, 999999999, time.UTC), want: "2024-05-01T12:30:00Z"},
+		{name: "offset kept", t: time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("ICT", 7*60*60)), want: "2024-05-01T12:30:00+07:00"},
+		{name: "zero", t: time.Time{}, want: "0001-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTime(tt.t); got != tt.want {
+				t.Errorf("formatTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatOptionalTime(t *testing.T) {
+	set := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	zero := time.Time{}
+
+	tests := []struct {
+		name string
+		t    *time.Time
+		want *string
+	}{
+		{name: "nil", t: nil, want: nil},
+		{name: "set", t: &set, want: stringPtr("2024-05-01T12:30:00Z")},
+		{name: "zero", t: &zero, want: stringPtr("0001-01-01T00:00:00Z")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatOptionalTime(tt.t)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil:
+				t.Errorf("formatOptionalTime() = %v, want %v", got, tt.want)
+			case *got != *tt.want:
+				t.Errorf("formatOptionalTime() = %q, want %q", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
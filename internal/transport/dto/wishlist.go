@@ -0,0 +1,46 @@
+package dto
+
+import "github.com/thanhnguyen/product-api/internal/business/entity"
+
+// WishlistListRequest represents a request to list the current user's wishlist
+type WishlistListRequest struct {
+	PageRequest
+}
+
+// AvailabilityResponse represents whether a wishlisted product can currently be ordered
+type AvailabilityResponse struct {
+	ProductID     uint   `json:"product_id"`
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	StockQuantity int    `json:"stock_quantity"`
+	Available     bool   `json:"available"`
+}
+
+// WishlistAvailabilityResponse represents the availability of every product in a wishlist
+type WishlistAvailabilityResponse struct {
+	Available   int                    `json:"available"`
+	Unavailable int                    `json:"unavailable"`
+	Items       []AvailabilityResponse `json:"items"`
+}
+
+// FromAvailability converts product availability to a WishlistAvailabilityResponse
+func FromAvailability(availability []entity.ProductAvailability) WishlistAvailabilityResponse {
+	resp := WishlistAvailabilityResponse{
+		Items: make([]AvailabilityResponse, 0, len(availability)),
+	}
+	for _, a := range availability {
+		resp.Items = append(resp.Items, AvailabilityResponse{
+			ProductID:     a.ProductID,
+			Name:          a.Name,
+			Status:        a.Status,
+			StockQuantity: a.StockQuantity,
+			Available:     a.Available,
+		})
+		if a.Available {
+			resp.Available++
+		} else {
+			resp.Unavailable++
+		}
+	}
+	return resp
+}
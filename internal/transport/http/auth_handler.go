@@ -0,0 +1,146 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
+	"github.com/thanhnguyen/product-api/internal/transport/http/middleware"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// AuthHandler handles HTTP requests for registration and login
+type AuthHandler struct {
+	authUseCase    usecase.AuthUseCase
+	authMiddleware *middleware.JWTAuthMiddleware
+	logger         *logger.Logger
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(authUseCase usecase.AuthUseCase, authMiddleware *middleware.JWTAuthMiddleware, logger *logger.Logger) *AuthHandler {
+	return &AuthHandler{
+		authUseCase:    authUseCase,
+		authMiddleware: authMiddleware,
+		logger:         logger,
+	}
+}
+
+// Register handles user registration
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req dto.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Convert DTO to entity
+	user := req.ToEntity()
+
+	// Call use case
+	if err := h.authUseCase.Register(c.Request.Context(), user, req.Password); err != nil {
+		if errors.Is(err, usecase.ErrUserExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username or email already exists"})
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to register user")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+		return
+	}
+
+	h.respondWithToken(c, http.StatusCreated, user)
+}
+
+// Login handles user login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req dto.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Call use case
+	user, err := h.authUseCase.Login(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to log in user")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	h.respondWithToken(c, http.StatusOK, user)
+}
+
+// ChangePassword handles changing the current user's password. The response
+// carries a fresh token, which no longer requires a password change.
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Call use case
+	user, err := h.authUseCase.ChangePassword(c.Request.Context(), userID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		var validationErr *usecase.ValidationError
+		switch {
+		case errors.Is(err, usecase.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		default:
+			h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to change password")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		}
+		return
+	}
+
+	h.respondWithToken(c, http.StatusOK, user)
+}
+
+// Logout removes the token cookie. Tokens sent in the Authorization header
+// remain valid until they expire.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	h.authMiddleware.ClearTokenCookie(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// respondWithToken issues a token for the user and writes the auth response
+func (h *AuthHandler) respondWithToken(c *gin.Context, status int, user *entity.User) {
+	token, err := h.authMiddleware.GenerateToken(user)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to generate token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	h.authMiddleware.SetTokenCookie(c, token)
+
+	c.JSON(status, dto.AuthResponse{
+		Token:   token,
+		Expires: time.Now().Add(h.authMiddleware.TokenDuration()),
+		User:    dto.FromUserEntity(*user),
+	})
+}
+
+// RegisterRoutes registers the auth routes
+func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
+	auth := router.Group("/auth")
+	{
+		auth.POST("/register", h.Register)
+		auth.POST("/login", h.Login)
+		auth.POST("/logout", h.Logout)
+	}
+}
@@ -0,0 +1,22 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkBatchSize rejects a bulk request with more than maxSize items with a
+// 400. It reports whether the request may proceed.
+func checkBatchSize(c *gin.Context, size, maxSize int) bool {
+	if maxSize <= 0 || size <= maxSize {
+		return true
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":          fmt.Sprintf("Batch of %d items exceeds the limit of %d", size, maxSize),
+		"max_batch_size": maxSize,
+	})
+	return false
+}
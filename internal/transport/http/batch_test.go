@@ -0,0 +1,90 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckBatchSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		maxSize int
+		want    bool
+	}{
+		{name: "below the limit", size: 2, maxSize: 3, want: true},
+		{name: "at the limit", size: 3, maxSize: 3, want: true},
+		{name: "over the limit", size: 4, maxSize: 3, want: false},
+		{name: "no limit", size: 10000, maxSize: 0, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(batchRouter(tt.size, tt.maxSize), http.MethodGet, "/", "")
+			if got := w.Code == http.StatusOK; got != tt.want {
+				t.Fatalf("allowed = %v, want %v (status %d)", got, tt.want, w.Code)
+			}
+			if tt.want {
+				return
+			}
+
+			var body struct {
+				Error        string `json:"error"`
+				MaxBatchSize int    `json:"max_batch_size"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode the response: %v", err)
+			}
+			if w.Code != http.StatusBadRequest || body.MaxBatchSize != tt.maxSize {
+				t.Errorf("response = %d %+v, want 400 with the limit", w.Code, body)
+			}
+		})
+	}
+}
+
+// batchRouter serves a route checking a batch of size items against maxSize
+func batchRouter(size, maxSize int) *gin.Engine {
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		if checkBatchSize(c, size, maxSize) {
+			c.Status(http.StatusOK)
+		}
+	})
+	return router
+}
+
+func TestBulkRoutes_RejectOversizedBatches(t *testing.T) {
+	// The use case is unset, so reaching it would panic
+	router := gin.New()
+	NewProductHandler(&fakeProductUseCase{}, nil, testLogger(), 2, CachePolicy{}, 2, 7).RegisterAdminRoutes(router.Group(""))
+
+	items := make([]string, 3)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"product_id":%d,"delta":1}`, i+1)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "bulk price", path: "/products/bulk-price", body: `{"product_ids":[1,2,3],"type":"percentage","value":10}`},
+		{name: "stock adjustments", path: "/products/stock-adjustments", body: `{"items":[` + strings.Join(items, ",") + `]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(router, http.MethodPost, tt.path, tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), "Batch of 3 items exceeds the limit of 2") {
+				t.Errorf("unexpected error: %s", w.Body.String())
+			}
+		})
+	}
+}
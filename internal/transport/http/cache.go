@@ -0,0 +1,53 @@
+package http
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CachePolicy is how long clients may cache the responses of a kind of route
+// and whether shared caches such as CDNs may store them too
+type CachePolicy struct {
+	MaxAge time.Duration
+	Public bool
+}
+
+// private returns the policy restricted to the client's own cache, for
+// responses whose body depends on the caller
+func (p CachePolicy) private() CachePolicy {
+	p.Public = false
+	return p
+}
+
+// cacheFor lets caches store a successful response for the policy's max-age.
+// Public responses may be stored by shared caches; the Vary header still keys
+// them by the caller's credentials, so a cache never serves one user's response
+// to another. A non-positive max-age disables caching.
+func cacheFor(c *gin.Context, policy CachePolicy) {
+	varyByCaller(c)
+	if policy.MaxAge <= 0 {
+		c.Header("Cache-Control", "no-store")
+		return
+	}
+	visibility := "private"
+	if policy.Public {
+		visibility = "public"
+	}
+	c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, int(policy.MaxAge.Seconds())))
+}
+
+// varyByCaller marks a response as depending on the caller's credentials, sent
+// as a bearer token or a cookie, and accepted content type
+func varyByCaller(c *gin.Context) {
+	c.Writer.Header().Add("Vary", "Authorization, Cookie, Accept")
+}
+
+// noStore returns middleware that forbids caching any response of a route group
+func noStore() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		c.Next()
+	}
+}
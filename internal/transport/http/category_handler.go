@@ -0,0 +1,232 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// CategoryHandler handles HTTP requests for categories
+type CategoryHandler struct {
+	categoryUseCase usecase.CategoryUseCase
+	logger          *logger.Logger
+	cachePolicy     CachePolicy
+}
+
+// NewCategoryHandler creates a new CategoryHandler. Category lists are cached
+// following cachePolicy.
+func NewCategoryHandler(categoryUseCase usecase.CategoryUseCase, logger *logger.Logger, cachePolicy CachePolicy) *CategoryHandler {
+	return &CategoryHandler{
+		categoryUseCase: categoryUseCase,
+		logger:          logger,
+		cachePolicy:     cachePolicy,
+	}
+}
+
+// CreateCategory handles category creation
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req dto.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := req.ToEntity()
+	if err := h.categoryUseCase.CreateCategory(c.Request.Context(), category); err != nil {
+		h.handleError(c, err, "Failed to create category")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromCategoryEntity(*category))
+}
+
+// ListCategories handles listing categories, optionally filtered by name.
+// Disabled categories can't be used by products, so only admins see them.
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	var req dto.CategoryListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	includeDisabled := currentUserRole(c) == "admin"
+	categories, err := h.categoryUseCase.ListCategories(c.Request.Context(), entity.CategoryFilter{
+		Name:            req.Search,
+		IncludeDisabled: includeDisabled,
+	})
+	if err != nil {
+		h.handleError(c, err, "Failed to list categories")
+		return
+	}
+
+	items := make([]dto.CategorySummaryResponse, 0, len(categories))
+	for _, category := range categories {
+		items = append(items, dto.FromCategorySummary(category))
+	}
+
+	// The admin listing includes disabled categories, which shared caches must not store
+	policy := h.cachePolicy
+	if includeDisabled {
+		policy = policy.private()
+	}
+	cacheFor(c, policy)
+	c.JSON(http.StatusOK, gin.H{"categories": items})
+}
+
+// GetCategoryTree handles getting the category hierarchy
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	tree, err := h.categoryUseCase.CategoryTree(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to get category tree")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": dto.FromCategoryNodes(tree)})
+}
+
+// GetCategory handles getting a category by ID
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	category, err := h.categoryUseCase.GetCategory(c.Request.Context(), uint(id))
+	if err != nil {
+		h.handleError(c, err, "Failed to get category")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromCategoryEntity(*category))
+}
+
+// UpdateCategory handles renaming a category, changing its description or
+// moving it under another parent
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var req dto.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := req.ToEntity()
+	category.ID = uint(id)
+	if err := h.categoryUseCase.UpdateCategory(c.Request.Context(), category); err != nil {
+		h.handleError(c, err, "Failed to update category")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromCategoryEntity(*category))
+}
+
+// DeleteCategory handles category deletion. With ?reassign_to= the category's
+// products are moved to that category instead of losing the category. Without
+// it, a category that still has products is only deleted if deletes cascade.
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var reassignTo *uint
+	if param := c.Query("reassign_to"); param != "" {
+		target, err := strconv.ParseUint(param, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reassign_to category ID"})
+			return
+		}
+		targetID := uint(target)
+		reassignTo = &targetID
+	}
+
+	if err := h.categoryUseCase.DeleteCategory(c.Request.Context(), uint(id), reassignTo); err != nil {
+		h.handleError(c, err, "Failed to delete category")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+// DisableCategory handles disabling a category
+func (h *CategoryHandler) DisableCategory(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+// EnableCategory handles re-enabling a disabled category
+func (h *CategoryHandler) EnableCategory(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+// setDisabled disables or re-enables the category in the URL
+func (h *CategoryHandler) setDisabled(c *gin.Context, disabled bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	if disabled {
+		err = h.categoryUseCase.DisableCategory(c.Request.Context(), uint(id))
+	} else {
+		err = h.categoryUseCase.EnableCategory(c.Request.Context(), uint(id))
+	}
+	if err != nil {
+		h.handleError(c, err, "Failed to update category")
+		return
+	}
+
+	if disabled {
+		c.JSON(http.StatusOK, gin.H{"message": "Category disabled successfully"})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"message": "Category enabled successfully"})
+	}
+}
+
+// handleError maps use case errors to HTTP responses
+func (h *CategoryHandler) handleError(c *gin.Context, err error, message string) {
+	var validationErr *usecase.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.Is(err, usecase.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	case errors.Is(err, usecase.ErrCategoryInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "Category still has products; pass reassign_to to move them"})
+	default:
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// RegisterRoutes registers the category routes open to every authenticated user
+func (h *CategoryHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/categories", h.ListCategories)
+}
+
+// RegisterAdminRoutes registers the category routes that require the admin role
+func (h *CategoryHandler) RegisterAdminRoutes(router *gin.RouterGroup) {
+	categories := router.Group("/categories")
+	{
+		categories.POST("", h.CreateCategory)
+		categories.GET("/tree", h.GetCategoryTree)
+		categories.GET("/:id", h.GetCategory)
+		categories.PUT("/:id", h.UpdateCategory)
+		categories.DELETE("/:id", h.DeleteCategory)
+		categories.POST("/:id/disable", h.DisableCategory)
+		categories.POST("/:id/enable", h.EnableCategory)
+	}
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+)
+
+// fakeCategoryUseCase lists a fixed category
+type fakeCategoryUseCase struct {
+	usecase.CategoryUseCase
+}
+
+func (f *fakeCategoryUseCase) ListCategories(ctx context.Context, filter entity.CategoryFilter) ([]entity.CategorySummary, error) {
+	return []entity.CategorySummary{{Category: entity.Category{ID: 1, Name: "Books"}, ProductCount: 12}}, nil
+}
+
+// newCategoryRouter serves the category list to a caller with the given role
+func newCategoryRouter(policy CachePolicy, role string) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("role", role)
+	})
+	NewCategoryHandler(&fakeCategoryUseCase{}, testLogger(), policy).RegisterRoutes(router.Group(""))
+	return router
+}
+
+func TestListCategories_CacheControl(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy CachePolicy
+		role   string
+		want   string
+	}{
+		{
+			name:   "public",
+			policy: CachePolicy{MaxAge: 5 * time.Minute, Public: true},
+			role:   "user",
+			want:   "public, max-age=300",
+		},
+		{
+			name:   "private",
+			policy: CachePolicy{MaxAge: 5 * time.Minute},
+			role:   "user",
+			want:   "private, max-age=300",
+		},
+		{
+			name:   "admin listing with disabled categories",
+			policy: CachePolicy{MaxAge: 5 * time.Minute, Public: true},
+			role:   "admin",
+			want:   "private, max-age=300",
+		},
+		{
+			name:   "caching disabled",
+			policy: CachePolicy{Public: true},
+			role:   "user",
+			want:   "no-store",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(newCategoryRouter(tt.policy, tt.role), http.MethodGet, "/categories", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCategories_VariesByCaller(t *testing.T) {
+	w := performRequest(newCategoryRouter(CachePolicy{MaxAge: time.Minute, Public: true}, "user"), http.MethodGet, "/categories", "")
+
+	if got := w.Header().Get("Vary"); got != "Authorization, Cookie, Accept" {
+		t.Errorf("Vary = %q, want %q", got, "Authorization, Cookie, Accept")
+	}
+}
@@ -0,0 +1,18 @@
+package http
+
+import "github.com/gin-gonic/gin"
+
+// currentUserID returns the authenticated user's ID set by the auth middleware
+func currentUserID(c *gin.Context) (uint, bool) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return 0, false
+	}
+	userID, ok := value.(uint)
+	return userID, ok
+}
+
+// currentUserRole returns the authenticated user's role set by the auth middleware
+func currentUserRole(c *gin.Context) string {
+	return c.GetString("role")
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Dependency health statuses
+const (
+	dependencyUp       = "up"
+	dependencyDegraded = "degraded"
+	dependencyDown     = "down"
+)
+
+// healthCheck pings a dependency reported by the health endpoint
+type healthCheck struct {
+	name string
+	ping func(ctx context.Context) error
+}
+
+// dependencyHealth is a dependency's status in the health response
+type dependencyHealth struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// AddHealthCheck registers a dependency whose ping latency is reported by the
+// health endpoint. Checks must be added before the server is started.
+func (s *Server) AddHealthCheck(name string, ping func(ctx context.Context) error) {
+	s.healthChecks = append(s.healthChecks, healthCheck{name: name, ping: ping})
+}
+
+// healthCheck handles the health check endpoint. Dependencies slower than the
+// configured threshold are reported as degraded; the endpoint itself always
+// answers 200 so a slow dependency never takes the service out of rotation.
+func (s *Server) healthCheck(c *gin.Context) {
+	dependencies := s.checkDependencies(c.Request.Context())
+
+	// The overall status is the worst dependency status
+	status := "UP"
+	for _, health := range dependencies {
+		if health.Status == dependencyDown {
+			status = "DOWN"
+			break
+		}
+		if health.Status == dependencyDegraded {
+			status = "DEGRADED"
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":       status,
+		"time":         time.Now().Format(time.RFC3339),
+		"dependencies": dependencies,
+	})
+}
+
+// readyCheck handles the readiness endpoint. It answers 503 while any
+// dependency is down so the instance is taken out of rotation until it
+// recovers; slow dependencies don't affect readiness.
+func (s *Server) readyCheck(c *gin.Context) {
+	dependencies := s.checkDependencies(c.Request.Context())
+
+	status, code := "READY", http.StatusOK
+	for _, health := range dependencies {
+		if health.Status == dependencyDown {
+			status, code = "NOT_READY", http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	c.JSON(code, gin.H{
+		"status":       status,
+		"time":         time.Now().Format(time.RFC3339),
+		"dependencies": dependencies,
+	})
+}
+
+// checkDependencies pings every dependency concurrently
+func (s *Server) checkDependencies(ctx context.Context) map[string]dependencyHealth {
+	var (
+		wg           sync.WaitGroup
+		mu           sync.Mutex
+		dependencies = make(map[string]dependencyHealth, len(s.healthChecks))
+	)
+
+	for _, check := range s.healthChecks {
+		wg.Add(1)
+		go func(check healthCheck) {
+			defer wg.Done()
+			health := s.pingDependency(ctx, check)
+
+			mu.Lock()
+			dependencies[check.name] = health
+			mu.Unlock()
+		}(check)
+	}
+
+	wg.Wait()
+
+	return dependencies
+}
+
+// pingDependency measures a dependency's ping latency
+func (s *Server) pingDependency(ctx context.Context, check healthCheck) dependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Health.CheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.ping(ctx)
+	latency := time.Since(start)
+
+	health := dependencyHealth{
+		Status:    dependencyUp,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	switch {
+	case err != nil:
+		health.Status = dependencyDown
+		health.Error = err.Error()
+	case latency > s.config.Health.DegradedThreshold:
+		health.Status = dependencyDegraded
+	}
+
+	return health
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// healthResponse is the body of the health and readiness endpoints
+type healthResponse struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]dependencyHealth `json:"dependencies"`
+}
+
+func getHealth(t *testing.T, s *Server, path string, wantCode int) healthResponse {
+	t.Helper()
+	w := performRequest(s.router, http.MethodGet, path, "")
+	if w.Code != wantCode {
+		t.Fatalf("%s status = %d, want %d: %s", path, w.Code, wantCode, w.Body.String())
+	}
+	var body healthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+	return body
+}
+
+func fakePing(delay time.Duration, err error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		time.Sleep(delay)
+		return err
+	}
+}
+
+func TestHealthCheck_ReportsDependencyLatency(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.config.Health.DegradedThreshold = 20 * time.Millisecond
+	s.config.Health.CheckTimeout = time.Second
+	s.AddHealthCheck("database", fakePing(0, nil))
+	s.AddHealthCheck("elasticsearch", fakePing(40*time.Millisecond, nil))
+
+	body := getHealth(t, s, "/health", http.StatusOK)
+	if body.Status != "DEGRADED" {
+		t.Errorf("status = %q, want DEGRADED", body.Status)
+	}
+	if got := body.Dependencies["database"]; got.Status != dependencyUp || got.Error != "" {
+		t.Errorf("database = %+v, want up", got)
+	}
+	es := body.Dependencies["elasticsearch"]
+	if es.Status != dependencyDegraded || es.LatencyMs < 40 {
+		t.Errorf("elasticsearch = %+v, want degraded after at least 40ms", es)
+	}
+
+	// Slow dependencies don't affect readiness
+	if body := getHealth(t, s, "/ready", http.StatusOK); body.Status != "READY" {
+		t.Errorf("readiness = %q, want READY", body.Status)
+	}
+}
+
+func TestHealthCheck_DownDependency(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.config.Health.DegradedThreshold = time.Second
+	s.config.Health.CheckTimeout = 20 * time.Millisecond
+	s.AddHealthCheck("database", fakePing(0, nil))
+	s.AddHealthCheck("redis", fakePing(0, errors.New("connection refused")))
+	s.AddHealthCheck("elasticsearch", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// The health endpoint still answers 200 so the instance stays in rotation
+	body := getHealth(t, s, "/health", http.StatusOK)
+	if body.Status != "DOWN" {
+		t.Errorf("status = %q, want DOWN", body.Status)
+	}
+	if got := body.Dependencies["redis"]; got.Status != dependencyDown || got.Error != "connection refused" {
+		t.Errorf("redis = %+v, want down with its error", got)
+	}
+	if got := body.Dependencies["elasticsearch"]; got.Status != dependencyDown || got.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("elasticsearch = %+v, want down after the check timeout", got)
+	}
+
+	if body := getHealth(t, s, "/ready", http.StatusServiceUnavailable); body.Status != "NOT_READY" {
+		t.Errorf("readiness = %q, want NOT_READY", body.Status)
+	}
+}
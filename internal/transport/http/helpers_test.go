@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/config"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testLogger returns a logger that only reports errors
+func testLogger() *logger.Logger {
+	return logger.NewLogger("error", "text", "stdout")
+}
+
+// performRequest sends a request with an optional JSON body to handler and
+// returns the recorded response
+func performRequest(handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	return performRequestAs(handler, method, path, body, "")
+}
+
+// performRequestAs is performRequest with a bearer token, if any
+func performRequestAs(handler http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// newTestServer builds a server from the environment's configuration with
+// the given stats use case; the other use cases are left unset
+func newTestServer(t *testing.T, statsUseCase usecase.StatsUseCase) *Server {
+	t.Helper()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	log := testLogger()
+	hub := NewWebSocketHub(time.Second, nil)
+	s := NewServer(cfg, log, nil, nil, statsUseCase, nil, nil, nil, nil, nil, hub)
+	t.Cleanup(s.stopBackground)
+	return s
+}
+
+// tokenFor returns a token the server accepts for a user with the given role
+func tokenFor(t *testing.T, s *Server, role string) string {
+	t.Helper()
+	token, err := s.authMiddleware.GenerateToken(&entity.User{ID: 1, Email: role + "@example.com", Role: role})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	return token
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/pkg/ctxutil"
 	"github.com/thanhnguyen/product-api/pkg/logger"
 )
 
@@ -17,31 +19,75 @@ type JWTAuthMiddleware struct {
 	secretKey     []byte
 	logger        *logger.Logger
 	tokenDuration time.Duration
+	cookie        TokenCookie
 }
 
+// TokenCookie configures the httpOnly cookie that carries the token for
+// browser clients. An empty Name disables it. A CSRF token is issued with it
+// in the CSRFCookieName cookie.
+type TokenCookie struct {
+	Name     string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// refreshWindowFraction is the final fraction of a token's lifetime during
+// which it can no longer be refreshed
+const refreshWindowFraction = 0.1
+
 // JWTClaims represents the claims in a JWT
 type JWTClaims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// MustChangePassword restricts the token to changing the password
+	MustChangePassword bool `json:"must_change_password,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// NewJWTAuthMiddleware creates a new JWTAuthMiddleware
-func NewJWTAuthMiddleware(secretKey string, logger *logger.Logger, tokenDuration time.Duration) *JWTAuthMiddleware {
+// NewJWTAuthMiddleware creates a new JWTAuthMiddleware. Tokens are read from
+// the Authorization header and, when it is missing, from the token cookie.
+func NewJWTAuthMiddleware(secretKey string, logger *logger.Logger, tokenDuration time.Duration, cookie TokenCookie) *JWTAuthMiddleware {
 	return &JWTAuthMiddleware{
 		secretKey:     []byte(secretKey),
 		logger:        logger,
 		tokenDuration: tokenDuration,
+		cookie:        cookie,
+	}
+}
+
+// TokenDuration returns how long issued tokens remain valid
+func (m *JWTAuthMiddleware) TokenDuration() time.Duration {
+	return m.tokenDuration
+}
+
+// SetTokenCookie stores the token in the token cookie, if it is enabled
+func (m *JWTAuthMiddleware) SetTokenCookie(c *gin.Context, token string) {
+	if m.cookie.Name == "" {
+		return
+	}
+	c.SetSameSite(m.cookie.SameSite)
+	c.SetCookie(m.cookie.Name, token, int(m.tokenDuration.Seconds()), "/", "", m.cookie.Secure, true)
+	m.setCSRFCookie(c)
+}
+
+// ClearTokenCookie removes the token cookie, if it is enabled
+func (m *JWTAuthMiddleware) ClearTokenCookie(c *gin.Context) {
+	if m.cookie.Name == "" {
+		return
 	}
+	c.SetSameSite(m.cookie.SameSite)
+	c.SetCookie(m.cookie.Name, "", -1, "/", "", m.cookie.Secure, true)
+	c.SetCookie(CSRFCookieName, "", -1, "/", "", m.cookie.Secure, false)
 }
 
 // GenerateToken creates a new JWT token for a user
 func (m *JWTAuthMiddleware) GenerateToken(user *entity.User) (string, error) {
 	claims := JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:             user.ID,
+		Email:              user.Email,
+		Role:               user.Role,
+		MustChangePassword: user.MustChangePassword,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -52,11 +98,19 @@ func (m *JWTAuthMiddleware) GenerateToken(user *entity.User) (string, error) {
 	return token.SignedString(m.secretKey)
 }
 
-// Authenticate validates the JWT token and sets the user in the context
+// Authenticate validates the JWT token and sets the user in the context. The
+// Authorization header takes precedence over the token cookie.
 func (m *JWTAuthMiddleware) Authenticate() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
+			if m.cookie.Name != "" {
+				if token, err := c.Cookie(m.cookie.Name); err == nil && token != "" {
+					c.Set(cookieAuthKey, true)
+					m.authenticateToken(c, token)
+					return
+				}
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
 			c.Abort()
 			return
@@ -70,32 +124,87 @@ func (m *JWTAuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			// Validate the signing algorithm
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		m.authenticateToken(c, parts[1])
+	}
+}
+
+// AuthenticateWebSocket validates the JWT of a WebSocket upgrade request and
+// sets the user in the context. Browsers can't set headers on WebSocket
+// requests, so the token is read from the Sec-WebSocket-Protocol header as
+// "bearer, {token}" or from the token query parameter.
+func (m *JWTAuthMiddleware) AuthenticateWebSocket() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.Query("token")
+		for _, protocol := range websocketSubprotocols(c.Request) {
+			if protocol != WebSocketTokenSubprotocol {
+				tokenString = protocol
 			}
-			return m.secretKey, nil
-		})
+		}
 
-		if err != nil {
-			m.logger.WithError(err).Error("Failed to parse JWT token")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		if tokenString == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is required"})
 			c.Abort()
 			return
 		}
 
-		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-			c.Set("user_id", claims.UserID)
-			c.Set("email", claims.Email)
-			c.Set("role", claims.Role)
-			c.Next()
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		m.authenticateToken(c, tokenString)
+	}
+}
+
+// WebSocketTokenSubprotocol is the subprotocol a WebSocket client lists before
+// its token in the Sec-WebSocket-Protocol header
+const WebSocketTokenSubprotocol = "bearer"
+
+// websocketSubprotocols returns the subprotocols requested by a WebSocket client
+func websocketSubprotocols(r *http.Request) []string {
+	var protocols []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+	return protocols
+}
+
+// authenticateToken validates a JWT and sets the user in the context, aborting
+// with 401 if the token is invalid
+func (m *JWTAuthMiddleware) authenticateToken(c *gin.Context, tokenString string) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		// Validate the signing algorithm
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return m.secretKey, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has expired"})
 			c.Abort()
 			return
 		}
+		m.logger.WithError(err).Error("Failed to parse JWT token")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		c.Set("user_id", claims.UserID)
+		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("must_change_password", claims.MustChangePassword)
+		c.Set("claims", claims)
+
+		// Propagate the user ID so ownership-scoped repositories can read it
+		c.Request = c.Request.WithContext(ctxutil.WithUserID(c.Request.Context(), claims.UserID))
+		c.Next()
+	} else {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		c.Abort()
+		return
 	}
 }
 
@@ -122,6 +231,19 @@ func (m *JWTAuthMiddleware) AuthorizeRole(roles ...string) gin.HandlerFunc {
 	}
 }
 
+// RequirePasswordChanged rejects requests from users who must change their
+// password before they can use the API
+func (m *JWTAuthMiddleware) RequirePasswordChanged() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("must_change_password") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Password change required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // RefreshToken refreshes an existing valid token
 func (m *JWTAuthMiddleware) RefreshToken(c *gin.Context) {
 	// Get the user information from the context (set by Authenticate middleware)
@@ -134,11 +256,27 @@ func (m *JWTAuthMiddleware) RefreshToken(c *gin.Context) {
 	email, _ := c.Get("email")
 	role, _ := c.Get("role")
 
+	// Refuse tokens in the final part of their lifetime so clients refresh early
+	// and a token about to expire can't be chained indefinitely
+	if value, ok := c.Get("claims"); ok {
+		claims := value.(*JWTClaims)
+		if claims.IssuedAt != nil && claims.ExpiresAt != nil {
+			lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time)
+			remaining := time.Until(claims.ExpiresAt.Time)
+			if remaining < time.Duration(float64(lifetime)*refreshWindowFraction) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is too close to expiry to refresh, please log in again"})
+				return
+			}
+		}
+	}
+
 	// Create a user entity from the context data
 	user := &entity.User{
 		ID:    userID.(uint),
 		Email: email.(string),
 		Role:  role.(string),
+		// A refreshed token keeps any pending password change
+		MustChangePassword: c.GetBool("must_change_password"),
 	}
 
 	// Generate a new token
@@ -148,6 +286,7 @@ func (m *JWTAuthMiddleware) RefreshToken(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
 		return
 	}
+	m.SetTokenCookie(c, token)
 
 	c.JSON(http.StatusOK, gin.H{
 		"token":   token,
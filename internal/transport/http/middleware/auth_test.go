@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// newTestAuth returns an auth middleware issuing hour-long tokens
+func newTestAuth() *JWTAuthMiddleware {
+	return NewJWTAuthMiddleware(testSecret, logger.NewLogger("error", "text", "stdout"), time.Hour, TokenCookie{})
+}
+
+// signToken signs a user token valid from issuedAt until expiresAt
+func signToken(t *testing.T, issuedAt, expiresAt time.Time) string {
+	t.Helper()
+	claims := JWTClaims{
+		UserID: 7,
+		Email:  "user@example.com",
+		Role:   "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("failed to sign the token: %v", err)
+	}
+	return token
+}
+
+func TestRefreshToken(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		token     string
+		want      int
+		wantError string
+	}{
+		{name: "fresh token", token: signToken(t, now.Add(-10*time.Minute), now.Add(50*time.Minute)), want: http.StatusOK},
+		{name: "token in its final tenth", token: signToken(t, now.Add(-57*time.Minute), now.Add(3*time.Minute)), want: http.StatusUnauthorized, wantError: "Token is too close to expiry to refresh, please log in again"},
+		{name: "expired token", token: signToken(t, now.Add(-2*time.Hour), now.Add(-time.Hour)), want: http.StatusUnauthorized, wantError: "Token has expired"},
+		{name: "missing token", want: http.StatusUnauthorized, wantError: "Authorization header is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := newTestAuth()
+			router := gin.New()
+			router.POST("/auth/refresh", auth.Authenticate(), auth.RefreshToken)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			var body struct {
+				Token string `json:"token"`
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode the response: %v", err)
+			}
+			if body.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", body.Error, tt.wantError)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			// The new token is valid for the full duration again
+			claims := &JWTClaims{}
+			if _, err := jwt.ParseWithClaims(body.Token, claims, func(*jwt.Token) (interface{}, error) {
+				return []byte(testSecret), nil
+			}); err != nil {
+				t.Fatalf("refreshed token is invalid: %v", err)
+			}
+			if claims.UserID != 7 || claims.Role != "user" {
+				t.Errorf("refreshed claims = %+v, want the same user", claims)
+			}
+			if remaining := time.Until(claims.ExpiresAt.Time); remaining < 59*time.Minute {
+				t.Errorf("refreshed token expires in %v, want about an hour", remaining)
+			}
+		})
+	}
+}
+
+func TestRequirePasswordChanged(t *testing.T) {
+	auth := newTestAuth()
+	router := gin.New()
+	router.GET("/products", auth.Authenticate(), auth.RequirePasswordChanged(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name               string
+		mustChangePassword bool
+		want               int
+	}{
+		{name: "password changed", want: http.StatusOK},
+		{name: "password change pending", mustChangePassword: true, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.GenerateToken(&entity.User{ID: 1, Email: "admin@example.com", Role: "admin", MustChangePassword: tt.mustChangePassword})
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/products", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_TokenCookie(t *testing.T) {
+	now := time.Now()
+	valid := signToken(t, now, now.Add(time.Hour))
+
+	tests := []struct {
+		name       string
+		cookieName string
+		header     string
+		cookie     string
+		want       int
+		wantCookie bool
+	}{
+		{name: "cookie", cookieName: "access_token", cookie: valid, want: http.StatusOK, wantCookie: true},
+		{name: "invalid cookie", cookieName: "access_token", cookie: "garbage", want: http.StatusUnauthorized},
+		{name: "cookie disabled", cookie: valid, want: http.StatusUnauthorized},
+		{name: "header over an invalid cookie", cookieName: "access_token", header: valid, cookie: "garbage", want: http.StatusOK},
+		{name: "invalid header over a valid cookie", cookieName: "access_token", header: "garbage", cookie: valid, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := NewJWTAuthMiddleware(testSecret, logger.NewLogger("error", "text", "stdout"), time.Hour, TokenCookie{Name: tt.cookieName})
+			router := gin.New()
+			router.GET("/me", auth.Authenticate(), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint("user_id"), "cookie": c.GetBool(cookieAuthKey)})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.header)
+			}
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: tt.cookie})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			var body struct {
+				UserID uint `json:"user_id"`
+				Cookie bool `json:"cookie"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode the response: %v", err)
+			}
+			if body.UserID != 7 {
+				t.Errorf("user_id = %d, want 7", body.UserID)
+			}
+			// Only cookie-authenticated requests need the CSRF check
+			if body.Cookie != tt.wantCookie {
+				t.Errorf("cookie authenticated = %v, want %v", body.Cookie, tt.wantCookie)
+			}
+		})
+	}
+}
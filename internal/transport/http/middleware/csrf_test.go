@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+func TestVerifyCSRF(t *testing.T) {
+	auth := NewJWTAuthMiddleware(testSecret, logger.NewLogger("error", "text", "stdout"), time.Hour, TokenCookie{Name: "token"})
+	token, err := auth.GenerateToken(&entity.User{ID: 1, Email: "user@example.com", Role: "user"})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	router := gin.New()
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/wishlist", auth.Authenticate(), auth.VerifyCSRF(), handler)
+	router.POST("/wishlist", auth.Authenticate(), auth.VerifyCSRF(), handler)
+
+	tests := []struct {
+		name   string
+		method string
+		bearer bool
+		cookie bool
+		csrf   string
+		header string
+		want   int
+	}{
+		{name: "cookie with matching header", method: http.MethodPost, cookie: true, csrf: "abc", header: "abc", want: http.StatusOK},
+		{name: "cookie without header", method: http.MethodPost, cookie: true, csrf: "abc", want: http.StatusForbidden},
+		{name: "cookie with wrong header", method: http.MethodPost, cookie: true, csrf: "abc", header: "abd", want: http.StatusForbidden},
+		{name: "cookie without CSRF cookie", method: http.MethodPost, cookie: true, header: "abc", want: http.StatusForbidden},
+		{name: "safe method with cookie", method: http.MethodGet, cookie: true, want: http.StatusOK},
+		{name: "bearer token needs no CSRF token", method: http.MethodPost, bearer: true, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/wishlist", nil)
+			if tt.bearer {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			if tt.cookie {
+				req.AddCookie(&http.Cookie{Name: "token", Value: token})
+			}
+			if tt.csrf != "" {
+				req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: tt.csrf})
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetTokenCookie_IssuesCSRFCookie(t *testing.T) {
+	auth := NewJWTAuthMiddleware(testSecret, logger.NewLogger("error", "text", "stdout"), time.Hour, TokenCookie{Name: "token", Secure: true})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	auth.SetTokenCookie(c, "jwt")
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	if token := cookies["token"]; token == nil || !token.HttpOnly || !token.Secure {
+		t.Errorf("token cookie = %+v, want a secure httpOnly cookie", token)
+	}
+	csrf := cookies[CSRFCookieName]
+	if csrf == nil || csrf.Value == "" || csrf.HttpOnly {
+		t.Fatalf("CSRF cookie = %+v, want a token scripts can read", csrf)
+	}
+}
@@ -1,28 +1,67 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"runtime/debug"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thanhnguyen/product-api/pkg/logger"
 )
 
-// ErrorResponse represents a standardized error response
+// ErrorResponse represents a standardized error response. Error and Stack
+// are only populated in development.
 type ErrorResponse struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
+	Status    int      `json:"status"`
+	Message   string   `json:"message"`
+	Error     string   `json:"error,omitempty"`
+	Stack     []string `json:"stack,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
 }
 
 // ErrorHandler provides error handling middleware
 type ErrorHandler struct {
-	logger *logger.Logger
+	logger  *logger.Logger
+	verbose bool
 }
 
-// NewErrorHandler creates a new ErrorHandler
-func NewErrorHandler(logger *logger.Logger) *ErrorHandler {
+// NewErrorHandler creates a new ErrorHandler. Error details and stack traces
+// are included in responses only when environment is "development".
+func NewErrorHandler(logger *logger.Logger, environment string) *ErrorHandler {
 	return &ErrorHandler{
-		logger: logger,
+		logger:  logger,
+		verbose: environment == "development",
+	}
+}
+
+// Recover returns middleware that recovers from panics and responds with a
+// 500 error, including the stack trace in development
+func (h *ErrorHandler) Recover() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := debug.Stack()
+
+				h.logger.WithContext(c.Request.Context()).
+					WithField("path", c.Request.URL.Path).
+					WithField("method", c.Request.Method).
+					WithField("client_ip", c.ClientIP()).
+					WithField("panic", r).
+					WithField("stack", string(stack)).
+					Error("Recovered from panic")
+
+				response := h.newErrorResponse(c, http.StatusInternalServerError, "Internal server error", fmt.Errorf("panic: %v", r))
+				if h.verbose {
+					response.Stack = strings.Split(strings.TrimSpace(string(stack)), "\n")
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, response)
+			}
+		}()
+
+		c.Next()
 	}
 }
 
@@ -38,7 +77,8 @@ func (h *ErrorHandler) HandleErrors() gin.HandlerFunc {
 			err := c.Errors.Last().Err
 
 			// Log the error
-			h.logger.WithField("path", c.Request.URL.Path).
+			h.logger.WithContext(c.Request.Context()).
+				WithField("path", c.Request.URL.Path).
 				WithField("method", c.Request.Method).
 				WithField("client_ip", c.ClientIP()).
 				WithError(err).
@@ -52,12 +92,14 @@ func (h *ErrorHandler) HandleErrors() gin.HandlerFunc {
 			// Prepare error response
 			status := http.StatusInternalServerError
 			message := "Internal server error"
-			errorMsg := err.Error()
 
 			// Check if the error is already handled by other middleware
 			if c.Writer.Status() != http.StatusOK {
 				status = c.Writer.Status()
 			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				status = http.StatusGatewayTimeout
+			}
 
 			// Set appropriate message based on status code
 			switch status {
@@ -71,14 +113,12 @@ func (h *ErrorHandler) HandleErrors() gin.HandlerFunc {
 				message = "Access denied"
 			case http.StatusTooManyRequests:
 				message = "Rate limit exceeded"
+			case http.StatusGatewayTimeout:
+				message = "Request timed out"
 			}
 
 			// Respond with JSON
-			c.JSON(status, ErrorResponse{
-				Status:  status,
-				Message: message,
-				Error:   errorMsg,
-			})
+			c.JSON(status, h.newErrorResponse(c, status, message, err))
 		}
 	}
 }
@@ -86,31 +126,45 @@ func (h *ErrorHandler) HandleErrors() gin.HandlerFunc {
 // NotFoundHandler handles 404 errors
 func (h *ErrorHandler) NotFoundHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		h.logger.WithField("path", c.Request.URL.Path).
+		h.logger.WithContext(c.Request.Context()).
+			WithField("path", c.Request.URL.Path).
 			WithField("method", c.Request.Method).
 			WithField("client_ip", c.ClientIP()).
 			Warn("Resource not found")
 
-		c.JSON(http.StatusNotFound, ErrorResponse{
-			Status:  http.StatusNotFound,
-			Message: "Resource not found",
-			Error:   "The requested URL was not found on the server",
-		})
+		c.JSON(http.StatusNotFound, h.newErrorResponse(c, http.StatusNotFound, "Resource not found",
+			errors.New("the requested URL was not found on the server")))
 	}
 }
 
 // MethodNotAllowedHandler handles 405 errors
 func (h *ErrorHandler) MethodNotAllowedHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		h.logger.WithField("path", c.Request.URL.Path).
+		h.logger.WithContext(c.Request.Context()).
+			WithField("path", c.Request.URL.Path).
 			WithField("method", c.Request.Method).
 			WithField("client_ip", c.ClientIP()).
 			Warn("Method not allowed")
 
-		c.JSON(http.StatusMethodNotAllowed, ErrorResponse{
-			Status:  http.StatusMethodNotAllowed,
-			Message: "Method not allowed",
-			Error:   "The method is not allowed for the requested URL",
-		})
+		c.JSON(http.StatusMethodNotAllowed, h.newErrorResponse(c, http.StatusMethodNotAllowed, "Method not allowed",
+			errors.New("the method is not allowed for the requested URL")))
+	}
+}
+
+// newErrorResponse builds an error response, exposing the raw error only in development
+func (h *ErrorHandler) newErrorResponse(c *gin.Context, status int, message string, err error) ErrorResponse {
+	response := ErrorResponse{
+		Status:    status,
+		Message:   message,
+		RequestID: requestID(c),
 	}
+	if h.verbose && err != nil {
+		response.Error = err.Error()
+	}
+	return response
+}
+
+// requestID returns the correlation ID set by the RequestID middleware
+func requestID(c *gin.Context) string {
+	return c.GetString("request_id")
 }
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// newErrorRouter serves a route failing with an error and one panicking
+func newErrorRouter(environment string) *gin.Engine {
+	h := NewErrorHandler(logger.NewLogger("error", "text", "stdout"), environment)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("request_id", "req-1")
+	})
+	router.Use(h.Recover(), h.HandleErrors())
+	router.GET("/fail", func(c *gin.Context) {
+		c.Error(errors.New("pq: relation \"products\" does not exist"))
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("nil map")
+	})
+	return router
+}
+
+func serveError(t *testing.T, router http.Handler, path string) ErrorResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	var response ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+	return response
+}
+
+func TestErrorHandler_HidesDetailsOutsideDevelopment(t *testing.T) {
+	router := newErrorRouter("production")
+
+	for _, path := range []string{"/fail", "/panic"} {
+		response := serveError(t, router, path)
+		if response.Message != "Internal server error" {
+			t.Errorf("%s: message = %q, want %q", path, response.Message, "Internal server error")
+		}
+		if response.Error != "" || len(response.Stack) != 0 {
+			t.Errorf("%s: response leaks details: %+v", path, response)
+		}
+		if response.RequestID != "req-1" {
+			t.Errorf("%s: request ID = %q, want %q", path, response.RequestID, "req-1")
+		}
+	}
+}
+
+func TestErrorHandler_ShowsDetailsInDevelopment(t *testing.T) {
+	router := newErrorRouter("development")
+
+	response := serveError(t, router, "/fail")
+	if response.Error != `pq: relation "products" does not exist` {
+		t.Errorf("error = %q, want the raw error", response.Error)
+	}
+	if len(response.Stack) != 0 {
+		t.Errorf("an error without a panic has a stack: %v", response.Stack)
+	}
+
+	response = serveError(t, router, "/panic")
+	if response.Error != "panic: nil map" {
+		t.Errorf("error = %q, want %q", response.Error, "panic: nil map")
+	}
+	if len(response.Stack) == 0 {
+		t.Error("panic response has no stack trace")
+	}
+}
+
+func TestErrorHandler_NotFound(t *testing.T) {
+	for _, environment := range []string{"production", "development"} {
+		h := NewErrorHandler(logger.NewLogger("error", "text", "stdout"), environment)
+		router := gin.New()
+		router.NoRoute(h.NotFoundHandler())
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+		var response ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to decode the response: %v", err)
+		}
+		if w.Code != http.StatusNotFound || response.Message != "Resource not found" {
+			t.Errorf("%s: got %d %+v", environment, w.Code, response)
+		}
+		if hasDetail := response.Error != ""; hasDetail != (environment == "development") {
+			t.Errorf("%s: error = %q", environment, response.Error)
+		}
+	}
+}
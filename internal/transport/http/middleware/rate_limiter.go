@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"context"
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -10,51 +13,143 @@ import (
 	"golang.org/x/time/rate"
 )
 
-// IPRateLimiter implements rate limiting per IP address
+// DefaultBucket is the bucket used for requests no other bucket applies to
+const DefaultBucket = "default"
+
+// RateLimit is the sustained rate and burst of a rate limit bucket
+type RateLimit struct {
+	Rate  rate.Limit
+	Burst int
+}
+
+// visitor is the rate limiter of a single IP address and when it was last used
+type visitor struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// IPRateLimiter implements rate limiting per IP address. Requests are counted
+// in named buckets, each with its own limit and its own limiter per IP.
 type IPRateLimiter struct {
-	ips    map[string]*rate.Limiter
-	mu     *sync.RWMutex
-	rate   rate.Limit
-	burst  int
-	logger *logger.Logger
+	ips     map[string]*visitor
+	buckets map[string]RateLimit
+	mu      *sync.RWMutex
+	logger  *logger.Logger
+	now     func() time.Time
 }
 
-// NewIPRateLimiter creates a new instance of IPRateLimiter
+// NewIPRateLimiter creates a new instance of IPRateLimiter whose default
+// bucket allows r requests per second with bursts of b
 func NewIPRateLimiter(r rate.Limit, b int, logger *logger.Logger) *IPRateLimiter {
 	return &IPRateLimiter{
-		ips:    make(map[string]*rate.Limiter),
-		mu:     &sync.RWMutex{},
-		rate:   r,
-		burst:  b,
-		logger: logger,
+		ips:     make(map[string]*visitor),
+		buckets: map[string]RateLimit{DefaultBucket: {Rate: r, Burst: b}},
+		mu:      &sync.RWMutex{},
+		logger:  logger,
+		now:     time.Now,
 	}
 }
 
-// GetLimiter returns the rate limiter for a specific IP address
+// AddBucket adds a named bucket, replacing any bucket with the same name
+func (i *IPRateLimiter) AddBucket(name string, limit RateLimit) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.buckets[name] = limit
+}
+
+// GetLimiter returns the default bucket's rate limiter for a specific IP address
 func (i *IPRateLimiter) GetLimiter(ip string) *rate.Limiter {
-	i.mu.RLock()
-	limiter, exists := i.ips[ip]
-	i.mu.RUnlock()
+	return i.getLimiter(DefaultBucket, ip)
+}
+
+// getLimiter returns a bucket's rate limiter for a specific IP address
+func (i *IPRateLimiter) getLimiter(bucket, ip string) *rate.Limiter {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
+	key := bucket + "|" + ip
+	v, exists := i.ips[key]
 	if !exists {
-		i.mu.Lock()
-		limiter = rate.NewLimiter(i.rate, i.burst)
-		i.ips[ip] = limiter
-		i.mu.Unlock()
+		limit := i.buckets[bucket]
+		v = &visitor{limiter: rate.NewLimiter(limit.Rate, limit.Burst)}
+		i.ips[key] = v
 	}
+	v.lastSeen = i.now()
+
+	return v.limiter
+}
+
+// hasBucket reports whether a bucket exists
+func (i *IPRateLimiter) hasBucket(name string) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	_, exists := i.buckets[name]
+	return exists
+}
+
+// RateLimitOption configures which bucket RateLimitMiddleware counts a request in
+type RateLimitOption func(*rateLimitOptions)
 
-	return limiter
+// rateLimitOptions holds the bucket selection rules of a middleware
+type rateLimitOptions struct {
+	routeBuckets map[string]string
+	roleBuckets  bool
 }
 
-// RateLimitMiddleware returns a gin middleware that implements rate limiting
-func (i *IPRateLimiter) RateLimitMiddleware() gin.HandlerFunc {
+// WithRouteBucket counts requests to a route, given by method and full path
+// as registered, in the named bucket
+func WithRouteBucket(method, path, bucket string) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.routeBuckets[method+" "+path] = bucket
+	}
+}
+
+// WithRoleBuckets counts requests in the bucket named after the authenticated
+// user's role, if there is one. The middleware must run after authentication.
+func WithRoleBuckets() RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.roleBuckets = true
+	}
+}
+
+// bucketFor selects the bucket of a request: the route's bucket first, then
+// the role's, then the default one
+func (i *IPRateLimiter) bucketFor(c *gin.Context, opts *rateLimitOptions) string {
+	if bucket, ok := opts.routeBuckets[c.Request.Method+" "+c.FullPath()]; ok && i.hasBucket(bucket) {
+		return bucket
+	}
+	if opts.roleBuckets {
+		if role := c.GetString("role"); role != "" && i.hasBucket(role) {
+			return role
+		}
+	}
+	return DefaultBucket
+}
+
+// RateLimitMiddleware returns a gin middleware that implements rate limiting.
+// Without options every request is counted in the default bucket.
+func (i *IPRateLimiter) RateLimitMiddleware(options ...RateLimitOption) gin.HandlerFunc {
+	opts := &rateLimitOptions{routeBuckets: make(map[string]string)}
+	for _, option := range options {
+		option(opts)
+	}
+
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
-		limiter := i.GetLimiter(ip)
+		bucket := i.bucketFor(c, opts)
+		limiter := i.getLimiter(bucket, ip)
 		if !limiter.Allow() {
-			i.logger.WithField("ip", ip).Warn("Rate limit exceeded")
+			retryAfter := retryAfterSeconds(limiter)
+			i.logger.WithFields(logger.Fields{
+				"ip":     ip,
+				"bucket": bucket,
+			}).Warn("Rate limit exceeded")
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
 			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
+				"error":               "Rate limit exceeded",
+				"retry_after_seconds": retryAfter,
 			})
 			c.Abort()
 			return
@@ -63,12 +158,35 @@ func (i *IPRateLimiter) RateLimitMiddleware() gin.HandlerFunc {
 	}
 }
 
-// CleanupTask removes stale rate limiters to prevent memory leaks
-func (i *IPRateLimiter) CleanupTask(cleanupInterval time.Duration, expiryDuration time.Duration) {
+// retryAfterSeconds returns how many whole seconds until the limiter has a
+// token again, at least one. The reservation is cancelled so asking doesn't
+// consume the token.
+func retryAfterSeconds(limiter *rate.Limiter) int {
+	reservation := limiter.Reserve()
+	defer reservation.Cancel()
+
+	if !reservation.OK() {
+		return 1
+	}
+	seconds := int(math.Ceil(reservation.Delay().Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// CleanupTask removes stale rate limiters to prevent memory leaks until ctx is cancelled
+func (i *IPRateLimiter) CleanupTask(ctx context.Context, cleanupInterval time.Duration, expiryDuration time.Duration) {
 	ticker := time.NewTicker(cleanupInterval)
 	go func() {
-		for range ticker.C {
-			i.cleanup(expiryDuration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				i.cleanup(expiryDuration)
+			}
 		}
 	}()
 }
@@ -78,10 +196,17 @@ func (i *IPRateLimiter) cleanup(expiryDuration time.Duration) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	// This is a simplified version. A more sophisticated implementation
-	// would track the last access time for each limiter to determine
-	// whether it should be removed.
-	i.logger.Info("Cleaning up stale rate limiters")
-	// In a real implementation, we would check the last access time of each limiter
-	// and remove those that have been inactive for longer than expiryDuration.
+	cutoff := i.now().Add(-expiryDuration)
+	removed := 0
+	for ip, v := range i.ips {
+		if v.lastSeen.Before(cutoff) {
+			delete(i.ips, ip)
+			removed++
+		}
+	}
+
+	i.logger.WithFields(logger.Fields{
+		"removed":   removed,
+		"remaining": len(i.ips),
+	}).Info("Cleaned up stale rate limiters")
 }
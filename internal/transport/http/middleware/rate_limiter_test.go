@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// newTestRateLimiter returns a rate limiter whose clock is advanced by hand
+func newTestRateLimiter() (*IPRateLimiter, *time.Time) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewIPRateLimiter(1, 1, logger.NewLogger("error", "text", "stdout"))
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestCleanup_EvictsOnlyIdleLimiters(t *testing.T) {
+	limiter, now := newTestRateLimiter()
+
+	idle := limiter.GetLimiter("192.0.2.1")
+	limiter.GetLimiter("192.0.2.2")
+	*now = now.Add(2 * time.Minute)
+	// The second IP keeps using its limiter
+	active := limiter.GetLimiter("192.0.2.2")
+	*now = now.Add(2 * time.Minute)
+
+	limiter.cleanup(3 * time.Minute)
+
+	if len(limiter.ips) != 1 {
+		t.Fatalf("%d limiters remain, want 1", len(limiter.ips))
+	}
+	if limiter.GetLimiter("192.0.2.2") != active {
+		t.Error("an active limiter was evicted")
+	}
+	if limiter.GetLimiter("192.0.2.1") == idle {
+		t.Error("an idle limiter was kept")
+	}
+}
+
+func TestCleanup_EvictedLimiterStartsFull(t *testing.T) {
+	limiter, now := newTestRateLimiter()
+
+	if !limiter.GetLimiter("192.0.2.1").Allow() {
+		t.Fatal("first request was limited")
+	}
+	*now = now.Add(time.Hour)
+	limiter.cleanup(time.Minute)
+
+	if !limiter.GetLimiter("192.0.2.1").Allow() {
+		t.Error("a new limiter should allow a request after eviction")
+	}
+}
+
+func TestCleanupTask_StopsOnCancel(t *testing.T) {
+	limiter, now := newTestRateLimiter()
+	limiter.GetLimiter("192.0.2.1")
+	*now = now.Add(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	limiter.CleanupTask(ctx, time.Millisecond, time.Minute)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		limiter.mu.RLock()
+		remaining := len(limiter.ips)
+		limiter.mu.RUnlock()
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the idle limiter was not evicted within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	// Give the task time to stop, then check it no longer evicts
+	time.Sleep(10 * time.Millisecond)
+	limiter.GetLimiter("192.0.2.1")
+	*now = now.Add(time.Hour)
+	time.Sleep(10 * time.Millisecond)
+
+	limiter.mu.RLock()
+	defer limiter.mu.RUnlock()
+	if len(limiter.ips) != 1 {
+		t.Error("the cleanup task kept running after cancel")
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/thanhnguyen/product-api/pkg/ctxutil"
+)
+
+// RequestIDHeader is the header carrying a request's correlation ID
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID matches the client-supplied request IDs that are safe to log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID returns middleware that tags each request with a correlation ID.
+// The ID is taken from the X-Request-ID header or generated if it is missing
+// or malformed, echoed in the response header and stored in the request
+// context so every log entry of the request can include it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(ctxutil.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout returns middleware that cancels the request context after timeout,
+// so use cases and repositories stop working on requests the client will
+// never see answered. A request that runs out of time is answered with 504;
+// it must be registered after ErrorHandler.HandleErrors. A zero timeout
+// disables it.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.Status(http.StatusGatewayTimeout)
+			c.Error(ctx.Err())
+		}
+	}
+}
+
+// timeoutWriter turns the server error a handler responds with after the
+// request context timed out into 504, since the error is then almost always
+// the cancelled context
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+}
+
+// WriteHeader replaces 5xx statuses with 504 once the deadline has passed
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code >= http.StatusInternalServerError && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		code = http.StatusGatewayTimeout
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// newTimeoutRouter serves handler behind the error handler and a timeout
+func newTimeoutRouter(timeout time.Duration, handler gin.HandlerFunc) *gin.Engine {
+	errorHandler := NewErrorHandler(logger.NewLogger("error", "text", "stdout"), "production")
+	router := gin.New()
+	router.Use(errorHandler.HandleErrors(), Timeout(timeout))
+	router.GET("/", handler)
+	return router
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		handler gin.HandlerFunc
+		want    int
+	}{
+		{
+			name:    "fast handler",
+			timeout: time.Second,
+			handler: func(c *gin.Context) {
+				if _, ok := c.Request.Context().Deadline(); !ok {
+					t.Error("request context has no deadline")
+				}
+				c.Status(http.StatusOK)
+			},
+			want: http.StatusOK,
+		},
+		{
+			name:    "handler that gives up without responding",
+			timeout: 10 * time.Millisecond,
+			handler: func(c *gin.Context) {
+				<-c.Request.Context().Done()
+			},
+			want: http.StatusGatewayTimeout,
+		},
+		{
+			name:    "handler failing with the cancelled context",
+			timeout: 10 * time.Millisecond,
+			handler: func(c *gin.Context) {
+				<-c.Request.Context().Done()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list products"})
+			},
+			want: http.StatusGatewayTimeout,
+		},
+		{
+			name:    "disabled",
+			timeout: 0,
+			handler: func(c *gin.Context) {
+				if _, ok := c.Request.Context().Deadline(); ok {
+					t.Error("request context has a deadline with the timeout disabled")
+				}
+				c.Status(http.StatusOK)
+			},
+			want: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newTimeoutRouter(tt.timeout, tt.handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeout_ReportsTimedOut(t *testing.T) {
+	router := newTimeoutRouter(10*time.Millisecond, func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var response ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode the response %q: %v", w.Body.String(), err)
+	}
+	if response.Status != http.StatusGatewayTimeout || response.Message != "Request timed out" {
+		t.Errorf("response = %+v, want a request timeout", response)
+	}
+}
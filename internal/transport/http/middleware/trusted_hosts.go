@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrustedHosts returns middleware that rejects requests whose Host header
+// isn't one of allowedHosts with 400, so a forged Host can't leak into
+// generated URLs or caches. Hosts listed without a port match any port. An
+// empty list allows every host.
+func TrustedHosts(allowedHosts []string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(allowedHosts))
+	for _, host := range allowedHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			allowed[host] = true
+		}
+	}
+
+	return func(c *gin.Context) {
+		if len(allowed) == 0 {
+			c.Next()
+			return
+		}
+
+		host := strings.ToLower(c.Request.Host)
+		hostname := host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			hostname = h
+		}
+		if !allowed[host] && !allowed[hostname] {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid host header"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestTrustedHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		host    string
+		want    int
+	}{
+		{name: "empty list allows any host", host: "evil.example", want: http.StatusOK},
+		{name: "allowed host", allowed: []string{"api.example.com"}, host: "api.example.com", want: http.StatusOK},
+		{name: "allowed host on any port", allowed: []string{"api.example.com"}, host: "api.example.com:8080", want: http.StatusOK},
+		{name: "host matched ignoring case", allowed: []string{"API.example.com"}, host: "api.EXAMPLE.com", want: http.StatusOK},
+		{name: "host with port must match the port", allowed: []string{"api.example.com:443"}, host: "api.example.com:8080", want: http.StatusBadRequest},
+		{name: "disallowed host", allowed: []string{"api.example.com"}, host: "evil.example", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(TrustedHosts(tt.allowed))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
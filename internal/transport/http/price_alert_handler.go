@@ -0,0 +1,126 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// PriceAlertHandler handles HTTP requests for price-drop alerts
+type PriceAlertHandler struct {
+	priceAlertUseCase usecase.PriceAlertUseCase
+	logger            *logger.Logger
+}
+
+// NewPriceAlertHandler creates a new PriceAlertHandler
+func NewPriceAlertHandler(priceAlertUseCase usecase.PriceAlertUseCase, logger *logger.Logger) *PriceAlertHandler {
+	return &PriceAlertHandler{
+		priceAlertUseCase: priceAlertUseCase,
+		logger:            logger,
+	}
+}
+
+// Subscribe handles subscribing the current user to a product's price-drop alert
+func (h *PriceAlertHandler) Subscribe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req dto.PriceAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alert := &entity.PriceAlert{
+		UserID:    userID,
+		ProductID: uint(productID),
+		Threshold: req.Threshold,
+	}
+
+	// Call use case
+	if err := h.priceAlertUseCase.Subscribe(c.Request.Context(), alert); err != nil {
+		var validationErr *usecase.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+		case errors.Is(err, usecase.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		default:
+			h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to subscribe to price alert")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to subscribe to price alert"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromPriceAlertEntity(*alert))
+}
+
+// Unsubscribe handles removing the current user's price-drop alert for a product
+func (h *PriceAlertHandler) Unsubscribe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	// Call use case
+	if err := h.priceAlertUseCase.Unsubscribe(c.Request.Context(), userID, uint(productID)); err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to unsubscribe from price alert")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe from price alert"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price alert removed successfully"})
+}
+
+// ListAlerts handles listing the current user's price-drop alerts
+func (h *PriceAlertHandler) ListAlerts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	alerts, err := h.priceAlertUseCase.ListAlerts(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to list price alerts")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list price alerts"})
+		return
+	}
+
+	// Convert entities to response
+	items := make([]dto.PriceAlertResponse, 0, len(alerts))
+	for _, a := range alerts {
+		items = append(items, dto.FromPriceAlertEntity(a))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// RegisterRoutes registers the price alert routes
+func (h *PriceAlertHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/products/:id/price-alert", h.Subscribe)
+	router.DELETE("/products/:id/price-alert", h.Unsubscribe)
+	router.GET("/price-alerts", h.ListAlerts)
+}
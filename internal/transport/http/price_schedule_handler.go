@@ -0,0 +1,151 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// PriceScheduleHandler handles HTTP requests for scheduled price changes
+type PriceScheduleHandler struct {
+	priceScheduleUseCase usecase.PriceScheduleUseCase
+	logger               *logger.Logger
+}
+
+// NewPriceScheduleHandler creates a new PriceScheduleHandler
+func NewPriceScheduleHandler(priceScheduleUseCase usecase.PriceScheduleUseCase, logger *logger.Logger) *PriceScheduleHandler {
+	return &PriceScheduleHandler{
+		priceScheduleUseCase: priceScheduleUseCase,
+		logger:               logger,
+	}
+}
+
+// CreateSchedule handles scheduled price change creation
+func (h *PriceScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req dto.PriceScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Convert DTO to entity
+	schedule := req.ToEntity()
+
+	// Call use case
+	if err := h.priceScheduleUseCase.CreateSchedule(c.Request.Context(), schedule); err != nil {
+		h.handleError(c, err, "Failed to create price schedule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromScheduleEntity(*schedule))
+}
+
+// ListSchedules handles listing scheduled price changes
+func (h *PriceScheduleHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.priceScheduleUseCase.ListSchedules(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to list price schedules")
+		return
+	}
+
+	// Convert entities to response
+	items := make([]dto.PriceScheduleResponse, 0, len(schedules))
+	for _, s := range schedules {
+		items = append(items, dto.FromScheduleEntity(s))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// GetSchedule handles fetching a scheduled price change by ID
+func (h *PriceScheduleHandler) GetSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price schedule ID"})
+		return
+	}
+
+	schedule, err := h.priceScheduleUseCase.GetSchedule(c.Request.Context(), uint(id))
+	if err != nil {
+		h.handleError(c, err, "Failed to get price schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromScheduleEntity(*schedule))
+}
+
+// UpdateSchedule handles updating a pending scheduled price change
+func (h *PriceScheduleHandler) UpdateSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price schedule ID"})
+		return
+	}
+
+	var req dto.PriceScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Convert DTO to entity
+	schedule := req.ToEntity()
+	schedule.ID = uint(id)
+
+	// Call use case
+	if err := h.priceScheduleUseCase.UpdateSchedule(c.Request.Context(), schedule); err != nil {
+		h.handleError(c, err, "Failed to update price schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromScheduleEntity(*schedule))
+}
+
+// DeleteSchedule handles deleting a scheduled price change
+func (h *PriceScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price schedule ID"})
+		return
+	}
+
+	if err := h.priceScheduleUseCase.DeleteSchedule(c.Request.Context(), uint(id)); err != nil {
+		h.handleError(c, err, "Failed to delete price schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price schedule deleted successfully"})
+}
+
+// handleError maps use case errors to HTTP responses
+func (h *PriceScheduleHandler) handleError(c *gin.Context, err error, message string) {
+	var validationErr *usecase.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.Is(err, usecase.ErrScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Price schedule not found"})
+	case errors.Is(err, usecase.ErrScheduleNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// RegisterRoutes registers the price schedule routes
+func (h *PriceScheduleHandler) RegisterRoutes(router *gin.RouterGroup) {
+	schedules := router.Group("/price-schedules")
+	{
+		schedules.POST("", h.CreateSchedule)
+		schedules.GET("", h.ListSchedules)
+		schedules.GET("/:id", h.GetSchedule)
+		schedules.PUT("/:id", h.UpdateSchedule)
+		schedules.DELETE("/:id", h.DeleteSchedule)
+	}
+}
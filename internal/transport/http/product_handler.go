@@ -1,11 +1,15 @@
 package http
 
 import (
-	"math"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
 	"github.com/thanhnguyen/product-api/internal/business/usecase"
 	"github.com/thanhnguyen/product-api/internal/transport/dto"
 	"github.com/thanhnguyen/product-api/pkg/logger"
@@ -13,15 +17,37 @@ import (
 
 // ProductHandler handles HTTP requests for products
 type ProductHandler struct {
-	productUseCase usecase.ProductUseCase
-	logger         *logger.Logger
+	productUseCase  usecase.ProductUseCase
+	reviewUseCase   usecase.ReviewUseCase
+	logger          *logger.Logger
+	minQueryLength  int
+	cachePolicy     CachePolicy
+	maxBatchSize    int
+	newArrivalsDays int
 }
 
-// NewProductHandler creates a new ProductHandler
-func NewProductHandler(productUseCase usecase.ProductUseCase, logger *logger.Logger) *ProductHandler {
+// NewProductHandler creates a new ProductHandler. Search queries shorter than
+// minQueryLength characters are rejected so typeahead clients can't send a
+// search per keystroke. Product details are cached following cachePolicy. Bulk
+// requests may list at most maxBatchSize items. New arrivals are the products
+// created in the last newArrivalsDays days unless the request sets the window.
+func NewProductHandler(
+	productUseCase usecase.ProductUseCase,
+	reviewUseCase usecase.ReviewUseCase,
+	logger *logger.Logger,
+	minQueryLength int,
+	cachePolicy CachePolicy,
+	maxBatchSize int,
+	newArrivalsDays int,
+) *ProductHandler {
 	return &ProductHandler{
-		productUseCase: productUseCase,
-		logger:         logger,
+		productUseCase:  productUseCase,
+		reviewUseCase:   reviewUseCase,
+		logger:          logger,
+		minQueryLength:  minQueryLength,
+		cachePolicy:     cachePolicy,
+		maxBatchSize:    maxBatchSize,
+		newArrivalsDays: newArrivalsDays,
 	}
 }
 
@@ -38,7 +64,16 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
 	// Call use case
 	if err := h.productUseCase.CreateProduct(c.Request.Context(), product, req.CategoryIDs); err != nil {
-		h.logger.WithError(err).Error("Failed to create product")
+		if errors.Is(err, usecase.ErrSKUExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A product with this SKU already exists"})
+			return
+		}
+		var validationErr *usecase.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to create product")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product"})
 		return
 	}
@@ -48,6 +83,39 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+// ListNewArrivals handles listing recently added products, newest first
+func (h *ProductHandler) ListNewArrivals(c *gin.Context) {
+	var req dto.NewArrivalsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Days == 0 {
+		req.Days = h.newArrivalsDays
+	}
+
+	// Call use case
+	products, err := h.productUseCase.ListNewArrivals(c.Request.Context(), req.Days, req.Limit)
+	if err != nil {
+		var validationErr *usecase.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to list new arrivals")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list new arrivals"})
+		return
+	}
+
+	// Convert entities to response
+	items := make([]dto.ProductResponse, 0, len(products))
+	for _, p := range products {
+		items = append(items, dto.FromEntity(p))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
 // GetProduct handles fetching a product by ID
 func (h *ProductHandler) GetProduct(c *gin.Context) {
 	// Parse ID from URL
@@ -58,10 +126,16 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
+	var req dto.ProductDetailRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Call use case
 	product, err := h.productUseCase.GetProduct(c.Request.Context(), uint(id))
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get product")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get product")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get product"})
 		return
 	}
@@ -72,10 +146,53 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	}
 
 	// Convert entity to response
-	response := dto.FromEntity(*product)
+	response := dto.ProductDetailResponse{ProductResponse: dto.FromEntity(*product)}
+
+	// Embed a preview of the reviews if requested
+	if includes(req.Include, "reviews") {
+		reviews, err := h.reviewUseCase.PreviewReviews(c.Request.Context(), product.ID)
+		if err != nil {
+			h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get product reviews")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get product"})
+			return
+		}
+		response.Reviews = make([]dto.ReviewResponse, 0, len(reviews))
+		for _, r := range reviews {
+			response.Reviews = append(response.Reviews, dto.FromReviewEntity(r))
+		}
+	}
+
+	cacheFor(c, h.cachePolicy)
 	c.JSON(http.StatusOK, response)
 }
 
+// GetProductBySKU handles fetching a product by SKU
+func (h *ProductHandler) GetProductBySKU(c *gin.Context) {
+	product, err := h.productUseCase.GetProductBySKU(c.Request.Context(), c.Param("sku"))
+	if errors.Is(err, usecase.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get product by SKU")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get product"})
+		return
+	}
+
+	cacheFor(c, h.cachePolicy)
+	c.JSON(http.StatusOK, dto.FromEntity(*product))
+}
+
+// includes reports whether a comma-separated include parameter lists name
+func includes(include, name string) bool {
+	for _, part := range strings.Split(include, ",") {
+		if strings.TrimSpace(part) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // ListProducts handles product listing with filtering and pagination
 func (h *ProductHandler) ListProducts(c *gin.Context) {
 	var req dto.ProductListRequest
@@ -84,21 +201,35 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		return
 	}
 
-	// Set default values for pagination
-	if req.Page <= 0 {
-		req.Page = 1
-	}
-	if req.PageSize <= 0 || req.PageSize > 100 {
-		req.PageSize = 10
+	// Only admins may see archived products, so the response depends on the caller
+	varyByCaller(c)
+	if req.IncludeArchived && currentUserRole(c) != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can list archived products"})
+		return
 	}
 
+	// Set default values for pagination
+	req.Normalize()
+
 	// Convert DTO to filter
 	filter := req.ToProductFilter()
+	if req.Cursor != "" {
+		cursor, err := entity.DecodeProductCursor(req.Cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		filter.After = cursor
+	}
 
 	// Call use case
 	products, totalItems, err := h.productUseCase.ListProducts(c.Request.Context(), filter)
+	if errors.Is(err, entity.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor doesn't match the sort order"})
+		return
+	}
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to list products")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to list products")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list products"})
 		return
 	}
@@ -109,16 +240,11 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		items = append(items, dto.FromEntity(p))
 	}
 
-	// Calculate total pages
-	totalPages := int(math.Ceil(float64(totalItems) / float64(req.PageSize)))
-
 	// Build response
-	response := dto.ProductListResponse{
-		Items:      items,
-		TotalItems: totalItems,
-		TotalPages: totalPages,
-		Page:       req.Page,
-		PageSize:   req.PageSize,
+	response := dto.NewPage(items, totalItems, req.PageRequest)
+	if len(products) == req.PageSize {
+		last := products[len(products)-1]
+		response.NextCursor = entity.NewProductCursor(last, req.SortBy, req.SortOrder).Encode()
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -140,13 +266,44 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	var opts dto.ProductUpdateOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Only admins may change locked fields of a published product
+	if opts.OverrideLocked && currentUserRole(c) != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can override locked fields"})
+		return
+	}
+
 	// Convert DTO to entity
 	product := req.ToEntity()
 	product.ID = uint(id)
 
 	// Call use case
-	if err := h.productUseCase.UpdateProduct(c.Request.Context(), product, req.CategoryIDs); err != nil {
-		h.logger.WithError(err).Error("Failed to update product")
+	if err := h.productUseCase.UpdateProduct(c.Request.Context(), product, req.CategoryIDs, opts.OverrideLocked); err != nil {
+		var lockedErr *usecase.LockedFieldError
+		if errors.As(err, &lockedErr) {
+			c.JSON(http.StatusConflict, gin.H{"error": lockedErr.Error(), "field": lockedErr.Field})
+			return
+		}
+		if errors.Is(err, usecase.ErrSKUExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A product with this SKU already exists"})
+			return
+		}
+		var transitionErr *usecase.StatusTransitionError
+		if errors.As(err, &transitionErr) {
+			c.JSON(http.StatusConflict, gin.H{"error": transitionErr.Error()})
+			return
+		}
+		var validationErr *usecase.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to update product")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
 		return
 	}
@@ -154,7 +311,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	// Get updated product
 	updatedProduct, err := h.productUseCase.GetProduct(c.Request.Context(), uint(id))
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get updated product")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get updated product")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get updated product"})
 		return
 	}
@@ -176,7 +333,7 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 
 	// Call use case
 	if err := h.productUseCase.DeleteProduct(c.Request.Context(), uint(id)); err != nil {
-		h.logger.WithError(err).Error("Failed to delete product")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to delete product")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product"})
 		return
 	}
@@ -184,20 +341,282 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
 }
 
+// ChangeStatus handles moving a product to a new status
+func (h *ProductHandler) ChangeStatus(c *gin.Context) {
+	// Parse ID from URL
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req dto.ProductStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Call use case
+	product, err := h.productUseCase.ChangeStatus(c.Request.Context(), uint(id), req.Status)
+	if err != nil {
+		var transitionErr *usecase.StatusTransitionError
+		var validationErr *usecase.ValidationError
+		switch {
+		case errors.Is(err, usecase.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		case errors.As(err, &transitionErr):
+			c.JSON(http.StatusConflict, gin.H{"error": transitionErr.Error()})
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		default:
+			h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to change product status")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change product status"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromEntity(*product))
+}
+
+// ReindexProduct re-indexes a single product into the search index and
+// returns the indexed document
+func (h *ProductHandler) ReindexProduct(c *gin.Context) {
+	// Parse ID from URL
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	// Call use case
+	doc, err := h.productUseCase.ReindexProduct(c.Request.Context(), uint(id))
+	if errors.Is(err, usecase.ErrSearchDisabled) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Product search is not enabled"})
+		return
+	}
+	if errors.Is(err, usecase.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to reindex product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reindex product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// CheckSearchConsistency compares the search index with the database and
+// reports the missing, stale and orphaned documents, repairing them on request
+func (h *ProductHandler) CheckSearchConsistency(c *gin.Context) {
+	var opts dto.ConsistencyCheckOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Call use case
+	report, err := h.productUseCase.CheckSearchConsistency(c.Request.Context(), opts.Repair)
+	if errors.Is(err, usecase.ErrSearchDisabled) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Product search is not enabled"})
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to check search index consistency")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check search index consistency"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ArchiveProduct handles hiding a product from the catalog
+func (h *ProductHandler) ArchiveProduct(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+// UnarchiveProduct handles returning an archived product to the catalog
+func (h *ProductHandler) UnarchiveProduct(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+// setArchived archives or unarchives the product in the URL and returns it
+func (h *ProductHandler) setArchived(c *gin.Context, archived bool) {
+	// Parse ID from URL
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	// Call use case
+	if archived {
+		err = h.productUseCase.ArchiveProduct(c.Request.Context(), uint(id))
+	} else {
+		err = h.productUseCase.UnarchiveProduct(c.Request.Context(), uint(id))
+	}
+	if errors.Is(err, usecase.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to update product archival")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product archival"})
+		return
+	}
+
+	// Get updated product
+	product, err := h.productUseCase.GetProduct(c.Request.Context(), uint(id))
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get updated product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get updated product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromEntity(*product))
+}
+
+// BulkUpdatePrices handles applying a price adjustment to many products at once
+func (h *ProductHandler) BulkUpdatePrices(c *gin.Context) {
+	var req dto.BulkPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var opts dto.BulkPriceOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkBatchSize(c, len(req.ProductIDs), h.maxBatchSize) {
+		return
+	}
+
+	// Call use case. Updates selecting products by filter are capped as well.
+	update := req.ToEntity(opts)
+	update.MaxProducts = h.maxBatchSize
+	changes, err := h.productUseCase.BulkUpdatePrices(c.Request.Context(), update)
+	if err != nil {
+		var validationErr *usecase.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to bulk update prices")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to bulk update prices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromPriceChanges(changes, opts.DryRun))
+}
+
+// AdjustStock handles adjusting the stock of many products at once, all or nothing
+func (h *ProductHandler) AdjustStock(c *gin.Context) {
+	var req dto.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkBatchSize(c, len(req.Items), h.maxBatchSize) {
+		return
+	}
+
+	// Call use case
+	changes, err := h.productUseCase.AdjustStockBatch(c.Request.Context(), req.ToDeltas())
+	if err != nil {
+		var validationErr *usecase.ValidationError
+		var stockErr *usecase.InsufficientStockError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+		case errors.As(err, &stockErr):
+			c.JSON(http.StatusConflict, gin.H{"error": stockErr.Error(), "product_id": stockErr.ProductID})
+		case errors.Is(err, usecase.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to adjust stock")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust stock"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromStockChanges(changes))
+}
+
+// SearchProducts handles searching products by name and description
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	var req dto.ProductSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Reject queries too short to be meaningful
+	req.Query = strings.TrimSpace(req.Query)
+	if utf8.RuneCountInString(req.Query) < h.minQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Search query must be at least %d characters", h.minQueryLength),
+		})
+		return
+	}
+
+	// Set default values for pagination
+	req.Normalize()
+
+	// Call use case
+	products, totalItems, err := h.productUseCase.SearchProducts(c.Request.Context(), req.Query, req.Page, req.PageSize)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to search products")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search products"})
+		return
+	}
+
+	// Convert entities to response
+	items := make([]dto.ProductResponse, 0, len(products))
+	for _, p := range products {
+		items = append(items, dto.FromEntity(p))
+	}
+
+	c.JSON(http.StatusOK, dto.NewPage(items, totalItems, req.PageRequest))
+}
+
+// SearchProductsByDescription handles searching products by description
 func (h *ProductHandler) SearchProductsByDescription(c *gin.Context) {
-	desc := c.Query("query")
-	if desc == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing query parameter"})
+	var req dto.ProductDescriptionSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values for pagination
+	req.Normalize()
+
+	// Call use case
+	results, totalItems, err := h.productUseCase.SearchProductsByDescription(c.Request.Context(), req.Query, req.Page, req.PageSize)
+	if errors.Is(err, usecase.ErrSearchDisabled) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Product search is not enabled"})
 		return
 	}
-	products, err := h.productUseCase.SearchProductsByDescription(c.Request.Context(), desc)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to search products")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to search products by description")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search products"})
 		return
 	}
-	// TODO Convert to response DTO if needed
-	c.JSON(http.StatusOK, products)
+
+	// Convert entities to response
+	items := make([]dto.ProductSearchResultResponse, 0, len(results))
+	for _, r := range results {
+		items = append(items, dto.ProductSearchResultResponse{
+			ProductResponse: dto.FromEntity(r.Product),
+			Highlights:      r.Highlights,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.NewPage(items, totalItems, req.PageRequest))
 }
 
 // RegisterRoutes registers the product routes
@@ -206,9 +625,27 @@ func (h *ProductHandler) RegisterRoutes(router *gin.RouterGroup) {
 	{
 		products.POST("", h.CreateProduct)
 		products.GET("", h.ListProducts)
+		products.GET("/new-arrivals", h.ListNewArrivals)
 		products.GET("/:id", h.GetProduct)
+		products.GET("/by-sku/:sku", h.GetProductBySKU)
 		products.PUT("/:id", h.UpdateProduct)
+		products.PATCH("/:id/status", h.ChangeStatus)
 		products.DELETE("/:id", h.DeleteProduct)
-		products.GET("/search", h.SearchProductsByDescription)
+		products.GET("/search", h.SearchProducts)
+		products.GET("/search/description", h.SearchProductsByDescription)
 	}
 }
+
+// RegisterAdminRoutes registers the product routes that require the admin role
+func (h *ProductHandler) RegisterAdminRoutes(router *gin.RouterGroup) {
+	products := router.Group("/products")
+	{
+		products.POST("/:id/archive", h.ArchiveProduct)
+		products.POST("/:id/unarchive", h.UnarchiveProduct)
+		products.POST("/stock-adjustments", h.AdjustStock)
+		products.POST("/bulk-price", h.BulkUpdatePrices)
+	}
+
+	router.POST("/admin/products/:id/reindex", h.ReindexProduct)
+	router.POST("/admin/search/consistency-check", h.CheckSearchConsistency)
+}
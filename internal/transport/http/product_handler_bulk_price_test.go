@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
+)
+
+// bulkPriceRepo is a product repository holding product prices in memory
+type bulkPriceRepo struct {
+	storage.ProductRepository
+	prices map[uint]float64
+}
+
+func (r *bulkPriceRepo) BulkUpdatePrice(ctx context.Context, update entity.BulkPriceUpdate) ([]entity.PriceChange, error) {
+	ids := update.ProductIDs
+	if len(ids) == 0 {
+		for id := uint(1); id <= uint(len(r.prices)); id++ {
+			ids = append(ids, id)
+		}
+	}
+	if update.MaxProducts > 0 && len(ids) > update.MaxProducts {
+		return nil, storage.ErrTooManyRows
+	}
+
+	changes := make([]entity.PriceChange, 0, len(ids))
+	for _, id := range ids {
+		newPrice := update.Adjustment.Apply(r.prices[id])
+		changes = append(changes, entity.PriceChange{ProductID: id, OldPrice: r.prices[id], NewPrice: newPrice})
+		if !update.DryRun {
+			r.prices[id] = newPrice
+		}
+	}
+	return changes, nil
+}
+
+// noopPriceAlerts ignores price drops
+type noopPriceAlerts struct {
+	usecase.PriceAlertUseCase
+}
+
+func (noopPriceAlerts) NotifyPriceDrop(ctx context.Context, product *entity.Product, oldPrice float64) {
+}
+
+// newBulkPriceRouter returns a router serving the admin product routes of a
+// catalog of a $100 and a $50 product, limited to maxBatchSize products per request
+func newBulkPriceRouter(maxBatchSize int) (*gin.Engine, *bulkPriceRepo) {
+	repo := &bulkPriceRepo{prices: map[uint]float64{1: 100, 2: 50}}
+	productUseCase := usecase.NewProductUseCase(repo, nil, noopPriceAlerts{}, nil, testLogger(), time.Minute, nil, nil, nil)
+	handler := NewProductHandler(productUseCase, nil, testLogger(), 2, CachePolicy{}, maxBatchSize, 7)
+
+	router := gin.New()
+	handler.RegisterAdminRoutes(router.Group("/api/v1"))
+	return router, repo
+}
+
+func TestBulkUpdatePricesHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want map[uint]float64
+	}{
+		{
+			name: "percentage",
+			body: `{"product_ids":[1,2],"type":"percentage","value":-10}`,
+			want: map[uint]float64{1: 90, 2: 45},
+		},
+		{
+			name: "absolute",
+			body: `{"product_ids":[1,2],"type":"absolute","value":-20}`,
+			want: map[uint]float64{1: 80, 2: 30},
+		},
+		{
+			name: "clamped to the minimum price",
+			body: `{"product_ids":[1,2],"type":"absolute","value":-60,"min_price":45}`,
+			want: map[uint]float64{1: 45, 2: 45},
+		},
+		{
+			name: "by filter",
+			body: `{"filter":{"search":"lamp"},"type":"percentage","value":50}`,
+			want: map[uint]float64{1: 150, 2: 75},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, repo := newBulkPriceRouter(10)
+
+			w := performRequest(router, http.MethodPost, "/api/v1/products/bulk-price", tt.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+
+			var resp dto.BulkPriceResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			if resp.Updated != len(tt.want) {
+				t.Errorf("updated = %d, want %d", resp.Updated, len(tt.want))
+			}
+			for _, change := range resp.Changes {
+				if change.NewPrice != tt.want[change.ProductID] {
+					t.Errorf("product %d new price = %v, want %v", change.ProductID, change.NewPrice, tt.want[change.ProductID])
+				}
+			}
+			for id, price := range tt.want {
+				if repo.prices[id] != price {
+					t.Errorf("product %d stored price = %v, want %v", id, repo.prices[id], price)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkUpdatePricesHandlerRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "without a target", body: `{"type":"percentage","value":-90}`},
+		{name: "with an empty filter", body: `{"filter":{},"type":"percentage","value":-90}`},
+		{name: "with a percentage of -100", body: `{"product_ids":[1],"type":"percentage","value":-100}`},
+		{name: "matching more products than the batch size", body: `{"filter":{"search":"a"},"type":"percentage","value":-10}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, repo := newBulkPriceRouter(1)
+
+			w := performRequest(router, http.MethodPost, "/api/v1/products/bulk-price", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+			}
+			if repo.prices[1] != 100 || repo.prices[2] != 50 {
+				t.Errorf("prices changed to %v", repo.prices)
+			}
+		})
+	}
+}
+
+func TestBulkUpdatePricesIsAnAdminRoute(t *testing.T) {
+	repo := &bulkPriceRepo{prices: map[uint]float64{1: 100}}
+	productUseCase := usecase.NewProductUseCase(repo, nil, noopPriceAlerts{}, nil, testLogger(), time.Minute, nil, nil, nil)
+	handler := NewProductHandler(productUseCase, nil, testLogger(), 2, CachePolicy{}, 10, 7)
+
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	w := performRequest(router, http.MethodPost, "/api/v1/products/bulk-price", `{"product_ids":[1],"type":"percentage","value":-10}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 outside the admin routes", w.Code)
+	}
+}
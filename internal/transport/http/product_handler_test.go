@@ -0,0 +1,749 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/internal/storage/elasticsearch"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
+)
+
+// fakeProductUseCase serves a fixed catalog
+type fakeProductUseCase struct {
+	usecase.ProductUseCase
+	products []entity.Product
+}
+
+func (f *fakeProductUseCase) ListProducts(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, int64, error) {
+	return f.products, int64(len(f.products)), nil
+}
+
+func (f *fakeProductUseCase) GetProduct(ctx context.Context, id uint) (*entity.Product, error) {
+	for i := range f.products {
+		if f.products[i].ID == id {
+			return &f.products[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeProductUseCase) GetProductBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	for i := range f.products {
+		if f.products[i].SKU == sku {
+			return &f.products[i], nil
+		}
+	}
+	return nil, usecase.ErrProductNotFound
+}
+
+func (f *fakeProductUseCase) SearchProducts(ctx context.Context, query string, page, pageSize int) ([]entity.Product, int64, error) {
+	return f.products, int64(len(f.products)), nil
+}
+
+func (f *fakeProductUseCase) SearchProductsByDescription(ctx context.Context, desc string, page, pageSize int) ([]entity.ProductSearchResult, int64, error) {
+	results := make([]entity.ProductSearchResult, len(f.products))
+	for i, p := range f.products {
+		results[i] = entity.ProductSearchResult{Product: p, Highlights: []string{"<em>" + desc + "</em>"}}
+	}
+	return results, int64(len(results)), nil
+}
+
+// newProductRouter serves the product routes of productUseCase to a caller
+// with the given role
+func newProductRouter(productUseCase usecase.ProductUseCase, role string) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("role", role)
+	})
+	NewProductHandler(productUseCase, nil, testLogger(), 2, CachePolicy{}, 10, 7).RegisterRoutes(router.Group(""))
+	return router
+}
+
+func TestListProducts_VariesByCaller(t *testing.T) {
+	productUseCase := &fakeProductUseCase{products: []entity.Product{{ID: 1, Name: "Widget", Price: 10}}}
+
+	tests := []struct {
+		name string
+		role string
+		want int
+	}{
+		{name: "admin", role: "admin", want: http.StatusOK},
+		{name: "user", role: "user", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(newProductRouter(productUseCase, tt.role), http.MethodGet, "/products?include_archived=true", "")
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("Vary"); got != "Authorization, Cookie, Accept" {
+				t.Errorf("Vary = %q, want %q", got, "Authorization, Cookie, Accept")
+			}
+		})
+	}
+}
+
+// fakeProductRepo serves products by ID
+type fakeProductRepo struct {
+	storage.ProductRepository
+	products map[uint]*entity.Product
+}
+
+func (f *fakeProductRepo) FindByID(ctx context.Context, id uint) (*entity.Product, error) {
+	return f.products[id], nil
+}
+
+// recordingIndexer records the documents sent to the search index
+type recordingIndexer struct {
+	mu   sync.Mutex
+	docs []elasticsearch.Product
+}
+
+func (r *recordingIndexer) IndexProduct(ctx context.Context, p elasticsearch.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.docs = append(r.docs, p)
+	return nil
+}
+
+func (r *recordingIndexer) DeleteProduct(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (r *recordingIndexer) IndexedProducts(ctx context.Context) (map[uint]time.Time, error) {
+	return nil, nil
+}
+
+func TestReindexProduct_IndexesOnlyThatProduct(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeProductRepo{products: map[uint]*entity.Product{
+		1: {ID: 1, Name: "Widget", Description: "Small", UpdatedAt: updatedAt},
+		2: {ID: 2, Name: "Gadget", Description: "Large", UpdatedAt: updatedAt},
+	}}
+	indexer := &recordingIndexer{}
+	productUseCase := usecase.NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, indexer, nil)
+
+	router := gin.New()
+	NewProductHandler(productUseCase, nil, testLogger(), 2, CachePolicy{}, 10, 7).RegisterAdminRoutes(router.Group(""))
+
+	w := performRequest(router, http.MethodPost, "/admin/products/2/reindex", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	want := elasticsearch.Product{ID: 2, Name: "Gadget", Description: "Large", UpdatedAt: updatedAt}
+	if len(indexer.docs) != 1 || indexer.docs[0].ID != want.ID || indexer.docs[0].Name != want.Name ||
+		indexer.docs[0].Description != want.Description || !indexer.docs[0].UpdatedAt.Equal(want.UpdatedAt) {
+		t.Fatalf("indexed documents = %+v, want only %+v", indexer.docs, want)
+	}
+
+	var got elasticsearch.Product
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+	if got.ID != 2 || got.Name != "Gadget" {
+		t.Errorf("response = %+v, want the indexed document", got)
+	}
+}
+
+func TestReindexProduct_Errors(t *testing.T) {
+	repo := &fakeProductRepo{products: map[uint]*entity.Product{}}
+
+	tests := []struct {
+		name    string
+		indexer usecase.ProductIndexer
+		path    string
+		want    int
+	}{
+		{name: "invalid id", indexer: &recordingIndexer{}, path: "/admin/products/abc/reindex", want: http.StatusBadRequest},
+		{name: "unknown product", indexer: &recordingIndexer{}, path: "/admin/products/9/reindex", want: http.StatusNotFound},
+		{name: "search disabled", indexer: nil, path: "/admin/products/9/reindex", want: http.StatusNotImplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUseCase := usecase.NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, tt.indexer, nil)
+			router := gin.New()
+			NewProductHandler(productUseCase, nil, testLogger(), 2, CachePolicy{}, 10, 7).RegisterAdminRoutes(router.Group(""))
+
+			w := performRequest(router, http.MethodPost, tt.path, "")
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if indexer, ok := tt.indexer.(*recordingIndexer); ok && len(indexer.docs) != 0 {
+				t.Errorf("expected nothing indexed, got %+v", indexer.docs)
+			}
+		})
+	}
+}
+
+func (f *fakeProductRepo) SetArchived(ctx context.Context, id uint, archived bool) error {
+	f.products[id].Archived = archived
+	return nil
+}
+
+func TestArchiveProduct_ReturnsArchivedProduct(t *testing.T) {
+	repo := &fakeProductRepo{products: map[uint]*entity.Product{1: {ID: 1, Name: "Widget"}}}
+	productUseCase := usecase.NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, nil)
+	router := gin.New()
+	NewProductHandler(productUseCase, nil, testLogger(), 2, CachePolicy{}, 10, 7).RegisterAdminRoutes(router.Group(""))
+
+	for _, step := range []struct {
+		path string
+		want bool
+	}{
+		{path: "/products/1/archive", want: true},
+		{path: "/products/1/unarchive", want: false},
+	} {
+		w := performRequest(router, http.MethodPost, step.path, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s status = %d, want %d: %s", step.path, w.Code, http.StatusOK, w.Body.String())
+		}
+		var got struct {
+			ID       uint `json:"id"`
+			Archived bool `json:"archived"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to decode the response: %v", err)
+		}
+		if got.ID != 1 || got.Archived != step.want {
+			t.Errorf("%s response = %+v, want archived %v", step.path, got, step.want)
+		}
+	}
+
+	if w := performRequest(router, http.MethodPost, "/products/9/archive", ""); w.Code != http.StatusNotFound {
+		t.Errorf("status of a missing product = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestListProducts_RejectsUnknownSort(t *testing.T) {
+	productUseCase := &fakeProductUseCase{products: []entity.Product{{ID: 1, Name: "Widget", Price: 10}}}
+	router := newProductRouter(productUseCase, "user")
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "sort_by=price&sort_order=desc", want: http.StatusOK},
+		{query: "sort_by=password_hash", want: http.StatusBadRequest},
+		{query: "sort_by=name&sort_order=sideways", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := performRequest(router, http.MethodGet, "/products?"+tt.query, ""); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.query, w.Code, tt.want)
+		}
+	}
+}
+
+func TestListProducts_NextCursor(t *testing.T) {
+	productUseCase := &fakeProductUseCase{products: []entity.Product{{ID: 3, Name: "B", Price: 20}, {ID: 5, Name: "A", Price: 30}}}
+	router := newProductRouter(productUseCase, "user")
+
+	tests := []struct {
+		name     string
+		query    string
+		wantNext bool
+	}{
+		{name: "full page", query: "page_size=2&sort_by=price&sort_order=asc", wantNext: true},
+		{name: "last page", query: "page_size=3&sort_by=price&sort_order=asc", wantNext: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(router, http.MethodGet, "/products?"+tt.query, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+			var body struct {
+				NextCursor string `json:"next_cursor"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode the response: %v", err)
+			}
+			if !tt.wantNext {
+				if body.NextCursor != "" {
+					t.Errorf("next_cursor = %q, want none", body.NextCursor)
+				}
+				return
+			}
+
+			cursor, err := entity.DecodeProductCursor(body.NextCursor)
+			if err != nil {
+				t.Fatalf("next_cursor %q doesn't decode: %v", body.NextCursor, err)
+			}
+			if cursor.ID != 5 || cursor.SortBy != "price" || cursor.Value != "30" {
+				t.Errorf("next cursor = %+v, want after product 5 at price 30", cursor)
+			}
+		})
+	}
+}
+
+func TestListProducts_InvalidCursor(t *testing.T) {
+	router := newProductRouter(&fakeProductUseCase{}, "user")
+
+	if w := performRequest(router, http.MethodGet, "/products?cursor=not-a-cursor", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestListProducts_PageEnvelope(t *testing.T) {
+	productUseCase := &fakeProductUseCase{products: []entity.Product{{ID: 1, Name: "Widget", Price: 10}}}
+	router := newProductRouter(productUseCase, "user")
+
+	w := performRequest(router, http.MethodGet, "/products?page=0&page_size=1000", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var page dto.Page[dto.ProductResponse]
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+	if page.Page != 1 || page.PageSize != dto.DefaultPageSize {
+		t.Errorf("page %d of size %d, want out of range values normalized", page.Page, page.PageSize)
+	}
+	if len(page.Items) != 1 || page.TotalItems != 1 || page.TotalPages != 1 {
+		t.Errorf("page = %+v, want the one product", page)
+	}
+}
+
+func TestListProducts_EmptyPage(t *testing.T) {
+	router := newProductRouter(&fakeProductUseCase{}, "user")
+
+	w := performRequest(router, http.MethodGet, "/products", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), `"items":[]`) {
+		t.Errorf("response = %s, want an empty items array", w.Body.String())
+	}
+}
+
+// updatingProductRepo stores the products it is asked to update
+type updatingProductRepo struct {
+	fakeProductRepo
+}
+
+func (f *updatingProductRepo) Update(ctx context.Context, product *entity.Product) error {
+	updated := *product
+	f.products[product.ID] = &updated
+	return nil
+}
+
+func TestUpdateProduct_LockedSKU(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		query   string
+		want    int
+		wantSKU string
+	}{
+		{name: "blocked", role: "admin", want: http.StatusConflict, wantSKU: "SKU-1"},
+		{name: "admin override", role: "admin", query: "?override_locked=true", want: http.StatusOK, wantSKU: "SKU-2"},
+		{name: "override by a user", role: "user", query: "?override_locked=true", want: http.StatusForbidden, wantSKU: "SKU-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &updatingProductRepo{fakeProductRepo{products: map[uint]*entity.Product{
+				1: {ID: 1, Name: "Lamp", SKU: "SKU-1", Description: "Desk lamp", Price: 50, StockQuantity: 20, Status: entity.ProductStatusActive},
+			}}}
+			productUseCase := usecase.NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, []string{entity.ProductFieldSKU})
+
+			body := `{"name":"Lamp","sku":"SKU-2","description":"Desk lamp","price":50,"stock_quantity":20,"category_ids":[]}`
+			w := performRequest(newProductRouter(productUseCase, tt.role), http.MethodPut, "/products/1"+tt.query, body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusConflict && !strings.Contains(w.Body.String(), `"field":"sku"`) {
+				t.Errorf("body = %s, want the locked field", w.Body)
+			}
+			if got := repo.products[1].SKU; got != tt.wantSKU {
+				t.Errorf("SKU = %s, want %s", got, tt.wantSKU)
+			}
+		})
+	}
+}
+
+// enabledCategoryRepo finds only the enabled categories, like the database does
+type enabledCategoryRepo struct {
+	storage.CategoryRepository
+	categories []entity.Category
+}
+
+func (f *enabledCategoryRepo) FindByIDs(ctx context.Context, ids []uint) ([]entity.Category, error) {
+	var found []entity.Category
+	for _, category := range f.categories {
+		for _, id := range ids {
+			if category.ID == id && !category.Disabled {
+				found = append(found, category)
+			}
+		}
+	}
+	return found, nil
+}
+
+func TestCreateAndUpdateProduct_DisabledCategory(t *testing.T) {
+	categories := &enabledCategoryRepo{categories: []entity.Category{
+		{ID: 1, Name: "Lighting"},
+		{ID: 2, Name: "Clearance", Disabled: true},
+	}}
+	repo := &updatingProductRepo{fakeProductRepo{products: map[uint]*entity.Product{
+		1: {ID: 1, Name: "Lamp", SKU: "SKU-1", Price: 50, StockQuantity: 20, Status: entity.ProductStatusActive},
+	}}}
+	router := newProductRouter(usecase.NewProductUseCase(repo, categories, nil, nil, testLogger(), time.Minute, nil, nil, nil), "admin")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "create", method: http.MethodPost, path: "/products",
+			body: `{"name":"Desk lamp","sku":"SKU-2","description":"Small lamp","price":30,"stock_quantity":5,"category_ids":[1,2]}`},
+		{name: "update", method: http.MethodPut, path: "/products/1",
+			body: `{"name":"Lamp","sku":"SKU-1","description":"Desk lamp","price":50,"stock_quantity":20,"category_ids":[2]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(router, tt.method, tt.path, tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), "disabled") {
+				t.Errorf("body = %s, want the category error", w.Body)
+			}
+		})
+	}
+}
+
+// fakeReviewUseCase previews fixed reviews
+type fakeReviewUseCase struct {
+	usecase.ReviewUseCase
+	reviews  []entity.Review
+	previews int
+}
+
+func (f *fakeReviewUseCase) PreviewReviews(ctx context.Context, productID uint) ([]entity.Review, error) {
+	f.previews++
+	return f.reviews, nil
+}
+
+func TestGetProduct_IncludeReviews(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantReviews int
+	}{
+		{name: "default", query: "", wantReviews: -1},
+		{name: "other include", query: "?include=categories", wantReviews: -1},
+		{name: "reviews", query: "?include=categories,reviews", wantReviews: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productUseCase := &fakeProductUseCase{products: []entity.Product{{ID: 1, Name: "Widget", Price: 10}}}
+			reviewUseCase := &fakeReviewUseCase{reviews: []entity.Review{
+				{ID: 1, ProductID: 1, UserID: 5, Rating: 5, Comment: "Great"},
+				{ID: 2, ProductID: 1, UserID: 6, Rating: 4, Comment: "Good"},
+			}}
+			router := gin.New()
+			NewProductHandler(productUseCase, reviewUseCase, testLogger(), 2, CachePolicy{}, 10, 7).RegisterRoutes(router.Group(""))
+
+			w := performRequest(router, http.MethodGet, "/products/1"+tt.query, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			raw, ok := body["reviews"]
+			if tt.wantReviews < 0 {
+				if ok || reviewUseCase.previews != 0 {
+					t.Errorf("reviews = %s after %d previews, want them left out", raw, reviewUseCase.previews)
+				}
+				return
+			}
+
+			var reviews []dto.ReviewResponse
+			if err := json.Unmarshal(raw, &reviews); err != nil {
+				t.Fatalf("decode reviews: %v", err)
+			}
+			if len(reviews) != tt.wantReviews || reviews[0].Rating != 5 || reviews[1].Comment != "Good" {
+				t.Errorf("reviews = %+v, want the %d previewed reviews", reviews, tt.wantReviews)
+			}
+		})
+	}
+}
+
+// searchingProductRepo searches its products by name in the database,
+// returning one page of the matches in ID order
+type searchingProductRepo struct {
+	fakeProductRepo
+	queries []string
+}
+
+func (f *searchingProductRepo) Search(ctx context.Context, query string, offset, limit int) ([]entity.Product, int64, error) {
+	f.queries = append(f.queries, query)
+	var found []entity.Product
+	for _, p := range f.products {
+		if strings.Contains(strings.ToLower(p.Name), strings.ToLower(query)) {
+			found = append(found, *p)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+
+	total := int64(len(found))
+	if offset > len(found) {
+		offset = len(found)
+	}
+	found = found[offset:]
+	if limit < len(found) {
+		found = found[:limit]
+	}
+	return found, total, nil
+}
+
+func TestSearchProducts_SearchBackendDisabled(t *testing.T) {
+	repo := &searchingProductRepo{fakeProductRepo: fakeProductRepo{products: map[uint]*entity.Product{
+		1: {ID: 1, Name: "Desk lamp", Price: 50},
+		2: {ID: 2, Name: "Chair", Price: 80},
+	}}}
+	// Without Elasticsearch the product search is nil
+	productUseCase := usecase.NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, nil)
+	router := newProductRouter(productUseCase, "user")
+
+	// Name search degrades to the database
+	w := performRequest(router, http.MethodGet, "/products/search?query=lamp", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("search status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var page dto.Page[dto.ProductResponse]
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != 1 || len(repo.queries) != 1 {
+		t.Errorf("items = %+v after database queries %v, want product 1 from the database", page.Items, repo.queries)
+	}
+
+	// Description search needs the backend
+	w = performRequest(router, http.MethodGet, "/products/search/description?query=lamp", "")
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("description search status = %d, want %d: %s", w.Code, http.StatusNotImplemented, w.Body)
+	}
+}
+
+func TestSearchProducts_PageEnvelope(t *testing.T) {
+	repo := &searchingProductRepo{fakeProductRepo: fakeProductRepo{products: map[uint]*entity.Product{}}}
+	for id := uint(1); id <= 5; id++ {
+		repo.products[id] = &entity.Product{ID: id, Name: fmt.Sprintf("Lamp %d", id), Price: 10}
+	}
+	productUseCase := usecase.NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, nil)
+
+	w := performRequest(newProductRouter(productUseCase, "user"), http.MethodGet, "/products/search?query=lamp&page=2&page_size=2", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	for key, want := range map[string]string{"total_items": "5", "total_pages": "3", "page": "2", "page_size": "2"} {
+		if got := string(body[key]); got != want {
+			t.Errorf("%s = %s, want %s", key, got, want)
+		}
+	}
+
+	var items []dto.ProductResponse
+	if err := json.Unmarshal(body["items"], &items); err != nil {
+		t.Fatalf("decode items: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != 3 || items[1].ID != 4 {
+		t.Errorf("items = %+v, want products 3 and 4", items)
+	}
+}
+
+func TestSearchProducts_MinimumQueryLength(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "one character", query: "l", want: http.StatusBadRequest},
+		{name: "padded", query: "%20l%20%20", want: http.StatusBadRequest},
+		{name: "one multi-byte character", query: "%C3%A9", want: http.StatusBadRequest},
+		{name: "two multi-byte characters", query: "%C3%A9%C3%A9", want: http.StatusOK},
+		{name: "long enough", query: "la", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &searchingProductRepo{fakeProductRepo: fakeProductRepo{products: map[uint]*entity.Product{}}}
+			productUseCase := usecase.NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, nil)
+
+			w := performRequest(newProductRouter(productUseCase, "user"), http.MethodGet, "/products/search?query="+tt.query, "")
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusBadRequest {
+				if !strings.Contains(w.Body.String(), "at least 2 characters") {
+					t.Errorf("body = %s, want the minimum length", w.Body)
+				}
+				if len(repo.queries) != 0 {
+					t.Errorf("searched for %v, want no search", repo.queries)
+				}
+			}
+		})
+	}
+}
+
+func TestProductResponses_UseDTOShape(t *testing.T) {
+	// The entity would serialize the categories as objects and the timestamps
+	// with fractional seconds
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 500000000, time.UTC)
+	productUseCase := &fakeProductUseCase{products: []entity.Product{{
+		ID: 1, Name: "Lamp", SKU: "SKU-1", Description: "Desk lamp", Price: 50, StockQuantity: 20,
+		Status:     entity.ProductStatusActive,
+		Categories: []entity.Category{{ID: 3, Name: "Lighting", Description: "Lamps and bulbs"}},
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}}}
+	router := newProductRouter(productUseCase, "admin")
+
+	wantKeys := []string{"archived", "categories", "created_at", "description", "id", "name", "price", "sku", "status", "stock_quantity", "updated_at"}
+	tests := []struct {
+		name  string
+		path  string
+		paged bool
+		extra []string
+	}{
+		{name: "detail", path: "/products/1"},
+		{name: "by SKU", path: "/products/by-sku/SKU-1"},
+		{name: "list", path: "/products", paged: true},
+		{name: "search", path: "/products/search?query=lamp", paged: true},
+		{name: "description search", path: "/products/search/description?query=lamp", paged: true, extra: []string{"highlights"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(router, http.MethodGet, tt.path, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			var product map[string]json.RawMessage
+			if tt.paged {
+				var page struct {
+					Items []map[string]json.RawMessage `json:"items"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || len(page.Items) != 1 {
+					t.Fatalf("decode response %s: %v", w.Body, err)
+				}
+				product = page.Items[0]
+			} else if err := json.Unmarshal(w.Body.Bytes(), &product); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+
+			keys := make([]string, 0, len(product))
+			for key := range product {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			want := append(append([]string(nil), wantKeys...), tt.extra...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(keys, want) {
+				t.Errorf("keys = %v, want %v", keys, want)
+			}
+			if got := string(product["categories"]); got != `["Lighting"]` {
+				t.Errorf("categories = %s, want the category names", got)
+			}
+			if got := string(product["created_at"]); got != `"2024-05-01T12:00:00Z"` {
+				t.Errorf("created_at = %s, want the response timestamp format", got)
+			}
+		})
+	}
+}
+
+// filteringProductRepo records the filter of the last product list
+type filteringProductRepo struct {
+	fakeProductRepo
+	filter *entity.ProductFilter
+}
+
+func (f *filteringProductRepo) List(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, int64, error) {
+	f.filter = &filter
+	var products []entity.Product
+	for _, p := range f.products {
+		products = append(products, *p)
+	}
+	return products, int64(len(products)), nil
+}
+
+func TestListNewArrivals(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		want      int
+		wantDays  int
+		wantLimit int
+	}{
+		{name: "defaults", want: http.StatusOK, wantDays: 7, wantLimit: 20},
+		{name: "custom window", query: "?days=30&limit=5", want: http.StatusOK, wantDays: 30, wantLimit: 5},
+		{name: "window too long", query: "?days=366", want: http.StatusBadRequest},
+		{name: "limit too high", query: "?limit=101", want: http.StatusBadRequest},
+		{name: "zero limit", query: "?limit=0", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &filteringProductRepo{fakeProductRepo: fakeProductRepo{products: map[uint]*entity.Product{
+				1: {ID: 1, Name: "Lamp", Price: 50},
+			}}}
+			productUseCase := usecase.NewProductUseCase(repo, nil, nil, nil, testLogger(), time.Minute, nil, nil, nil)
+
+			requested := time.Now()
+			w := performRequest(newProductRouter(productUseCase, "user"), http.MethodGet, "/products/new-arrivals"+tt.query, "")
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want != http.StatusOK {
+				if repo.filter != nil {
+					t.Errorf("listed products with %+v, want no query", *repo.filter)
+				}
+				return
+			}
+
+			var body struct {
+				Items []dto.ProductResponse `json:"items"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(body.Items) != 1 || body.Items[0].ID != 1 {
+				t.Errorf("items = %+v, want product 1", body.Items)
+			}
+
+			filter := repo.filter
+			if filter.PageSize != tt.wantLimit || filter.SortBy != "created_at" || filter.SortOrder != "desc" {
+				t.Errorf("filter = %+v, want the %d newest first", *filter, tt.wantLimit)
+			}
+			since := requested.AddDate(0, 0, -tt.wantDays)
+			if filter.CreatedAfter == nil || filter.CreatedAfter.Sub(since).Abs() > time.Minute {
+				t.Errorf("CreatedAfter = %v, want about %v", filter.CreatedAfter, since)
+			}
+		})
+	}
+}
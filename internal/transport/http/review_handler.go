@@ -0,0 +1,169 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// ReviewHandler handles HTTP requests for product reviews
+type ReviewHandler struct {
+	reviewUseCase usecase.ReviewUseCase
+	logger        *logger.Logger
+}
+
+// NewReviewHandler creates a new ReviewHandler
+func NewReviewHandler(reviewUseCase usecase.ReviewUseCase, logger *logger.Logger) *ReviewHandler {
+	return &ReviewHandler{
+		reviewUseCase: reviewUseCase,
+		logger:        logger,
+	}
+}
+
+// CreateReview handles reviewing a product as the current user
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req dto.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review := &entity.Review{
+		ProductID: uint(productID),
+		UserID:    userID,
+		Rating:    req.Rating,
+		Comment:   req.Comment,
+	}
+
+	// Call use case
+	if err := h.reviewUseCase.CreateReview(c.Request.Context(), review); err != nil {
+		h.handleError(c, err, "Failed to create review")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromReviewEntity(*review))
+}
+
+// ListReviews handles listing a product's reviews
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req dto.ReviewListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values for pagination
+	req.Normalize()
+
+	// Call use case
+	reviews, totalItems, err := h.reviewUseCase.ListReviews(c.Request.Context(), uint(productID), req.Page, req.PageSize)
+	if err != nil {
+		h.handleError(c, err, "Failed to list reviews")
+		return
+	}
+
+	// Convert entities to response
+	items := make([]dto.ReviewResponse, 0, len(reviews))
+	for _, r := range reviews {
+		items = append(items, dto.FromReviewEntity(r))
+	}
+
+	c.JSON(http.StatusOK, dto.NewPage(items, totalItems, req.PageRequest))
+}
+
+// GetReviewSummary handles getting a product's rating summary
+func (h *ReviewHandler) GetReviewSummary(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	// Call use case
+	summary, err := h.reviewUseCase.GetReviewSummary(c.Request.Context(), uint(productID))
+	if err != nil {
+		h.handleError(c, err, "Failed to get review summary")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromReviewSummaryEntity(*summary))
+}
+
+// ListReviewedNotWishlisted handles listing the products the current user
+// rated highly but hasn't added to their wishlist
+func (h *ReviewHandler) ListReviewedNotWishlisted(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req dto.ReviewedNotWishlistedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Call use case
+	products, err := h.reviewUseCase.ListReviewedNotWishlisted(c.Request.Context(), userID, req.MinRating)
+	if err != nil {
+		h.handleError(c, err, "Failed to list reviewed products")
+		return
+	}
+
+	// Convert entities to response
+	items := make([]dto.ProductResponse, 0, len(products))
+	for _, p := range products {
+		items = append(items, dto.FromEntity(p))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// handleError maps use case errors to HTTP responses
+func (h *ReviewHandler) handleError(c *gin.Context, err error, message string) {
+	var validationErr *usecase.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message})
+	case errors.Is(err, usecase.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	case errors.Is(err, usecase.ErrReviewExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already reviewed this product"})
+	default:
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// RegisterRoutes registers the review routes
+func (h *ReviewHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/products/:id/reviews", h.CreateReview)
+	router.GET("/products/:id/reviews", h.ListReviews)
+	router.GET("/products/:id/reviews/summary", h.GetReviewSummary)
+	router.GET("/reviews/not-wishlisted", h.ListReviewedNotWishlisted)
+}
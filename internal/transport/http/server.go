@@ -2,38 +2,64 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/thanhnguyen/product-api/internal/business/usecase"
 	"github.com/thanhnguyen/product-api/internal/config"
 	"github.com/thanhnguyen/product-api/internal/transport/http/middleware"
 	"github.com/thanhnguyen/product-api/pkg/logger"
 )
 
+// Rate limit buckets besides the default one. Role buckets are named after the role.
+const (
+	adminRateLimitBucket        = "admin"
+	statsRefreshRateLimitBucket = "stats_refresh"
+)
+
 // Server represents the HTTP server
 type Server struct {
-	router         *gin.Engine
-	httpServer     *http.Server
-	config         *config.Config
-	logger         *logger.Logger
-	authMiddleware *middleware.JWTAuthMiddleware
-	rateLimiter    *middleware.IPRateLimiter
-	errorHandler   *middleware.ErrorHandler
-	productHandler *ProductHandler
-	statsHandler   *StatsHandler
-	wsHub          *WebSocketHub
+	router          *gin.Engine
+	httpServer      *http.Server
+	config          *config.Config
+	logger          *logger.Logger
+	authMiddleware  *middleware.JWTAuthMiddleware
+	rateLimiter     *middleware.IPRateLimiter
+	errorHandler    *middleware.ErrorHandler
+	authHandler     *AuthHandler
+	productHandler  *ProductHandler
+	statsHandler    *StatsHandler
+	scheduleHandler *PriceScheduleHandler
+	alertHandler    *PriceAlertHandler
+	reviewHandler   *ReviewHandler
+	wishlistHandler *WishlistHandler
+	categoryHandler *CategoryHandler
+	wsHub           *WebSocketHub
+	healthChecks    []healthCheck
+	metrics         *prometheus.Registry
+	stopBackground  context.CancelFunc
 }
 
 // NewServer creates a new HTTP server
 func NewServer(
 	config *config.Config,
 	logger *logger.Logger,
+	authUseCase usecase.AuthUseCase,
 	productUseCase usecase.ProductUseCase,
 	statsUseCase usecase.StatsUseCase,
+	priceScheduleUseCase usecase.PriceScheduleUseCase,
+	priceAlertUseCase usecase.PriceAlertUseCase,
+	reviewUseCase usecase.ReviewUseCase,
+	wishlistUseCase usecase.WishlistUseCase,
+	categoryUseCase usecase.CategoryUseCase,
 	wsHub *WebSocketHub,
 ) *Server {
 	// Set Gin mode
@@ -42,7 +68,9 @@ func NewServer(
 	}
 
 	router := gin.New()
-	router.Use(gin.Recovery())
+
+	// Background tasks run until the server is shut down
+	background, stopBackground := context.WithCancel(context.Background())
 
 	// Create server
 	server := &Server{
@@ -54,17 +82,51 @@ func NewServer(
 			WriteTimeout: config.Server.WriteTimeout,
 			IdleTimeout:  config.Server.IdleTimeout,
 		},
-		config: config,
-		logger: logger,
-		wsHub:  wsHub,
+		config:         config,
+		logger:         logger,
+		wsHub:          wsHub,
+		metrics:        prometheus.NewRegistry(),
+		stopBackground: stopBackground,
 	}
 
+	// Tag every request with a correlation ID before anything logs it
+	router.Use(middleware.RequestID())
+
+	// Reject forged Host headers
+	router.Use(middleware.TrustedHosts(config.Server.AllowedHosts))
+
+	// Set the security headers on every response, errors included
+	router.Use(middleware.SecurityHeaders{
+		StrictTransportSecurity: config.Security.StrictTransportSecurity,
+		ContentTypeOptions:      config.Security.ContentTypeOptions,
+		FrameOptions:            config.Security.FrameOptions,
+		ContentSecurityPolicy:   config.Security.ContentSecurityPolicy,
+		ReferrerPolicy:          config.Security.ReferrerPolicy,
+	}.Middleware())
+
+	// Export runtime, request and WebSocket metrics
+	server.metrics.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "websocket_connections_active",
+			Help: "Number of connected WebSocket clients.",
+		}, func() float64 {
+			return float64(wsHub.ClientCount())
+		}),
+	)
+	router.Use(middleware.NewMetrics(server.metrics).Middleware())
+
 	// Initialize error handler
-	server.errorHandler = middleware.NewErrorHandler(logger)
+	server.errorHandler = middleware.NewErrorHandler(logger, config.Environment)
+	router.Use(server.errorHandler.Recover())
 	router.Use(server.errorHandler.HandleErrors())
 	router.NoRoute(server.errorHandler.NotFoundHandler())
 	router.NoMethod(server.errorHandler.MethodNotAllowedHandler())
 
+	// Bound how long a request may run; the deadline flows into the use cases
+	router.Use(middleware.Timeout(config.Server.RequestTimeout))
+
 	// CORS configuration
 	corsConfig := cors.Config{
 		AllowOrigins:     config.CORS.AllowOrigins,
@@ -76,11 +138,21 @@ func NewServer(
 	}
 	router.Use(cors.New(corsConfig))
 
+	// Turn requests away while the service is under maintenance
+	if config.Features.Maintenance {
+		router.Use(middleware.Maintenance("/health", "/ready", "/metrics"))
+	}
+
 	// Initialize middleware
 	server.authMiddleware = middleware.NewJWTAuthMiddleware(
 		config.JWT.Secret,
 		logger,
 		time.Duration(config.JWT.ExpiryMinutes)*time.Minute,
+		middleware.TokenCookie{
+			Name:     config.JWT.CookieName,
+			Secure:   config.JWT.CookieSecure,
+			SameSite: sameSiteMode(config.JWT.CookieSameSite),
+		},
 	)
 
 	// Initialize rate limiter
@@ -89,68 +161,165 @@ func NewServer(
 		config.RateLimit.Burst,
 		logger,
 	)
+	server.rateLimiter.AddBucket(adminRateLimitBucket, middleware.RateLimit{
+		Rate:  config.RateLimit.AdminRate,
+		Burst: config.RateLimit.AdminBurst,
+	})
+	server.rateLimiter.AddBucket(statsRefreshRateLimitBucket, middleware.RateLimit{
+		Rate:  config.RateLimit.StatsRefreshRate,
+		Burst: config.RateLimit.StatsRefreshBurst,
+	})
 	server.rateLimiter.CleanupTask(
+		background,
 		time.Duration(config.RateLimit.CleanupIntervalMinutes)*time.Minute,
 		time.Duration(config.RateLimit.ExpiryDurationMinutes)*time.Minute,
 	)
-	router.Use(server.rateLimiter.RateLimitMiddleware())
 
 	// Setup middleware
 	router.Use(gin.Logger())
 	router.Use(server.requestLogger())
 
 	// Setup handlers
-	server.productHandler = NewProductHandler(productUseCase, logger)
+	server.authHandler = NewAuthHandler(authUseCase, server.authMiddleware, logger)
+	cache := config.ActiveCache()
+	server.productHandler = NewProductHandler(productUseCase, reviewUseCase, logger, config.Search.MinQueryLength,
+		CachePolicy{MaxAge: cache.ProductMaxAge, Public: cache.ProductPublic},
+		config.Bulk.MaxBatchSize, config.Products.NewArrivalsDays)
 	server.statsHandler = NewStatsHandler(statsUseCase, logger)
+	server.scheduleHandler = NewPriceScheduleHandler(priceScheduleUseCase, logger)
+	server.alertHandler = NewPriceAlertHandler(priceAlertUseCase, logger)
+	server.reviewHandler = NewReviewHandler(reviewUseCase, logger)
+	server.wishlistHandler = NewWishlistHandler(wishlistUseCase, logger)
+	server.categoryHandler = NewCategoryHandler(categoryUseCase, logger, CachePolicy{MaxAge: cache.CategoryMaxAge, Public: cache.CategoryPublic})
 
 	// Register routes
 	server.registerRoutes()
 
-	// Đăng ký route WebSocket
-	server.router.GET("/ws/notifications", wsHub.HandleWS)
+	// Đăng ký route WebSocket. The stream carries the admin-only stats.
+	if config.Features.WebSocket {
+		server.router.GET("/ws/notifications",
+			server.rateLimiter.RateLimitMiddleware(),
+			server.authMiddleware.AuthenticateWebSocket(),
+			server.authMiddleware.AuthorizeRole("admin"),
+			wsHub.HandleWS,
+		)
+	}
 
 	return server
 }
 
+// sameSiteMode parses a cookie SameSite setting, defaulting to Lax
+func sameSiteMode(mode string) http.SameSite {
+	switch strings.ToLower(mode) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	s.logger.Infof("Starting HTTP server on port %d", s.config.Server.Port)
 	return s.httpServer.ListenAndServe()
 }
 
-// Shutdown gracefully shuts down the HTTP server
+// AddMetricsCollector exports the metrics of collector at /metrics, e.g. the
+// database connection pool statistics
+func (s *Server) AddMetricsCollector(collector prometheus.Collector) error {
+	return s.metrics.Register(collector)
+}
+
+// Shutdown gracefully shuts down the HTTP server, stops its background tasks
+// and disconnects the WebSocket clients
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down HTTP server")
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+	s.stopBackground()
+	return errors.Join(err, s.wsHub.Close(ctx))
 }
 
 // registerRoutes registers all HTTP routes
 func (s *Server) registerRoutes() {
+	// Anonymous requests are rate limited in the default bucket
+	rateLimit := s.rateLimiter.RateLimitMiddleware()
+
 	// Public routes
-	s.router.GET("/health", s.healthCheck)
+	s.router.GET("/health", rateLimit, s.healthCheck)
+	s.router.GET("/ready", rateLimit, s.readyCheck)
+	s.router.GET("/metrics", rateLimit, gin.WrapH(promhttp.HandlerFor(s.metrics, promhttp.HandlerOpts{})))
 
-	// Auth routes can be added here when needed
+	// Auth routes
+	publicAPI := s.router.Group("/api/v1")
+	publicAPI.Use(rateLimit)
+	s.authHandler.RegisterRoutes(publicAPI)
+
+	// Protected API routes requiring authentication. Authenticated requests
+	// are rate limited by role, and expensive routes have buckets of their own.
+	roleRateLimit := s.rateLimiter.RateLimitMiddleware(
+		middleware.WithRoleBuckets(),
+		middleware.WithRouteBucket(http.MethodPost, "/api/v1/stats/refresh", statsRefreshRateLimitBucket),
+	)
+
+	// Users who must change their password can only do that
+	passwordAPI := s.router.Group("/api/v1")
+	passwordAPI.Use(s.authMiddleware.Authenticate(), s.authMiddleware.VerifyCSRF(), roleRateLimit)
+	passwordAPI.POST("/auth/change-password", s.authHandler.ChangePassword)
 
-	// Protected API routes requiring authentication
 	protectedAPI := s.router.Group("/api/v1")
-	protectedAPI.Use(s.authMiddleware.Authenticate())
+	protectedAPI.Use(
+		s.authMiddleware.Authenticate(),
+		s.authMiddleware.VerifyCSRF(),
+		s.authMiddleware.RequirePasswordChanged(),
+		roleRateLimit,
+	)
 	{
+		// Token refresh
+		protectedAPI.POST("/auth/refresh", s.authMiddleware.RefreshToken)
+
 		// Products
 		s.productHandler.RegisterRoutes(protectedAPI)
+		s.statsHandler.RegisterProductRoutes(protectedAPI)
+
+		// Price alerts
+		s.alertHandler.RegisterRoutes(protectedAPI)
 
-		// Stats - require admin role
-		statsRoutes := protectedAPI.Group("/stats")
-		statsRoutes.Use(s.authMiddleware.AuthorizeRole("admin"))
-		s.statsHandler.RegisterRoutes(protectedAPI)
+		// Reviews
+		s.reviewHandler.RegisterRoutes(protectedAPI)
+
+		// Wishlist
+		s.wishlistHandler.RegisterRoutes(protectedAPI)
+
+		// Categories
+		s.categoryHandler.RegisterRoutes(protectedAPI)
+
+		// Admin routes - require admin role
+		adminAPI := protectedAPI.Group("")
+		adminAPI.Use(s.authMiddleware.AuthorizeRole("admin"))
+
+		// Stats and their export
+		s.statsHandler.RegisterRoutes(adminAPI)
+
+		// Price schedules
+		s.scheduleHandler.RegisterRoutes(adminAPI)
+
+		// Product archival - require admin role
+		s.productHandler.RegisterAdminRoutes(adminAPI)
+
+		// Category management - require admin role
+		s.categoryHandler.RegisterAdminRoutes(adminAPI)
+
+		// Effective configuration - require admin role
+		adminAPI.GET("/admin/config", s.effectiveConfig)
 	}
 }
 
-// healthCheck handles the health check endpoint
-func (s *Server) healthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "UP",
-		"time":   time.Now().Format(time.RFC3339),
-	})
+// effectiveConfig returns the effective configuration with secrets masked
+func (s *Server) effectiveConfig(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, s.config.Redacted())
 }
 
 // requestLogger logs request information
@@ -171,6 +340,6 @@ func (s *Server) requestLogger() gin.HandlerFunc {
 			"status":   c.Writer.Status(),
 			"duration": duration.String(),
 			"ip":       c.ClientIP(),
-		}).Info("Request processed")
+		}).WithContext(c.Request.Context()).Info("Request processed")
 	}
 }
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/thanhnguyen/product-api/internal/transport/http/middleware"
+)
+
+func TestShutdown_StopsBackgroundTasksAndDisconnectsClients(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	s := newTestServer(t, newFakeStatsUseCase())
+	ts := httptest.NewServer(s.router)
+
+	// Connect an admin WebSocket client
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/notifications"
+	dialer := websocket.Dialer{Subprotocols: []string{middleware.WebSocketTokenSubprotocol, tokenFor(t, s, "admin")}}
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	waitForCondition(t, func() bool { return s.wsHub.ClientCount() == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	// The client is told the server went away
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("ReadMessage() succeeded after shutdown, want the connection closed")
+	}
+	if got := s.wsHub.ClientCount(); got != 0 {
+		t.Errorf("ClientCount() = %d after shutdown, want 0", got)
+	}
+
+	// The rate limiter cleanup and WebSocket pumps return
+	conn.Close()
+	ts.Close()
+	waitForCondition(t, func() bool { return runtime.NumGoroutine() <= baseline })
+}
+
+// waitForCondition polls cond until it holds or a second has passed
+func waitForCondition(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEffectiveConfig_AdminOnlyWithSecretsMasked(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "db-password")
+	s := newTestServer(t, nil)
+
+	for _, role := range []string{"user", "manager"} {
+		w := performRequestAs(s.router, http.MethodGet, "/api/v1/admin/config", "", tokenFor(t, s, role))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want %d", role, w.Code, http.StatusForbidden)
+		}
+	}
+
+	w := performRequestAs(s.router, http.MethodGet, "/api/v1/admin/config", "", tokenFor(t, s, "admin"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin: status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &settings); err != nil {
+		t.Fatalf("decoding the configuration: %v", err)
+	}
+	if got := settings["database.password"]; got != "[REDACTED len=11]" {
+		t.Errorf("database.password = %v, want it masked", got)
+	}
+	if got, _ := settings["jwt.secret"].(string); !strings.HasPrefix(got, "[REDACTED") {
+		t.Errorf("jwt.secret = %q, want it masked", got)
+	}
+	if body := w.Body.String(); strings.Contains(body, "db-password") || strings.Contains(body, s.config.JWT.Secret) {
+		t.Error("the configuration reveals a secret")
+	}
+}
+
+func TestFeatureFlags_MaintenanceAndWebSocket(t *testing.T) {
+	t.Setenv("FEATURE_MAINTENANCE", "true")
+	t.Setenv("FEATURE_WEBSOCKET", "false")
+	s := newTestServer(t, newFakeStatsUseCase())
+	token := tokenFor(t, s, "admin")
+
+	if w := performRequestAs(s.router, http.MethodGet, "/api/v1/stats", "", token); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("API status under maintenance = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if w := performRequest(s.router, http.MethodGet, "/metrics", ""); w.Code != http.StatusOK {
+		t.Errorf("metrics status under maintenance = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	t.Setenv("FEATURE_MAINTENANCE", "false")
+	s = newTestServer(t, newFakeStatsUseCase())
+	if w := performRequestAs(s.router, http.MethodGet, "/ws/notifications", "", tokenFor(t, s, "admin")); w.Code != http.StatusNotFound {
+		t.Errorf("WebSocket status when disabled = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -1,13 +1,20 @@
 package http
 
 import (
+	"encoding/csv"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
 	"github.com/thanhnguyen/product-api/pkg/logger"
 )
 
+// defaultTopProductsLimit is the number of top products returned without a limit
+const defaultTopProductsLimit = 5
+
 // StatsHandler handles HTTP requests for statistics
 type StatsHandler struct {
 	statsUseCase usecase.StatsUseCase
@@ -26,19 +33,25 @@ func NewStatsHandler(statsUseCase usecase.StatsUseCase, logger *logger.Logger) *
 func (h *StatsHandler) GetStats(c *gin.Context) {
 	stats, err := h.statsUseCase.GetStats(c.Request.Context())
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get stats")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get stats")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stats"})
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	c.JSON(http.StatusOK, dto.FromStatsEntity(*stats))
 }
 
 // GetCategoryStats returns product counts by category
 func (h *StatsHandler) GetCategoryStats(c *gin.Context) {
-	stats, err := h.statsUseCase.GetCategoryStats(c.Request.Context())
+	var req dto.CategoryStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := h.statsUseCase.GetCategoryStats(c.Request.Context(), req.Rollup)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get category stats")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get category stats")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get category stats"})
 		return
 	}
@@ -50,7 +63,7 @@ func (h *StatsHandler) GetCategoryStats(c *gin.Context) {
 func (h *StatsHandler) GetWishlistStats(c *gin.Context) {
 	stats, err := h.statsUseCase.GetWishlistStats(c.Request.Context())
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get wishlist stats")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get wishlist stats")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get wishlist stats"})
 		return
 	}
@@ -60,9 +73,15 @@ func (h *StatsHandler) GetWishlistStats(c *gin.Context) {
 
 // GetTopProducts returns top products by reviews
 func (h *StatsHandler) GetTopProducts(c *gin.Context) {
-	topProducts, err := h.statsUseCase.GetTopProducts(c.Request.Context(), 5)
+	limit, ok := topProductsLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	topProducts, err := h.statsUseCase.GetTopProducts(c.Request.Context(), limit)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to get top products")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get top products")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top products"})
 		return
 	}
@@ -70,10 +89,123 @@ func (h *StatsHandler) GetTopProducts(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"top_products": topProducts})
 }
 
+// GetBestSellers returns the best-selling products
+func (h *StatsHandler) GetBestSellers(c *gin.Context) {
+	limit, ok := topProductsLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	bestSellers, err := h.statsUseCase.GetBestSellers(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get best-sellers")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get best-sellers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"best_sellers": bestSellers})
+}
+
+// topProductsLimit parses the limit query parameter of a product ranking,
+// capped at usecase.MaxTopProducts
+func topProductsLimit(c *gin.Context) (int, bool) {
+	limit := defaultTopProductsLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			return 0, false
+		}
+		limit = parsed
+	}
+	if limit > usecase.MaxTopProducts {
+		limit = usecase.MaxTopProducts
+	}
+	return limit, true
+}
+
+// ExportStats streams one kind of statistics as a CSV file
+func (h *StatsHandler) ExportStats(c *gin.Context) {
+	var req dto.StatsExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Build the rows of the requested stats
+	var (
+		header []string
+		rows   [][]string
+	)
+	ctx := c.Request.Context()
+	switch req.Type {
+	case "category":
+		stats, err := h.statsUseCase.GetCategoryStats(ctx, false)
+		if err != nil {
+			h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get category stats")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export stats"})
+			return
+		}
+		header = []string{"category_id", "category_name", "product_count"}
+		for _, s := range stats {
+			rows = append(rows, []string{
+				strconv.FormatUint(uint64(s.CategoryID), 10),
+				s.CategoryName,
+				strconv.Itoa(s.ProductCount),
+			})
+		}
+	case "wishlist":
+		stats, err := h.statsUseCase.GetWishlistStats(ctx)
+		if err != nil {
+			h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get wishlist stats")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export stats"})
+			return
+		}
+		header = []string{"product_id", "product_name", "wishlist_count"}
+		for _, s := range stats {
+			rows = append(rows, []string{
+				strconv.FormatUint(uint64(s.ProductID), 10),
+				s.ProductName,
+				strconv.Itoa(s.WishlistCount),
+			})
+		}
+	case "top-products":
+		topProducts, err := h.statsUseCase.GetTopProducts(ctx, usecase.MaxTopProducts)
+		if err != nil {
+			h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get top products")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export stats"})
+			return
+		}
+		header = []string{"product_id", "product_name", "count", "metric"}
+		for _, p := range topProducts {
+			rows = append(rows, []string{
+				strconv.FormatUint(uint64(p.ProductID), 10),
+				p.ProductName,
+				strconv.Itoa(p.Count),
+				p.Metric,
+			})
+		}
+	}
+
+	// Stream the CSV
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", req.Type+"-stats.csv"))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if err := w.Write(header); err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to write stats export")
+		return
+	}
+	if err := w.WriteAll(rows); err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to write stats export")
+	}
+}
+
 // RefreshStats forces a refresh of the statistics
 func (h *StatsHandler) RefreshStats(c *gin.Context) {
 	if err := h.statsUseCase.RefreshStats(c.Request.Context()); err != nil {
-		h.logger.WithError(err).Error("Failed to refresh stats")
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to refresh stats")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh stats"})
 		return
 	}
@@ -83,12 +215,20 @@ func (h *StatsHandler) RefreshStats(c *gin.Context) {
 
 // RegisterRoutes registers the statistics routes
 func (h *StatsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	// Stats change on every refresh and must never be served from a cache
 	stats := router.Group("/stats")
+	stats.Use(noStore())
 	{
 		stats.GET("", h.GetStats)
 		stats.GET("/categories", h.GetCategoryStats)
 		stats.GET("/wishlist", h.GetWishlistStats)
 		stats.GET("/top-products", h.GetTopProducts)
+		stats.GET("/export", h.ExportStats)
 		stats.POST("/refresh", h.RefreshStats)
 	}
 }
+
+// RegisterProductRoutes registers the product rankings any user may see
+func (h *StatsHandler) RegisterProductRoutes(router *gin.RouterGroup) {
+	router.GET("/products/best-sellers", h.GetBestSellers)
+}
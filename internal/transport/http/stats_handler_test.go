@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+)
+
+// fakeStatsUseCase serves fixed statistics
+type fakeStatsUseCase struct {
+	usecase.StatsUseCase
+	categories []entity.CategoryStat
+	stats      entity.Stats
+}
+
+func (f *fakeStatsUseCase) GetStats(ctx context.Context) (*entity.Stats, error) {
+	return &f.stats, nil
+}
+
+func (f *fakeStatsUseCase) GetCategoryStats(ctx context.Context, rollup bool) ([]entity.CategoryStat, error) {
+	return f.categories, nil
+}
+
+func newFakeStatsUseCase() *fakeStatsUseCase {
+	return &fakeStatsUseCase{categories: []entity.CategoryStat{
+		{CategoryID: 1, CategoryName: "Books", ProductCount: 12},
+		{CategoryID: 2, CategoryName: "Games, Toys", ProductCount: 3},
+	}}
+}
+
+func TestExportStats_CategoryCSV(t *testing.T) {
+	router := gin.New()
+	NewStatsHandler(newFakeStatsUseCase(), testLogger()).RegisterRoutes(router.Group(""))
+
+	w := performRequest(router, http.MethodGet, "/stats/export?type=category", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", got)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want a header and 2 rows", len(records))
+	}
+	if want := []string{"category_id", "category_name", "product_count"}; !reflect.DeepEqual(records[0], want) {
+		t.Errorf("header = %v, want %v", records[0], want)
+	}
+	if want := []string{"2", "Games, Toys", "3"}; !reflect.DeepEqual(records[2], want) {
+		t.Errorf("row = %v, want %v", records[2], want)
+	}
+}
+
+func TestExportStats_InvalidType(t *testing.T) {
+	router := gin.New()
+	NewStatsHandler(newFakeStatsUseCase(), testLogger()).RegisterRoutes(router.Group(""))
+
+	w := performRequest(router, http.MethodGet, "/stats/export?type=orders", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestStatsRoutes_RequireAdmin(t *testing.T) {
+	s := newTestServer(t, newFakeStatsUseCase())
+
+	tests := []struct {
+		name string
+		role string
+		want int
+	}{
+		{name: "anonymous", want: http.StatusUnauthorized},
+		{name: "user", role: "user", want: http.StatusForbidden},
+		{name: "admin", role: "admin", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var token string
+			if tt.role != "" {
+				token = tokenFor(t, s, tt.role)
+			}
+			for _, path := range []string{"/api/v1/stats/categories", "/api/v1/stats/export?type=category"} {
+				w := performRequestAs(s.router, http.MethodGet, path, "", token)
+				if w.Code != tt.want {
+					t.Errorf("GET %s status = %d, want %d", path, w.Code, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetStats_TypedResponse(t *testing.T) {
+	statsUseCase := newFakeStatsUseCase()
+	statsUseCase.stats = entity.Stats{
+		TotalProducts: 42,
+		TotalUsers:    3,
+		TotalReviews:  7,
+		AverageRating: 4.5,
+		LastRefreshed: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Misc:          map[string]interface{}{"orders_today": 5},
+	}
+	router := gin.New()
+	NewStatsHandler(statsUseCase, testLogger()).RegisterRoutes(router.Group(""))
+
+	w := performRequest(router, http.MethodGet, "/stats", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+	want := map[string]interface{}{
+		"total_products": float64(42),
+		"total_users":    float64(3),
+		"total_reviews":  float64(7),
+		"average_rating": 4.5,
+		"last_refreshed": "2024-05-01T12:00:00Z",
+		"misc":           map[string]interface{}{"orders_today": float64(5)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("response = %v, want %v", got, want)
+	}
+}
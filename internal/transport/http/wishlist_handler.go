@@ -0,0 +1,143 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// WishlistHandler handles HTTP requests for the current user's wishlist.
+// The user is always taken from the JWT context, never from the request.
+type WishlistHandler struct {
+	wishlistUseCase usecase.WishlistUseCase
+	logger          *logger.Logger
+}
+
+// NewWishlistHandler creates a new WishlistHandler
+func NewWishlistHandler(wishlistUseCase usecase.WishlistUseCase, logger *logger.Logger) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistUseCase: wishlistUseCase,
+		logger:          logger,
+	}
+}
+
+// AddToWishlist handles adding a product to the current user's wishlist
+func (h *WishlistHandler) AddToWishlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("productID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	// Call use case
+	if err := h.wishlistUseCase.AddToWishlist(c.Request.Context(), userID, uint(productID)); err != nil {
+		if errors.Is(err, usecase.ErrProductNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to add product to wishlist")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product to wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product added to wishlist"})
+}
+
+// RemoveFromWishlist handles removing a product from the current user's wishlist
+func (h *WishlistHandler) RemoveFromWishlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("productID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	// Call use case
+	if err := h.wishlistUseCase.RemoveFromWishlist(c.Request.Context(), userID, uint(productID)); err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to remove product from wishlist")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove product from wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product removed from wishlist"})
+}
+
+// ListWishlist handles listing the products in the current user's wishlist
+func (h *WishlistHandler) ListWishlist(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req dto.WishlistListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default values for pagination
+	req.Normalize()
+
+	// Call use case
+	products, totalItems, err := h.wishlistUseCase.ListWishlist(c.Request.Context(), userID, req.Page, req.PageSize)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to list wishlist")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list wishlist"})
+		return
+	}
+
+	// Convert entities to response
+	items := make([]dto.ProductResponse, 0, len(products))
+	for _, p := range products {
+		items = append(items, dto.FromEntity(p))
+	}
+
+	c.JSON(http.StatusOK, dto.NewPage(items, totalItems, req.PageRequest))
+}
+
+// CheckAvailability handles checking which products in the current user's
+// wishlist can currently be ordered
+func (h *WishlistHandler) CheckAvailability(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	availability, err := h.wishlistUseCase.CheckAvailability(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to check wishlist availability")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check wishlist availability"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromAvailability(availability))
+}
+
+// RegisterRoutes registers the wishlist routes
+func (h *WishlistHandler) RegisterRoutes(router *gin.RouterGroup) {
+	wishlist := router.Group("/wishlist")
+	{
+		wishlist.GET("", h.ListWishlist)
+		wishlist.POST("/check-availability", h.CheckAvailability)
+		wishlist.POST("/:productID", h.AddToWishlist)
+		wishlist.DELETE("/:productID", h.RemoveFromWishlist)
+	}
+}
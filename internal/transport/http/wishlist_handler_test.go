@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhnguyen/product-api/internal/business/entity"
+	"github.com/thanhnguyen/product-api/internal/business/usecase"
+	"github.com/thanhnguyen/product-api/internal/storage"
+	"github.com/thanhnguyen/product-api/internal/transport/dto"
+)
+
+// fakeWishlistRepo serves a fixed wishlist, newest first, and records the
+// page it was asked for
+type fakeWishlistRepo struct {
+	storage.WishlistRepository
+	products      []entity.Product
+	offset, limit int
+}
+
+func (f *fakeWishlistRepo) List(ctx context.Context, userID uint, offset, limit int) ([]entity.Product, int64, error) {
+	f.offset, f.limit = offset, limit
+	if offset >= len(f.products) {
+		return nil, int64(len(f.products)), nil
+	}
+	end := offset + limit
+	if end > len(f.products) {
+		end = len(f.products)
+	}
+	return f.products[offset:end], int64(len(f.products)), nil
+}
+
+func (f *fakeWishlistRepo) ListStock(ctx context.Context, userID uint) ([]entity.Product, error) {
+	return f.products, nil
+}
+
+// newWishlistRouter serves the wishlist routes of repo to user 5
+func newWishlistRouter(repo storage.WishlistRepository) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", uint(5))
+	})
+	wishlistUseCase := usecase.NewWishlistUseCase(repo, nil, testLogger())
+	NewWishlistHandler(wishlistUseCase, testLogger()).RegisterRoutes(router.Group(""))
+	return router
+}
+
+func TestListWishlist_Pages(t *testing.T) {
+	repo := &fakeWishlistRepo{}
+	for id := uint(1); id <= 12; id++ {
+		repo.products = append(repo.products, entity.Product{ID: id, Name: "Product", Price: 10})
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantOffset int
+		wantLimit  int
+		wantPage   int
+		wantIDs    []uint
+	}{
+		{name: "default page", query: "", wantOffset: 0, wantLimit: 10, wantPage: 1, wantIDs: []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{name: "last page", query: "?page=3&page_size=5", wantOffset: 10, wantLimit: 5, wantPage: 3, wantIDs: []uint{11, 12}},
+		{name: "past the end", query: "?page=4&page_size=5", wantOffset: 15, wantLimit: 5, wantPage: 4, wantIDs: []uint{}},
+		{name: "oversized page", query: "?page_size=1000", wantOffset: 0, wantLimit: dto.DefaultPageSize, wantPage: 1, wantIDs: []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(newWishlistRouter(repo), http.MethodGet, "/wishlist"+tt.query, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if repo.offset != tt.wantOffset || repo.limit != tt.wantLimit {
+				t.Errorf("repository page = offset %d limit %d, want offset %d limit %d", repo.offset, repo.limit, tt.wantOffset, tt.wantLimit)
+			}
+
+			var page dto.Page[dto.ProductResponse]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to decode the response: %v", err)
+			}
+			if page.TotalItems != 12 || page.Page != tt.wantPage || page.PageSize != tt.wantLimit {
+				t.Errorf("page = %d of size %d with %d items, want %d of size %d with 12",
+					page.Page, page.PageSize, page.TotalItems, tt.wantPage, tt.wantLimit)
+			}
+			ids := make([]uint, len(page.Items))
+			for i, item := range page.Items {
+				ids[i] = item.ID
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("items = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("items = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckAvailability(t *testing.T) {
+	repo := &fakeWishlistRepo{products: []entity.Product{
+		{ID: 1, Name: "Lamp", Status: entity.ProductStatusActive, StockQuantity: 3},
+		{ID: 2, Name: "Desk", Status: entity.ProductStatusActive, StockQuantity: 0},
+		{ID: 3, Name: "Chair", Status: entity.ProductStatusOutOfStock, StockQuantity: 0},
+		{ID: 4, Name: "Shelf", Status: entity.ProductStatusActive, StockQuantity: 5, Archived: true},
+	}}
+
+	w := performRequest(newWishlistRouter(repo), http.MethodPost, "/wishlist/check-availability", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var body dto.WishlistAvailabilityResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+	if body.Available != 1 || body.Unavailable != 3 {
+		t.Errorf("available = %d, unavailable = %d, want 1 and 3", body.Available, body.Unavailable)
+	}
+	want := map[uint]bool{1: true, 2: false, 3: false, 4: false}
+	if len(body.Items) != len(want) {
+		t.Fatalf("items = %+v, want %d", body.Items, len(want))
+	}
+	for _, item := range body.Items {
+		if item.Available != want[item.ProductID] {
+			t.Errorf("product %d available = %v, want %v", item.ProductID, item.Available, want[item.ProductID])
+		}
+	}
+	if body.Items[1].StockQuantity != 0 || body.Items[1].Status != entity.ProductStatusActive {
+		t.Errorf("insufficient stock item = %+v, want an active product with no stock", body.Items[1])
+	}
+}
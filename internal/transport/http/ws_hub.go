@@ -1,53 +1,216 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/thanhnguyen/product-api/internal/transport/http/middleware"
 )
 
+const (
+	// sendBufferSize is the number of messages queued per client before it is
+	// considered too slow and dropped
+	sendBufferSize = 16
+
+	// writeWait bounds how long a single write to a client may take
+	writeWait = 10 * time.Second
+)
+
+// wsClient is a connected client with its own outgoing message queue. Only the
+// client's writer goroutine writes to conn.
+type wsClient struct {
+	conn   *websocket.Conn
+	send   chan []byte
+	userID uint
+}
+
 type WebSocketHub struct {
-	clients map[*websocket.Conn]bool
-	mu      sync.Mutex
+	clients      map[*wsClient]bool
+	mu           sync.Mutex
+	closed       bool
+	pumps        sync.WaitGroup
+	pingInterval time.Duration
+	upgrader     websocket.Upgrader
 }
 
-func NewWebSocketHub() *WebSocketHub {
-	return &WebSocketHub{clients: make(map[*websocket.Conn]bool)}
+// NewWebSocketHub creates a hub that pings every client each pingInterval and
+// evicts clients that don't answer with a pong before the next deadline. Only
+// browsers from allowedOrigins may connect; "*" allows any origin.
+func NewWebSocketHub(pingInterval time.Duration, allowedOrigins []string) *WebSocketHub {
+	return &WebSocketHub{
+		clients:      make(map[*wsClient]bool),
+		pingInterval: pingInterval,
+		upgrader: websocket.Upgrader{
+			CheckOrigin:  checkOrigin(allowedOrigins),
+			Subprotocols: []string{middleware.WebSocketTokenSubprotocol},
+		},
+	}
 }
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+// ClientCount returns the number of connected clients
+func (hub *WebSocketHub) ClientCount() int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	return len(hub.clients)
 }
 
+// checkOrigin allows requests without an Origin header, which don't come from
+// browsers, and requests from one of the allowed origins
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, allowed := range allowedOrigins {
+			if allowed == "*" || strings.EqualFold(allowed, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// pongWait is how long a client may stay silent before it is considered dead.
+// It leaves the client half a ping interval to answer.
+func (hub *WebSocketHub) pongWait() time.Duration {
+	return hub.pingInterval * 3 / 2
+}
+
+// HandleWS upgrades an authenticated request to a WebSocket connection. The
+// route must be behind JWTAuthMiddleware.AuthenticateWebSocket.
 func (hub *WebSocketHub) HandleWS(c *gin.Context) {
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	conn, err := hub.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		return
 	}
+	client := &wsClient{conn: conn, send: make(chan []byte, sendBufferSize), userID: userID}
 	hub.mu.Lock()
-	hub.clients[conn] = true
+	if hub.closed {
+		hub.mu.Unlock()
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+			time.Now().Add(writeWait))
+		conn.Close()
+		return
+	}
+	hub.clients[client] = true
+	hub.pumps.Add(2)
 	hub.mu.Unlock()
+
+	go hub.writePump(client)
+	go hub.readPump(client)
+}
+
+// Close disconnects every client and refuses new connections, then waits for
+// the clients' goroutines to return or ctx to be done. Connections upgraded
+// to WebSocket aren't tracked by http.Server.Shutdown, so the hub must be
+// closed alongside it.
+func (hub *WebSocketHub) Close(ctx context.Context) error {
+	hub.mu.Lock()
+	hub.closed = true
+	for client := range hub.clients {
+		delete(hub.clients, client)
+		close(client.send)
+	}
+	hub.mu.Unlock()
+
+	done := make(chan struct{})
 	go func() {
-		defer func() {
-			hub.mu.Lock()
-			delete(hub.clients, conn)
-			hub.mu.Unlock()
-			conn.Close()
-		}()
-		for {
-			if _, _, err := conn.NextReader(); err != nil {
-				break
-			}
+		hub.pumps.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// readPump discards incoming messages until the connection fails or the read
+// deadline passes without a pong, then unregisters the client
+func (hub *WebSocketHub) readPump(client *wsClient) {
+	defer func() {
+		hub.unregister(client)
+		client.conn.Close()
+		hub.pumps.Done()
+	}()
+	client.conn.SetReadDeadline(time.Now().Add(hub.pongWait()))
+	client.conn.SetPongHandler(func(string) error {
+		return client.conn.SetReadDeadline(time.Now().Add(hub.pongWait()))
+	})
+	for {
+		if _, _, err := client.conn.NextReader(); err != nil {
+			break
 		}
+	}
+}
+
+// writePump writes queued messages and periodic pings to the connection until
+// the queue is closed or a write fails
+func (hub *WebSocketHub) writePump(client *wsClient) {
+	ticker := time.NewTicker(hub.pingInterval)
+	defer func() {
+		ticker.Stop()
+		client.conn.Close()
+		hub.pumps.Done()
 	}()
+	for {
+		select {
+		case message, ok := <-client.send:
+			client.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				client.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := client.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				hub.unregister(client)
+				return
+			}
+		case <-ticker.C:
+			client.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := client.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				hub.unregister(client)
+				return
+			}
+		}
+	}
 }
 
+// unregister removes a client and closes its queue. It is safe to call more than once.
+func (hub *WebSocketHub) unregister(client *wsClient) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if hub.clients[client] {
+		delete(hub.clients, client)
+		close(client.send)
+	}
+}
+
+// Broadcast queues a message for every client without blocking. Clients whose
+// queue is full are dropped so one slow client can't stall the others.
 func (hub *WebSocketHub) Broadcast(message []byte) {
 	hub.mu.Lock()
 	defer hub.mu.Unlock()
-	for conn := range hub.clients {
-		conn.WriteMessage(websocket.TextMessage, message)
+	for client := range hub.clients {
+		select {
+		case client.send <- message:
+		default:
+			delete(hub.clients, client)
+			close(client.send)
+		}
 	}
 }
@@ -16,6 +16,8 @@ function print_usage {
     echo "Options:"
     echo "  --up                 Apply migrations (default)"
     echo "  --down               Rollback migrations"
+    echo "  --status             Show applied and pending migrations"
+    echo "  --force              Run even if applied migration files have been modified"
     echo "  --migration=NAME     Apply/rollback a specific migration"
     echo "  --help               Show this help message"
     echo ""
@@ -28,6 +30,8 @@ function print_usage {
 # Parse command line arguments
 UP=true
 DOWN=false
+STATUS=false
+FORCE=false
 MIGRATION=""
 
 for arg in "$@"
@@ -43,6 +47,14 @@ do
         DOWN=true
         shift
         ;;
+        --status)
+        STATUS=true
+        shift
+        ;;
+        --force)
+        FORCE=true
+        shift
+        ;;
         --migration=*)
         MIGRATION="${arg#*=}"
         shift
@@ -79,6 +91,14 @@ if [ "$DOWN" = true ]; then
     CMD="$CMD -down"
 fi
 
+if [ "$STATUS" = true ]; then
+    CMD="$CMD -status"
+fi
+
+if [ "$FORCE" = true ]; then
+    CMD="$CMD -force"
+fi
+
 if [ ! -z "$MIGRATION" ]; then
     CMD="$CMD -migration=$MIGRATION"
 fi
@@ -0,0 +1,33 @@
+-- Migration: 003_scheduled_price_changes
+-- Description: Add scheduled price changes applied and reverted by the background scheduler
+
+-- Create scheduled_price_changes table
+CREATE TABLE IF NOT EXISTS scheduled_price_changes (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    new_price DECIMAL(10, 2) NOT NULL CHECK (new_price > 0),
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revert_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (product_id IS NOT NULL OR category_id IS NOT NULL),
+    CHECK (revert_at IS NULL OR revert_at > effective_at)
+);
+
+-- Create scheduled_price_change_items table holding the original prices to revert to
+CREATE TABLE IF NOT EXISTS scheduled_price_change_items (
+    schedule_id INTEGER NOT NULL REFERENCES scheduled_price_changes(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    original_price DECIMAL(10, 2) NOT NULL,
+    PRIMARY KEY (schedule_id, product_id)
+);
+
+-- Create indexes for the scheduler lookups
+CREATE INDEX idx_scheduled_price_changes_status_effective_at ON scheduled_price_changes(status, effective_at);
+CREATE INDEX idx_scheduled_price_changes_status_revert_at ON scheduled_price_changes(status, revert_at);
+
+CREATE TRIGGER update_scheduled_price_changes_updated_at
+BEFORE UPDATE ON scheduled_price_changes
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
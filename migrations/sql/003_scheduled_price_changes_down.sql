@@ -0,0 +1,13 @@
+-- Migration: 003_scheduled_price_changes (down)
+-- Description: Revert scheduled price changes
+
+-- Drop trigger
+DROP TRIGGER IF EXISTS update_scheduled_price_changes_updated_at ON scheduled_price_changes;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_scheduled_price_changes_status_revert_at;
+DROP INDEX IF EXISTS idx_scheduled_price_changes_status_effective_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS scheduled_price_change_items;
+DROP TABLE IF EXISTS scheduled_price_changes;
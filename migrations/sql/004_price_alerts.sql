@@ -0,0 +1,16 @@
+-- Migration: 004_price_alerts
+-- Description: Add price-drop alert subscriptions
+
+-- Create price_alerts table
+CREATE TABLE IF NOT EXISTS price_alerts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    threshold DECIMAL(10, 2) NOT NULL CHECK (threshold > 0),
+    notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, product_id)
+);
+
+-- Create indexes for performance
+CREATE INDEX idx_price_alerts_product_id ON price_alerts(product_id);
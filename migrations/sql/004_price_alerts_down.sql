@@ -0,0 +1,8 @@
+-- Migration: 004_price_alerts (down)
+-- Description: Revert price-drop alert subscriptions
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_price_alerts_product_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS price_alerts;
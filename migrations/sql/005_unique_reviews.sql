@@ -0,0 +1,5 @@
+-- Migration: 005_unique_reviews
+-- Description: Allow a single review per user and product
+
+-- Create unique index
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_user_product ON reviews(user_id, product_id);
@@ -0,0 +1,5 @@
+-- Migration: 005_unique_reviews (down)
+-- Description: Revert single review per user and product
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_reviews_user_product;
@@ -0,0 +1,15 @@
+-- Migration: 006_stock_reservations
+-- Description: Add stock reservations that hold stock until they expire
+
+-- Create stock_reservations table
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    released_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for performance
+CREATE INDEX idx_stock_reservations_outstanding ON stock_reservations(expires_at) WHERE released_at IS NULL;
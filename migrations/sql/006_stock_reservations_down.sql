@@ -0,0 +1,8 @@
+-- Migration: 006_stock_reservations (down)
+-- Description: Revert stock reservations
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_stock_reservations_outstanding;
+
+-- Drop tables
+DROP TABLE IF EXISTS stock_reservations;
@@ -0,0 +1,9 @@
+-- Migration: 007_product_archival
+-- Description: Allow products to be archived, hiding them from the catalog without deleting them
+
+-- Add archival columns
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_products_archived ON products(archived);
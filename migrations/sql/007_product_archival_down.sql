@@ -0,0 +1,9 @@
+-- Migration: 007_product_archival (down)
+-- Description: Revert product archival
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_products_archived;
+
+-- Drop columns
+ALTER TABLE products DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE products DROP COLUMN IF EXISTS archived;
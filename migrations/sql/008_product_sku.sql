@@ -0,0 +1,11 @@
+-- Migration: 008_product_sku
+-- Description: Give every product a unique stock-keeping unit
+
+-- Add SKU column
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+
+-- Generate SKUs for existing products
+UPDATE products SET sku = 'SKU-' || id WHERE sku IS NULL;
+
+-- Create unique index
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products(sku);
@@ -0,0 +1,8 @@
+-- Migration: 008_product_sku (down)
+-- Description: Revert product SKUs
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_products_sku;
+
+-- Drop columns
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
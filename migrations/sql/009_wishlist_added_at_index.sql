@@ -0,0 +1,5 @@
+-- Migration: 009_wishlist_added_at_index
+-- Description: Index wishlists by the time products were added for paginated listing
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_wishlist_user_added_at ON wishlist(user_id, added_at DESC);
@@ -0,0 +1,5 @@
+-- Migration: 009_wishlist_added_at_index (down)
+-- Description: Drop the wishlist listing index
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_wishlist_user_added_at;
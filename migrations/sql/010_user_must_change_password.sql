@@ -0,0 +1,5 @@
+-- Migration: 010_user_must_change_password
+-- Description: Force bootstrapped users to change their password on first login
+
+-- Add must_change_password column
+ALTER TABLE users ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
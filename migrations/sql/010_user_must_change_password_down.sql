@@ -0,0 +1,5 @@
+-- Migration: 010_user_must_change_password (down)
+-- Description: Revert forced password changes
+
+-- Drop columns
+ALTER TABLE users DROP COLUMN IF EXISTS must_change_password;
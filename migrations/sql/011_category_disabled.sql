@@ -0,0 +1,5 @@
+-- Migration: 011_category_disabled
+-- Description: Allow categories to be disabled without deleting them
+
+-- Add disabled column
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- Migration: 011_category_disabled (down)
+-- Description: Revert disabled categories
+
+-- Drop columns
+ALTER TABLE categories DROP COLUMN IF EXISTS disabled;
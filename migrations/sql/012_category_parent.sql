@@ -0,0 +1,8 @@
+-- Migration: 012_category_parent
+-- Description: Allow categories to be nested under a parent category
+
+-- Add parent_id column; sub-categories of a deleted category become top-level
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+
+-- Create index for listing a category's children
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
@@ -0,0 +1,8 @@
+-- Migration: 012_category_parent (down)
+-- Description: Revert nested categories
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_categories_parent_id;
+
+-- Drop columns
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
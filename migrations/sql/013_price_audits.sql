@@ -0,0 +1,17 @@
+-- Migration: 013_price_audits
+-- Description: Record bulk price updates in an audit log
+
+-- Create price_audits table; the log outlives the users who made the updates
+CREATE TABLE IF NOT EXISTS price_audits (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    adjustment_type VARCHAR(20) NOT NULL,
+    value DECIMAL(10, 2) NOT NULL,
+    min_price DECIMAL(10, 2) NOT NULL,
+    updated INTEGER NOT NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_price_audits_user_id ON price_audits(user_id);
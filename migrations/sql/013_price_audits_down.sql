@@ -0,0 +1,5 @@
+-- Migration: 013_price_audits (down)
+-- Description: Revert the price audit log
+
+-- Drop tables
+DROP TABLE IF EXISTS price_audits;
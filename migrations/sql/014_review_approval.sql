@@ -0,0 +1,5 @@
+-- Migration: 014_review_approval
+-- Description: Track whether a review is approved to count toward the product's rating
+
+-- Add approved column; existing reviews were published when created
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS approved BOOLEAN NOT NULL DEFAULT TRUE;
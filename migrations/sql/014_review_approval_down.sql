@@ -0,0 +1,5 @@
+-- Migration: 014_review_approval (down)
+-- Description: Revert the review approval flag
+
+-- Drop columns
+ALTER TABLE reviews DROP COLUMN IF EXISTS approved;
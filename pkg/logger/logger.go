@@ -1,11 +1,13 @@
 package logger
 
 import (
+	"context"
 	"io"
 	"os"
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"github.com/thanhnguyen/product-api/pkg/ctxutil"
 )
 
 // Logger wraps logrus.Logger to provide a more streamlined API
@@ -57,6 +59,9 @@ func NewLogger(level, format, output string) *Logger {
 		})
 	}
 
+	// Tag entries logged with a request's context with its correlation ID
+	log.AddHook(requestIDHook{})
+
 	return &Logger{log}
 }
 
@@ -70,6 +75,12 @@ func (l *Logger) WithFields(fields Fields) *logrus.Entry {
 	return l.Logger.WithFields(logrus.Fields(fields))
 }
 
+// WithContext returns a log entry for ctx. Entries logged during a request
+// carry its request_id.
+func (l *Logger) WithContext(ctx context.Context) *logrus.Entry {
+	return l.Logger.WithContext(ctx)
+}
+
 // WithError adds an error field to the log entry
 func (l *Logger) WithError(err error) *logrus.Entry {
 	return l.Logger.WithError(err)
@@ -106,3 +117,22 @@ func (l *Logger) Configure(level, format string, output io.Writer) {
 		l.SetOutput(output)
 	}
 }
+
+// requestIDHook adds the correlation ID of the entry's context to the entry
+type requestIDHook struct{}
+
+// Levels implements logrus.Hook
+func (requestIDHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook
+func (requestIDHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if requestID, ok := ctxutil.RequestIDFromContext(entry.Context); ok {
+		entry.Data["request_id"] = requestID
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// Notification is a message delivered to a single recipient
+type Notification struct {
+	Recipient string
+	Subject   string
+	Body      string
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to the log. It stands in for a real
+// mailer or webhook until one is configured.
+type LogNotifier struct {
+	logger *logger.Logger
+}
+
+// NewLogNotifier creates a new LogNotifier
+func NewLogNotifier(logger *logger.Logger) *LogNotifier {
+	return &LogNotifier{
+		logger: logger,
+	}
+}
+
+// Notify logs the notification
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.logger.WithFields(logger.Fields{
+		"recipient": notification.Recipient,
+		"subject":   notification.Subject,
+		"body":      notification.Body,
+	}).Info("Notification sent")
+	return nil
+}
+
+// Queue delivers notifications asynchronously through a wrapped Notifier
+// so callers never block on slow delivery
+type Queue struct {
+	notifier Notifier
+	logger   *logger.Logger
+	jobs     chan Notification
+	done     chan struct{}
+}
+
+// NewQueue creates a new Queue with the given buffer size and starts its
+// worker. The worker delivers what is already queued and stops when ctx is
+// cancelled.
+func NewQueue(ctx context.Context, notifier Notifier, logger *logger.Logger, size int) *Queue {
+	q := &Queue{
+		notifier: notifier,
+		logger:   logger,
+		jobs:     make(chan Notification, size),
+		done:     make(chan struct{}),
+	}
+
+	// Start the delivery worker
+	go q.run(ctx)
+
+	return q
+}
+
+// Notify enqueues a notification for delivery. It returns immediately and
+// drops the notification if the queue is full.
+func (q *Queue) Notify(ctx context.Context, n Notification) error {
+	select {
+	case q.jobs <- n:
+	default:
+		q.logger.WithField("recipient", n.Recipient).Warn("Notification queue full, dropping notification")
+	}
+	return nil
+}
+
+// Done returns a channel that is closed once the worker has stopped
+func (q *Queue) Done() <-chan struct{} {
+	return q.done
+}
+
+// run delivers queued notifications until ctx is cancelled, then flushes the
+// notifications still in the queue
+func (q *Queue) run(ctx context.Context) {
+	defer close(q.done)
+
+	// Deliveries in flight at shutdown are allowed to finish
+	deliverCtx := context.WithoutCancel(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case n := <-q.jobs:
+					q.deliver(deliverCtx, n)
+				default:
+					return
+				}
+			}
+		case n := <-q.jobs:
+			q.deliver(deliverCtx, n)
+		}
+	}
+}
+
+// deliver sends a notification through the wrapped Notifier
+func (q *Queue) deliver(ctx context.Context, n Notification) {
+	if err := q.notifier.Notify(ctx, n); err != nil {
+		q.logger.WithError(err).WithField("recipient", n.Recipient).Error("Failed to deliver notification")
+	}
+}
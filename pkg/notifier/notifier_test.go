@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thanhnguyen/product-api/pkg/logger"
+)
+
+// recordingNotifier records the notifications it delivers. Deliveries wait
+// until release is closed.
+type recordingNotifier struct {
+	release chan struct{}
+
+	mu        sync.Mutex
+	delivered []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification Notification) error {
+	<-n.release
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.delivered = append(n.delivered, notification.Recipient)
+	return nil
+}
+
+func TestQueue_FlushesAndStopsWhenContextCancelled(t *testing.T) {
+	n := &recordingNotifier{release: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := NewQueue(ctx, n, logger.NewLogger("error", "text", "stdout"), 10)
+
+	for _, recipient := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		q.Notify(context.Background(), Notification{Recipient: recipient})
+	}
+	cancel()
+	close(n.release)
+
+	select {
+	case <-q.Done():
+	case <-time.After(time.Second):
+		t.Fatal("queue worker did not stop after cancellation")
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.delivered) != 3 {
+		t.Errorf("delivered %v, want the 3 queued notifications", n.delivered)
+	}
+}